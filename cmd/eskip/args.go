@@ -41,6 +41,9 @@ const (
 	prettyFlag         = "pretty"
 	indentStrFlag      = "indent"
 	jsonFlag           = "json"
+	yamlFlag           = "yaml"
+	yamlInputFlag      = "yaml-input"
+	checkFlag          = "check"
 
 	defaultEtcdUrls     = "http://127.0.0.1:2379,http://127.0.0.1:4001"
 	defaultEtcdPrefix   = "/skipper"
@@ -77,6 +80,9 @@ var (
 	pretty            bool
 	indentStr         string
 	printJson         bool
+	printYaml         bool
+	yamlInput         bool
+	formatCheck       bool
 )
 
 var (
@@ -112,6 +118,9 @@ func initFlags() {
 	flags.BoolVar(&pretty, prettyFlag, false, prettyUsage)
 	flags.StringVar(&indentStr, indentStrFlag, "  ", indentStrUsage)
 	flags.BoolVar(&printJson, jsonFlag, false, jsonUsage)
+	flags.BoolVar(&printYaml, yamlFlag, false, yamlUsage)
+	flags.BoolVar(&yamlInput, yamlInputFlag, false, yamlInputUsage)
+	flags.BoolVar(&formatCheck, checkFlag, false, checkUsage)
 }
 
 func init() {
@@ -26,6 +26,15 @@ Check if an eskip file has valid syntax:
 
     eskip check routes.eskip
 
+Find unreachable, duplicate and ambiguous routes in an eskip file:
+
+    eskip lint routes.eskip
+
+Format an eskip file in place, keeping the comments, or check in CI that it's formatted:
+
+    eskip fmt routes.eskip
+    eskip fmt -check routes.eskip
+
 Print routes stored in etcd:
 
     eskip print -etcd-urls https://etcd.example.org
@@ -34,6 +43,11 @@ Print routes as JSON:
 
     eskip print -json
 
+Convert routes from an eskip file to YAML, and back:
+
+    eskip print -yaml routes.eskip > routes.yaml
+    eskip print routes.yaml
+
 Insert/update routes in etcd from an eskip file:
 
     eskip upsert routes.eskip
@@ -85,10 +99,13 @@ const (
 	prettyUsage         = "prints routes in a more readable format"
 	indentStrUsage      = "indent string used in pretty printing. Must match regexp \\s"
 	jsonUsage           = "prints routes as JSON"
+	yamlUsage           = "prints routes as YAML"
+	yamlInputUsage      = "reads the routes from stdin or the inline routes as YAML"
+	checkUsage          = "fmt only lists the inputs that are not formatted"
 
 	// command line help (1):
 	help1 = `Usage: eskip <command> [media flags] [--] [file]
-Commands: check|print|upsert|reset|delete|patch
+Commands: check|print|lint|fmt|upsert|reset|delete|patch
 Verify, print, update or delete Skipper routes.
 See more: https://github.com/zalando/skipper
 
@@ -99,7 +116,8 @@ innkeeper     endpoint of an innkeeper server. See more about innkeeper:
 etcd          endpoint(s) of an etcd cluster. See more about etcd:
               https://github.com/coreos/etcd
 stdin         standard input when not tty, expecting routes but ignored if a file is provided
-file          a file containing routes
+file          a file containing routes, in the YAML format when the file
+              extension is .yaml or .yml
 inline        routes as command line parameter
 inline ids    a list of route ids (only for delete)
 prepend       a chain of filters to be prepended to the filter chain in
@@ -127,6 +145,18 @@ check    verifies the syntax of routes. Accepts one input medium
 
 print    same as check, but also prints the routes.
 
+lint     same as check, but also reports the routes that can never be
+         matched, the routes with identical conditions, and the routes
+         whose order is unspecified. Example:
+         eskip lint routes.eskip
+
+fmt      formats the routes, keeping the comments. Accepts one input
+         medium of the following types: file, stdin, inline. A file is
+         rewritten in place, otherwise the formatted routes are printed.
+         With -check, only lists the input if it's not formatted, and
+         fails. Example:
+         eskip fmt routes.eskip
+
 upsert   insert/update routes from input to output. Expects one input
          medium of the following types: stdin, file, inline.
          Automatically selects etcd as output. Example:
@@ -29,6 +29,8 @@ type (
 const (
 	check  command = "check"
 	print  command = "print"
+	lint   command = "lint"
+	format command = "fmt"
 	upsert command = "upsert"
 	reset  command = "reset"
 	delete command = "delete"
@@ -45,6 +47,8 @@ var (
 var commands = map[command]commandFunc{
 	check:  checkCmd,
 	print:  printCmd,
+	lint:   lintCmd,
+	format: fmtCmd,
 	upsert: upsertCmd,
 	reset:  resetCmd,
 	delete: deleteCmd,
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zalando/skipper/eskip"
+)
+
+var notFormatted = errors.New("one or more inputs not formatted")
+
+// reads the document to be formatted, and tells the name to be used in
+// the output of the check
+func readFormatInput(in *medium) (doc, name string, err error) {
+	switch in.typ {
+	case file:
+		var b []byte
+		b, err = os.ReadFile(in.path)
+		return string(b), in.path, err
+	case stdin:
+		var b []byte
+		b, err = io.ReadAll(os.Stdin)
+		return string(b), "<stdin>", err
+	case inline:
+		return in.eskip, "<inline>", nil
+	default:
+		return "", "", invalidInputType
+	}
+}
+
+// command executed for fmt. It rewrites the input file in place, or prints
+// the formatted routes from stdin or the inline routes. With -check, it
+// only prints the name of the input when it's not formatted.
+func fmtCmd(a cmdArgs) error {
+	doc, name, err := readFormatInput(a.in)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := eskip.Format(doc)
+	if err != nil {
+		return err
+	}
+
+	if formatCheck {
+		if formatted != doc {
+			fmt.Fprintln(stdout, name)
+			return notFormatted
+		}
+
+		return nil
+	}
+
+	if a.in.typ != file {
+		_, err = io.WriteString(stdout, formatted)
+		return err
+	}
+
+	if formatted == doc {
+		return nil
+	}
+
+	info, err := os.Stat(a.in.path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(a.in.path, []byte(formatted), info.Mode().Perm())
+}
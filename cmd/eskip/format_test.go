@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+const (
+	testUnformatted = `// foo route
+foo: Path("/foo") -> setPath("/") -> "https://foo.example.org"; // done
+`
+
+	testFormatted = `// foo route
+foo: Path("/foo")
+  -> setPath("/")
+  -> "https://foo.example.org"; // done
+`
+)
+
+func runFmt(in *medium, check bool) (string, error) {
+	preserveOut := stdout
+	buf := &bytes.Buffer{}
+	defer func() {
+		stdout = preserveOut
+		formatCheck = false
+	}()
+
+	stdout = buf
+	formatCheck = check
+	err := fmtCmd(cmdArgs{in: in})
+	return buf.String(), err
+}
+
+func TestFormatFile(t *testing.T) {
+	const name = "testFile"
+	err := withFile(name, testUnformatted, func(_ *os.File) {
+		if _, err := runFmt(&medium{typ: file, path: name}, false); err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != testFormatted {
+			t.Errorf("invalid format, expected:\n%s\ngot:\n%s", testFormatted, b)
+		}
+
+		if _, err := runFmt(&medium{typ: file, path: name}, true); err != nil {
+			t.Errorf("formatted file reported: %v", err)
+		}
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFormatCheck(t *testing.T) {
+	const name = "testFile"
+	err := withFile(name, testUnformatted, func(_ *os.File) {
+		out, err := runFmt(&medium{typ: file, path: name}, true)
+		if err != notFormatted {
+			t.Fatalf("unexpected error, expected: %v, got: %v", notFormatted, err)
+		}
+
+		if out != name+"\n" {
+			t.Errorf("invalid output: %s", out)
+		}
+
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != testUnformatted {
+			t.Error("file changed in check mode")
+		}
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFormatStdin(t *testing.T) {
+	err := withStdin(testUnformatted, func() {
+		out, err := runFmt(&medium{typ: stdin}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out != testFormatted {
+			t.Errorf("invalid format, expected:\n%s\ngot:\n%s", testFormatted, out)
+		}
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	if _, err := runFmt(&medium{typ: inline, eskip: "not an eskip document"}, false); err == nil {
+		t.Error("failed to fail")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/routing"
 )
 
 type loadResult struct {
@@ -28,7 +29,10 @@ type loadResult struct {
 	parseErrors map[string]error
 }
 
-var invalidRouteExpression = errors.New("one or more invalid route expressions")
+var (
+	invalidRouteExpression = errors.New("one or more invalid route expressions")
+	routeLintIssues        = errors.New("one or more route lint issues")
+)
 
 // store all loaded routes, even if invalid, and store the
 // parse errors if any.
@@ -106,6 +110,25 @@ func checkCmd(a cmdArgs) error {
 	return checkRepeatedRouteIds(routes)
 }
 
+// command executed for lint.
+func lintCmd(a cmdArgs) error {
+	routes, err := loadRoutesChecked(a.in)
+	if err != nil {
+		return err
+	}
+
+	issues := routing.Lint(routes, routing.MatchingOptionsNone)
+	for _, i := range issues {
+		fmt.Fprintln(stdout, i)
+	}
+
+	if len(issues) > 0 {
+		return routeLintIssues
+	}
+
+	return nil
+}
+
 // command executed for print.
 func printCmd(a cmdArgs) error {
 	lr, err := loadRoutes(a.in)
@@ -119,6 +142,15 @@ func printCmd(a cmdArgs) error {
 		if err := e.Encode(lr.routes); err != nil {
 			return err
 		}
+	} else if printYaml {
+		y, err := eskip.MarshalYAML(lr.routes...)
+		if err != nil {
+			return err
+		}
+
+		if _, err := stdout.Write(y); err != nil {
+			return err
+		}
 	} else {
 		for _, r := range lr.routes {
 			if perr, hasError := lr.parseErrors[r.Id]; hasError {
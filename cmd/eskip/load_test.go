@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/etcd/etcdtest"
 )
 
@@ -284,3 +285,92 @@ func TestPatch(t *testing.T) {
 		}
 	}
 }
+
+func TestLint(t *testing.T) {
+	const invalidDoc = "not an eskip document"
+	_, parseErr := eskip.Parse(invalidDoc)
+	if parseErr == nil {
+		t.Fatal("failed to create the parse error")
+	}
+
+	for _, ti := range []struct {
+		msg      string
+		eskip    string
+		err      error
+		expected []string
+	}{{
+		msg:   "no issues",
+		eskip: `r0: Path("/foo") -> <shunt>; r1: Path("/foo") && Method("GET") -> <shunt>`,
+	}, {
+		msg:   "invalid routes",
+		eskip: invalidDoc,
+		err:   parseErr,
+	}, {
+		msg:   "issues",
+		eskip: `r0 [priority=1]: Path("/foo") -> <shunt>; r1: Path("/foo") && Method("GET") -> <shunt>`,
+		err:   routeLintIssues,
+		expected: []string{
+			"unreachable: route r1 at path /foo is shadowed by r0",
+		},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			var err error
+			preserveOut := stdout
+			buf := &bytes.Buffer{}
+			func() {
+				defer func() { stdout = preserveOut }()
+				stdout = buf
+				err = lintCmd(cmdArgs{in: &medium{typ: inline, eskip: ti.eskip}})
+			}()
+
+			// the parse errors are not comparable, only their messages
+			if err != ti.err && (err == nil || ti.err == nil || err.Error() != ti.err.Error()) {
+				t.Fatalf("unexpected error, expected: %v, got: %v", ti.err, err)
+			}
+
+			if strings.TrimSpace(buf.String()) != strings.Join(ti.expected, "\n") {
+				t.Errorf("invalid output: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestPrintYAML(t *testing.T) {
+	const doc = `r0 [priority=1]: Path("/foo") -> setPath("/bar") -> <roundRobin, "http://10.2.0.1", "http://10.2.0.2">;
+r1: * -> <shunt>;
+`
+
+	print := func(in string, yaml, input bool) (string, error) {
+		preserveOut := stdout
+		buf := &bytes.Buffer{}
+		defer func() {
+			stdout = preserveOut
+			printYaml = false
+			yamlInput = false
+		}()
+
+		stdout = buf
+		printYaml = yaml
+		yamlInput = input
+		err := printCmd(cmdArgs{in: &medium{typ: inline, eskip: in}})
+		return buf.String(), err
+	}
+
+	y, err := print(doc, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(y, "type: lb") || !strings.Contains(y, "priority: 1") {
+		t.Errorf("invalid YAML output: %s", y)
+	}
+
+	e, err := print(y, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.TrimSpace(e) != strings.TrimSpace(doc) {
+		t.Errorf("round trip failed, expected: %s, got: %s", doc, e)
+	}
+}
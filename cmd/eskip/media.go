@@ -40,6 +40,8 @@ const (
 var commandToValidations = map[command]validateSelectFunc{
 	check:  validateSelectRead,
 	print:  validateSelectRead,
+	lint:   validateSelectRead,
+	format: validateSelectFormat,
 	upsert: validateSelectWrite,
 	reset:  validateSelectWrite,
 	delete: validateSelectDelete,
@@ -151,6 +153,30 @@ func validateSelectDelete(media []*medium) (a cmdArgs, err error) {
 	return
 }
 
+// validate medium from args, and check if it can be formatted.
+// (fmt)
+func validateSelectFormat(media []*medium) (a cmdArgs, err error) {
+	if len(media) == 0 {
+		err = missingInput
+		return
+	}
+
+	if len(media) > 1 {
+		err = tooManyInputs
+		return
+	}
+
+	switch media[0].typ {
+	case file, stdin, inline:
+	default:
+		err = invalidInputType
+		return
+	}
+
+	a.in = media[0]
+	return
+}
+
 func validateSelectPatch(media []*medium) (a cmdArgs, err error) {
 	for _, m := range media {
 		switch m.typ {
@@ -115,6 +115,33 @@ func TestValidateSelectMedia(t *testing.T) {
 		nil,
 	}, {
 
+		// returns file input for fmt
+		"fmt",
+		[]*medium{{typ: file, path: "routes.eskip"}},
+		false,
+		nil,
+		&medium{typ: file, path: "routes.eskip"},
+		nil,
+	}, {
+
+		// missing input for fmt
+		"fmt",
+		nil,
+		true,
+		missingInput,
+		nil,
+		nil,
+	}, {
+
+		// etcd cannot be formatted
+		"fmt",
+		[]*medium{{typ: etcd}},
+		true,
+		invalidInputType,
+		nil,
+		nil,
+	}, {
+
 		// missing input
 		"upsert",
 		nil,
@@ -6,6 +6,8 @@ type defaultFunc func(cmdArgs) (cmdArgs, error)
 var commandToDefaultMediums = map[command]defaultFunc{
 	check:  defaultRead,
 	print:  defaultRead,
+	lint:   defaultRead,
+	format: defaultNone,
 	upsert: defaultWrite,
 	reset:  defaultWrite,
 	delete: defaultWrite,
@@ -19,6 +21,10 @@ func defaultRead(a cmdArgs) (aa cmdArgs, err error) {
 	return
 }
 
+func defaultNone(a cmdArgs) (cmdArgs, error) {
+	return a, nil
+}
+
 func defaultWrite(a cmdArgs) (aa cmdArgs, err error) {
 	aa = a
 	if aa.out == nil {
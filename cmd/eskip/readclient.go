@@ -74,6 +74,17 @@ func createInnkeeperClient(m *medium) (*innkeeperclient.Client, error) {
 	return ic, nil
 }
 
+// parses the routes from stdin or the inline routes, in the eskip or,
+// when set, in the YAML format. The format of the files is decided by
+// their extension.
+func parseInput(doc string) ([]*eskip.Route, error) {
+	if yamlInput {
+		return eskip.ParseYAML([]byte(doc))
+	}
+
+	return eskip.Parse(doc)
+}
+
 func (r *stdinReader) LoadAndParseAll() ([]*eskip.RouteInfo, error) {
 	// this pretty much disables continuous piping,
 	// but since the reset command first upserts all
@@ -85,7 +96,7 @@ func (r *stdinReader) LoadAndParseAll() ([]*eskip.RouteInfo, error) {
 		return nil, err
 	}
 
-	routes, err := eskip.Parse(string(doc))
+	routes, err := parseInput(string(doc))
 
 	if err != nil {
 		return nil, err
@@ -95,7 +106,7 @@ func (r *stdinReader) LoadAndParseAll() ([]*eskip.RouteInfo, error) {
 }
 
 func (r *inlineReader) LoadAndParseAll() ([]*eskip.RouteInfo, error) {
-	routes, err := eskip.Parse(r.routes)
+	routes, err := parseInput(r.routes)
 	if err != nil {
 		return nil, err
 	}
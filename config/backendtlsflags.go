@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/skipper"
+)
+
+const backendTLSProfileUsage = `set named TLS profiles for the backend connections, selected by routes with the backendTLS filter, e.g. -backend-tls-profile name=partner-a,cert=/etc/partner-a/tls.crt,key=/etc/partner-a/tls.key
+	possible profile properties:
+	name: the name of the profile, used by the backendTLS filter, required
+	cert: path of the client certificate, rotated certificates are picked up for new connections
+	key: path of the key of the client certificate
+	ca: path of the CA bundle used to verify the backends, multiple may be given separated by ':'
+	server-name: the server name used to verify the backends and sent in the TLS handshake
+	min-version: minimal TLS version, defaults to -tls-min-version`
+
+type backendTLSProfileFlags []skipper.BackendTLSProfile
+
+// yaml representation of a backend TLS profile, with the same keys as the flag
+type backendTLSProfileYAML struct {
+	Name       string `yaml:"name"`
+	CertPath   string `yaml:"cert"`
+	KeyPath    string `yaml:"key"`
+	CAPath     string `yaml:"ca"`
+	ServerName string `yaml:"server-name"`
+	MinVersion string `yaml:"min-version"`
+}
+
+var errInvalidBackendTLSProfile = errors.New("invalid backend TLS profile, name is required, and cert and key must be set together")
+
+func parseTLSVersion(s string) (uint16, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	v, ok := tlsVersionTable[s]
+	if !ok {
+		return 0, fmt.Errorf("invalid TLS version: %s", s)
+	}
+
+	return v, nil
+}
+
+func tlsVersionString(v uint16) string {
+	for s, vi := range tlsVersionTable {
+		if vi == v && strings.Contains(s, ".") {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func validBackendTLSProfile(p skipper.BackendTLSProfile) bool {
+	return p.Name != "" && (p.CertPath == "") == (p.KeyPath == "")
+}
+
+func (b backendTLSProfileFlags) String() string {
+	s := make([]string, len(b))
+	for i, bi := range b {
+		p := []string{"name=" + bi.Name}
+		if bi.CertPath != "" {
+			p = append(p, "cert="+bi.CertPath)
+		}
+
+		if bi.KeyPath != "" {
+			p = append(p, "key="+bi.KeyPath)
+		}
+
+		if bi.CAPath != "" {
+			p = append(p, "ca="+strings.ReplaceAll(bi.CAPath, ",", ":"))
+		}
+
+		if bi.ServerName != "" {
+			p = append(p, "server-name="+bi.ServerName)
+		}
+
+		if bi.MinVersion != 0 {
+			p = append(p, "min-version="+tlsVersionString(bi.MinVersion))
+		}
+
+		s[i] = strings.Join(p, ",")
+	}
+
+	return strings.Join(s, "\n")
+}
+
+func (b *backendTLSProfileFlags) Set(value string) error {
+	var bp skipper.BackendTLSProfile
+
+	vs := strings.Split(value, ",")
+	for _, vi := range vs {
+		kv := strings.SplitN(vi, "=", 2)
+		if len(kv) != 2 {
+			return errInvalidBackendTLSProfile
+		}
+
+		var err error
+		switch kv[0] {
+		case "name":
+			bp.Name = kv[1]
+		case "cert":
+			bp.CertPath = kv[1]
+		case "key":
+			bp.KeyPath = kv[1]
+		case "ca":
+			bp.CAPath = strings.ReplaceAll(kv[1], ":", ",")
+		case "server-name":
+			bp.ServerName = kv[1]
+		case "min-version":
+			bp.MinVersion, err = parseTLSVersion(kv[1])
+		default:
+			return fmt.Errorf("invalid backend TLS profile property: %s", kv[0])
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if !validBackendTLSProfile(bp) {
+		return errInvalidBackendTLSProfile
+	}
+
+	*b = append(*b, bp)
+	return nil
+}
+
+func (b *backendTLSProfileFlags) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var by []backendTLSProfileYAML
+	if err := unmarshal(&by); err != nil {
+		return err
+	}
+
+	for _, bi := range by {
+		v, err := parseTLSVersion(bi.MinVersion)
+		if err != nil {
+			return err
+		}
+
+		bp := skipper.BackendTLSProfile{
+			Name:       bi.Name,
+			CertPath:   bi.CertPath,
+			KeyPath:    bi.KeyPath,
+			CAPath:     strings.ReplaceAll(bi.CAPath, ":", ","),
+			ServerName: bi.ServerName,
+			MinVersion: v,
+		}
+
+		if !validBackendTLSProfile(bp) {
+			return errInvalidBackendTLSProfile
+		}
+
+		*b = append(*b, bp)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zalando/skipper"
+	"gopkg.in/yaml.v2"
+)
+
+func Test_backendTLSProfileFlags_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		wantErr bool
+		want    skipper.BackendTLSProfile
+	}{
+		{
+			name: "name only",
+			args: "name=partner-a",
+			want: skipper.BackendTLSProfile{Name: "partner-a"},
+		},
+		{
+			name: "all properties",
+			args: "name=partner-a,cert=/etc/a/tls.crt,key=/etc/a/tls.key,ca=/etc/a/ca.crt:/etc/a/ca2.crt,server-name=api.partner-a.example.org,min-version=1.3",
+			want: skipper.BackendTLSProfile{
+				Name:       "partner-a",
+				CertPath:   "/etc/a/tls.crt",
+				KeyPath:    "/etc/a/tls.key",
+				CAPath:     "/etc/a/ca.crt,/etc/a/ca2.crt",
+				ServerName: "api.partner-a.example.org",
+				MinVersion: tls.VersionTLS13,
+			},
+		},
+		{
+			name:    "missing name",
+			args:    "cert=/etc/a/tls.crt,key=/etc/a/tls.key",
+			wantErr: true,
+		},
+		{
+			name:    "cert without key",
+			args:    "name=partner-a,cert=/etc/a/tls.crt",
+			wantErr: true,
+		},
+		{
+			name:    "invalid property",
+			args:    "name=partner-a,foo=bar",
+			wantErr: true,
+		},
+		{
+			name:    "invalid min version",
+			args:    "name=partner-a,min-version=2.0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := &backendTLSProfileFlags{}
+
+			if err := bf.Set(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("backendTLSProfileFlags.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr {
+				b := *bf
+				if len(b) != 1 {
+					t.Fatalf("Failed to have profile created: %d != 1", len(b))
+				}
+
+				if !cmp.Equal(b[0], tt.want) {
+					t.Errorf("backendTLSProfileFlags.Set() got v, want v, %v", cmp.Diff(b[0], tt.want))
+				}
+
+				if s := bf.String(); s != tt.args {
+					t.Errorf("backendTLSProfileFlags.String() = %v, want %v", s, tt.args)
+				}
+			}
+		})
+	}
+}
+
+func Test_backendTLSProfileFlags_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yml     string
+		wantErr bool
+		want    backendTLSProfileFlags
+	}{
+		{
+			name: "multiple profiles",
+			yml: `- name: partner-a
+  cert: /etc/a/tls.crt
+  key: /etc/a/tls.key
+  min-version: "1.2"
+- name: partner-b
+  ca: /etc/b/ca.crt
+  server-name: api.partner-b.example.org`,
+			want: backendTLSProfileFlags{{
+				Name:       "partner-a",
+				CertPath:   "/etc/a/tls.crt",
+				KeyPath:    "/etc/a/tls.key",
+				MinVersion: tls.VersionTLS12,
+			}, {
+				Name:       "partner-b",
+				CAPath:     "/etc/b/ca.crt",
+				ServerName: "api.partner-b.example.org",
+			}},
+		},
+		{
+			name: "missing name",
+			yml: `- cert: /etc/a/tls.crt
+  key: /etc/a/tls.key`,
+			wantErr: true,
+		},
+		{
+			name: "invalid min version",
+			yml: `- name: partner-a
+  min-version: foo`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bf := &backendTLSProfileFlags{}
+
+			if err := yaml.Unmarshal([]byte(tt.yml), bf); (err != nil) != tt.wantErr {
+				t.Errorf("backendTLSProfileFlags.UnmarshalYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !cmp.Equal(*bf, tt.want) {
+				t.Errorf("backendTLSProfileFlags.UnmarshalYAML() got v, want v, %v", cmp.Diff(*bf, tt.want))
+			}
+		})
+	}
+}
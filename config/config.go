@@ -22,6 +22,7 @@ import (
 	"github.com/zalando/skipper/net"
 	"github.com/zalando/skipper/proxy"
 	routesrv "github.com/zalando/skipper/routesrv"
+	"github.com/zalando/skipper/routing"
 	"github.com/zalando/skipper/swarm"
 )
 
@@ -34,14 +35,24 @@ type Config struct {
 	ExpectedBytesPerRequest         int            `yaml:"expected-bytes-per-request"`
 	MaxTCPListenerConcurrency       int            `yaml:"max-tcp-listener-concurrency"`
 	MaxTCPListenerQueue             int            `yaml:"max-tcp-listener-queue"`
+	EnableProxyProtocol             bool           `yaml:"enable-proxy-protocol"`
+	ProxyProtocolTrustedCIDRs       *listFlag      `yaml:"proxy-protocol-trusted-cidrs"`
+	Listeners                       listenerFlags  `yaml:"listener"`
+	EnableHTTP3                     bool           `yaml:"enable-http3"`
+	AddressHTTP3                    string         `yaml:"http3-address"`
 	IgnoreTrailingSlash             bool           `yaml:"ignore-trailing-slash"`
 	Insecure                        bool           `yaml:"insecure"`
 	ProxyPreserveHost               bool           `yaml:"proxy-preserve-host"`
 	DevMode                         bool           `yaml:"dev-mode"`
 	SupportListener                 string         `yaml:"support-listener"`
+	DrainTokenFile                  string         `yaml:"drain-token-file"`
 	DebugListener                   string         `yaml:"debug-listener"`
 	CertPathTLS                     string         `yaml:"tls-cert"`
 	KeyPathTLS                      string         `yaml:"tls-key"`
+	CertDirTLS                      string         `yaml:"tls-cert-dir"`
+	CertDirRefreshIntervalTLS       time.Duration  `yaml:"tls-cert-dir-refresh-interval"`
+	ClientCAPathTLS                 string         `yaml:"tls-client-ca"`
+	ClientAuthTLSString             string         `yaml:"tls-client-auth"`
 	StatusChecks                    *listFlag      `yaml:"status-checks"`
 	PrintVersion                    bool           `yaml:"version"`
 	MaxLoopbacks                    int            `yaml:"max-loopbacks"`
@@ -57,6 +68,7 @@ type Config struct {
 	EnableRatelimiters              bool           `yaml:"enable-ratelimits"`
 	Ratelimits                      ratelimitFlags `yaml:"ratelimits"`
 	EnableRouteLIFOMetrics          bool           `yaml:"enable-route-lifo-metrics"`
+	EnableRouteFIFOMetrics          bool           `yaml:"enable-route-fifo-metrics"`
 	MetricsFlavour                  *listFlag      `yaml:"metrics-flavour"`
 	FilterPlugins                   *pluginFlag    `yaml:"filter-plugin"`
 	PredicatePlugins                *pluginFlag    `yaml:"predicate-plugin"`
@@ -120,12 +132,15 @@ type Config struct {
 	RoutesFile                string               `yaml:"routes-file"`
 	RoutesURLs                *listFlag            `yaml:"routes-urls"`
 	InlineRoutes              string               `yaml:"inline-routes"`
+	InlineRoutesYAML          string               `yaml:"inline-routes-yaml"`
 	AppendFilters             *defaultFiltersFlags `yaml:"default-filters-append"`
 	PrependFilters            *defaultFiltersFlags `yaml:"default-filters-prepend"`
 	EditRoute                 *routeChangerConfig  `yaml:"edit-route"`
 	CloneRoute                *routeChangerConfig  `yaml:"clone-route"`
 	SourcePollTimeout         int64                `yaml:"source-poll-timeout"`
 	WaitFirstRouteLoad        bool                 `yaml:"wait-first-route-load"`
+	RouteHistorySize          int                  `yaml:"route-history-size"`
+	LintRoutes                bool                 `yaml:"lint-routes"`
 
 	// Forwarded headers
 	ForwardedHeadersList            *listFlag            `yaml:"forwarded-headers"`
@@ -153,6 +168,7 @@ type Config struct {
 	KubernetesEastWestRangePredicates       []*eskip.Predicate  `yaml:"-"`
 	KubernetesOnlyAllowedExternalNames      bool                `yaml:"kubernetes-only-allowed-external-names"`
 	KubernetesAllowedExternalNames          regexpListFlag      `yaml:"kubernetes-allowed-external-names"`
+	KubernetesReportInvalidRoutes           bool                `yaml:"kubernetes-report-invalid-routes"`
 
 	// Default filters
 	DefaultFiltersDir string `yaml:"default-filters-dir"`
@@ -191,6 +207,12 @@ type Config struct {
 	// TLS version
 	TLSMinVersion string `yaml:"tls-min-version"`
 
+	// TLS client certificate policy of the listeners, nil when not set
+	ClientAuthTLS *tls.ClientAuthType `yaml:"-"`
+
+	// TLS profiles of the backend connections
+	BackendTLSProfiles backendTLSProfileFlags `yaml:"backend-tls-profile"`
+
 	// API Monitoring
 	ApiUsageMonitoringEnable                       bool   `yaml:"enable-api-usage-monitoring"`
 	ApiUsageMonitoringRealmKeys                    string `yaml:"api-usage-monitoring-realm-keys"`
@@ -205,6 +227,7 @@ type Config struct {
 	BackendFlushInterval         time.Duration `yaml:"backend-flush-interval"`
 	ExperimentalUpgrade          bool          `yaml:"experimental-upgrade"`
 	ExperimentalUpgradeAudit     bool          `yaml:"experimental-upgrade-audit"`
+	UpgradeDrainTimeout          time.Duration `yaml:"upgrade-drain-timeout"`
 	ReadTimeoutServer            time.Duration `yaml:"read-timeout-server"`
 	ReadHeaderTimeoutServer      time.Duration `yaml:"read-header-timeout-server"`
 	WriteTimeoutServer           time.Duration `yaml:"write-timeout-server"`
@@ -257,6 +280,7 @@ func NewConfig() *Config {
 	cfg := new(Config)
 	cfg.MetricsFlavour = commaListFlag("codahale", "prometheus")
 	cfg.StatusChecks = commaListFlag()
+	cfg.ProxyProtocolTrustedCIDRs = commaListFlag()
 	cfg.FilterPlugins = newPluginFlag()
 	cfg.PredicatePlugins = newPluginFlag()
 	cfg.DataclientPlugins = newPluginFlag()
@@ -280,14 +304,25 @@ func NewConfig() *Config {
 	flag.IntVar(&cfg.ExpectedBytesPerRequest, "expected-bytes-per-request", 50*1024, "bytes per request, that is used to calculate concurrency limits to buffer connection spikes")
 	flag.IntVar(&cfg.MaxTCPListenerConcurrency, "max-tcp-listener-concurrency", 0, "sets hardcoded max for TCP listener concurrency, normally calculated based on available memory cgroups with max TODO")
 	flag.IntVar(&cfg.MaxTCPListenerQueue, "max-tcp-listener-queue", 0, "sets hardcoded max queue size for TCP listener, normally calculated 10x concurrency with max TODO:50k")
+	flag.BoolVar(&cfg.EnableProxyProtocol, "enable-proxy-protocol", false, "enables accepting PROXY protocol v1 and v2 headers on the proxy listener")
+	flag.Var(cfg.ProxyProtocolTrustedCIDRs, "proxy-protocol-trusted-cidrs", "comma separated list of CIDRs allowed to send PROXY protocol headers, required by -enable-proxy-protocol and the proxy-protocol of the additional listeners")
+	flag.Var(&cfg.Listeners, "listener", listenerUsage)
+	flag.BoolVar(&cfg.EnableHTTP3, "enable-http3", false, "enables an HTTP/3 (QUIC) listener next to the TLS proxy listener, advertised in the Alt-Svc header of the TLS responses")
+	flag.StringVar(&cfg.AddressHTTP3, "http3-address", "", "UDP address of the HTTP/3 listener, defaults to the value of -address")
+	flag.Var(&cfg.BackendTLSProfiles, "backend-tls-profile", backendTLSProfileUsage)
 	flag.BoolVar(&cfg.IgnoreTrailingSlash, "ignore-trailing-slash", false, "flag indicating to ignore trailing slashes in paths when routing")
 	flag.BoolVar(&cfg.Insecure, "insecure", false, "flag indicating to ignore the verification of the TLS certificates of the backend services")
 	flag.BoolVar(&cfg.ProxyPreserveHost, "proxy-preserve-host", false, "flag indicating to preserve the incoming request 'Host' header in the outgoing requests")
 	flag.BoolVar(&cfg.DevMode, "dev-mode", false, "enables developer time behavior, like ubuffered routing updates")
 	flag.StringVar(&cfg.SupportListener, "support-listener", ":9911", "network address used for exposing the /metrics endpoint. An empty value disables support endpoint.")
+	flag.StringVar(&cfg.DrainTokenFile, "drain-token-file", "", "path of the file containing the bearer token of the /drain endpoint of the support listener. The endpoint is enabled only when set")
 	flag.StringVar(&cfg.DebugListener, "debug-listener", "", "when this address is set, skipper starts an additional listener returning the original and transformed requests")
 	flag.StringVar(&cfg.CertPathTLS, "tls-cert", "", "the path on the local filesystem to the certificate file(s) (including any intermediates), multiple may be given comma separated")
 	flag.StringVar(&cfg.KeyPathTLS, "tls-key", "", "the path on the local filesystem to the certificate's private key file(s), multiple keys may be given comma separated - the order must match the certs")
+	flag.StringVar(&cfg.CertDirTLS, "tls-cert-dir", "", "the path on the local filesystem to a directory of certificates and keys in .crt and .key file pairs, selected by SNI and reloaded on changes, requires -tls-cert and -tls-key for the default certificate")
+	flag.StringVar(&cfg.ClientCAPathTLS, "tls-client-ca", "", "the path on the local filesystem to the CA bundle file(s) used to verify the client certificates, multiple may be given comma separated")
+	flag.StringVar(&cfg.ClientAuthTLSString, "tls-client-auth", "", "the client certificate policy of the TLS listeners: <none|request|require|verify-if-given|require-and-verify>, defaults to verify-if-given when -tls-client-ca is set, otherwise to none")
+	flag.DurationVar(&cfg.CertDirRefreshIntervalTLS, "tls-cert-dir-refresh-interval", time.Minute, "sets how often the directory set by -tls-cert-dir is checked for changes")
 	flag.Var(cfg.StatusChecks, "status-checks", "experimental URLs to check before reporting healthy on startup")
 	flag.BoolVar(&cfg.PrintVersion, "version", false, "print Skipper version")
 	flag.IntVar(&cfg.MaxLoopbacks, "max-loopbacks", proxy.DefaultMaxLoopbacks, "maximum number of loopbacks for an incoming request, set to -1 to disable loopbacks")
@@ -303,6 +338,7 @@ func NewConfig() *Config {
 	flag.BoolVar(&cfg.EnableRatelimiters, "enable-ratelimits", false, enableRatelimitsUsage)
 	flag.Var(&cfg.Ratelimits, "ratelimits", ratelimitsUsage)
 	flag.BoolVar(&cfg.EnableRouteLIFOMetrics, "enable-route-lifo-metrics", false, "enable metrics for the individual route LIFO queues")
+	flag.BoolVar(&cfg.EnableRouteFIFOMetrics, "enable-route-fifo-metrics", false, "enable metrics for the individual route FIFO queues")
 	flag.Var(cfg.MetricsFlavour, "metrics-flavour", "Metrics flavour is used to change the exposed metrics format. Supported metric formats: 'codahale' and 'prometheus', you can select both of them")
 	flag.Var(cfg.FilterPlugins, "filter-plugin", "set a custom filter plugins to load, a comma separated list of name and arguments")
 	flag.Var(cfg.PredicatePlugins, "predicate-plugin", "set a custom predicate plugins to load, a comma separated list of name and arguments")
@@ -364,12 +400,15 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.RoutesFile, "routes-file", "", "file containing route definitions")
 	flag.Var(cfg.RoutesURLs, "routes-urls", "comma separated URLs to route definitions in eskip format")
 	flag.StringVar(&cfg.InlineRoutes, "inline-routes", "", "inline routes in eskip format")
+	flag.StringVar(&cfg.InlineRoutesYAML, "inline-routes-yaml", "", "inline routes in the YAML format of the eskip routes")
 	flag.Int64Var(&cfg.SourcePollTimeout, "source-poll-timeout", int64(3000), "polling timeout of the routing data sources, in milliseconds")
 	flag.Var(cfg.AppendFilters, "default-filters-append", "set of default filters to apply to append to all filters of all routes")
 	flag.Var(cfg.PrependFilters, "default-filters-prepend", "set of default filters to apply to prepend to all filters of all routes")
 	flag.Var(cfg.EditRoute, "edit-route", "match and edit filters and predicates of all routes")
 	flag.Var(cfg.CloneRoute, "clone-route", "clone all matching routes and replace filters and predicates of all matched routes")
 	flag.BoolVar(&cfg.WaitFirstRouteLoad, "wait-first-route-load", false, "prevent starting the listener before the first batch of routes were loaded")
+	flag.IntVar(&cfg.RouteHistorySize, "route-history-size", routing.DefaultHistorySize, "number of route table generations whose changes are served on the /routes/history endpoint of the support listener, negative value disables the history")
+	flag.BoolVar(&cfg.LintRoutes, "lint-routes", false, "log the unreachable, duplicate and ambiguous routes on startup and on every route update")
 
 	// Forwarded headers
 	flag.Var(cfg.ForwardedHeadersList, "forwarded-headers", "comma separated list of headers to add to the incoming request before routing\n"+
@@ -397,6 +436,7 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.KubernetesEastWestRangePredicatesString, "kubernetes-east-west-range-predicates", "", "set the predicates that will be appended to routes identified as to -kubernetes-east-west-range-domains")
 	flag.BoolVar(&cfg.KubernetesOnlyAllowedExternalNames, "kubernetes-only-allowed-external-names", false, "only accept external name services, route group network backends and route group explicit LB endpoints from an allow list defined by zero or more -kubernetes-allowed-external-name flags")
 	flag.Var(&cfg.KubernetesAllowedExternalNames, "kubernetes-allowed-external-name", "set zero or more regular expressions from which at least one should be matched by the external name services, route group network addresses and explicit endpoints domain names")
+	flag.BoolVar(&cfg.KubernetesReportInvalidRoutes, "kubernetes-report-invalid-routes", false, "create Warning events on the Ingress and RouteGroup objects whose routes are invalid, requires the permission to create events")
 
 	// Auth:
 	flag.BoolVar(&cfg.EnableOAuth2GrantFlow, "enable-oauth2-grant-flow", false, "enables OAuth2 Grant Flow filter")
@@ -448,6 +488,7 @@ func NewConfig() *Config {
 	flag.DurationVar(&cfg.BackendFlushInterval, "backend-flush-interval", 20*time.Millisecond, "flush interval for upgraded proxy connections")
 	flag.BoolVar(&cfg.ExperimentalUpgrade, "experimental-upgrade", false, "enable experimental feature to handle upgrade protocol requests")
 	flag.BoolVar(&cfg.ExperimentalUpgradeAudit, "experimental-upgrade-audit", false, "enable audit logging of the request line and the messages during the experimental web socket upgrades")
+	flag.DurationVar(&cfg.UpgradeDrainTimeout, "upgrade-drain-timeout", proxy.DefaultUpgradeDrainTimeout, "sets the time the upgraded connections are kept open after draining started")
 	flag.DurationVar(&cfg.ReadTimeoutServer, "read-timeout-server", 5*time.Minute, "set ReadTimeout for http server connections")
 	flag.DurationVar(&cfg.ReadHeaderTimeoutServer, "read-header-timeout-server", 60*time.Second, "set ReadHeaderTimeout for http server connections")
 	flag.DurationVar(&cfg.WriteTimeoutServer, "write-timeout-server", 60*time.Second, "set WriteTimeout for http server connections")
@@ -539,7 +580,18 @@ func (c *Config) Parse() error {
 		return err
 	}
 
+	var clientAuth *tls.ClientAuthType
+	if c.ClientAuthTLSString != "" {
+		ca, err := parseClientAuth(c.ClientAuthTLSString)
+		if err != nil {
+			return err
+		}
+
+		clientAuth = &ca
+	}
+
 	c.ApplicationLogLevel = logLevel
+	c.ClientAuthTLS = clientAuth
 	c.KubernetesPathMode = kubernetesPathMode
 	c.KubernetesEastWestRangePredicates = kubernetesEastWestRangePredicates
 	c.HistogramMetricBuckets = histogramBuckets
@@ -623,12 +675,23 @@ func (c *Config) ToOptions() skipper.Options {
 		ExpectedBytesPerRequest:         c.ExpectedBytesPerRequest,
 		MaxTCPListenerConcurrency:       c.MaxTCPListenerConcurrency,
 		MaxTCPListenerQueue:             c.MaxTCPListenerQueue,
+		EnableProxyProtocol:             c.EnableProxyProtocol,
+		ProxyProtocolTrustedCIDRs:       c.ProxyProtocolTrustedCIDRs.values,
+		Listeners:                       c.Listeners,
+		EnableHTTP3:                     c.EnableHTTP3,
+		AddressHTTP3:                    c.AddressHTTP3,
+		BackendTLSProfiles:              c.BackendTLSProfiles,
 		IgnoreTrailingSlash:             c.IgnoreTrailingSlash,
 		DevMode:                         c.DevMode,
 		SupportListener:                 c.SupportListener,
+		DrainTokenFile:                  c.DrainTokenFile,
 		DebugListener:                   c.DebugListener,
 		CertPathTLS:                     c.CertPathTLS,
 		KeyPathTLS:                      c.KeyPathTLS,
+		CertDirTLS:                      c.CertDirTLS,
+		ClientCAPathTLS:                 c.ClientCAPathTLS,
+		ClientAuthTLS:                   c.ClientAuthTLS,
+		CertDirRefreshIntervalTLS:       c.CertDirRefreshIntervalTLS,
 		MaxLoopbacks:                    c.MaxLoopbacks,
 		DefaultHTTPStatus:               c.DefaultHTTPStatus,
 		LoadBalancerHealthCheckInterval: c.LoadBalancerHealthCheckInterval,
@@ -639,6 +702,7 @@ func (c *Config) ToOptions() skipper.Options {
 		EnableRatelimiters:              c.EnableRatelimiters,
 		RatelimitSettings:               c.Ratelimits,
 		EnableRouteLIFOMetrics:          c.EnableRouteLIFOMetrics,
+		EnableRouteFIFOMetrics:          c.EnableRouteFIFOMetrics,
 		MetricsFlavours:                 c.MetricsFlavour.values,
 		FilterPlugins:                   c.FilterPlugins.values,
 		PredicatePlugins:                c.PredicatePlugins.values,
@@ -699,6 +763,7 @@ func (c *Config) ToOptions() skipper.Options {
 		WatchRoutesFile:           c.RoutesFile,
 		RoutesURLs:                c.RoutesURLs.values,
 		InlineRoutes:              c.InlineRoutes,
+		InlineRoutesYAML:          c.InlineRoutesYAML,
 		DefaultFilters: &eskip.DefaultFilters{
 			Prepend: c.PrependFilters.filters,
 			Append:  c.AppendFilters.filters,
@@ -707,6 +772,8 @@ func (c *Config) ToOptions() skipper.Options {
 		EditRoute:          eskip.NewEditor(c.EditRoute.Reg, c.EditRoute.Repl),
 		SourcePollTimeout:  time.Duration(c.SourcePollTimeout) * time.Millisecond,
 		WaitFirstRouteLoad: c.WaitFirstRouteLoad,
+		RouteHistorySize:   c.RouteHistorySize,
+		LintRoutes:         c.LintRoutes,
 
 		// Kubernetes:
 		Kubernetes:                         c.KubernetesIngress,
@@ -726,6 +793,7 @@ func (c *Config) ToOptions() skipper.Options {
 		KubernetesEastWestRangePredicates:  c.KubernetesEastWestRangePredicates,
 		KubernetesOnlyAllowedExternalNames: c.KubernetesOnlyAllowedExternalNames,
 		KubernetesAllowedExternalNames:     c.KubernetesAllowedExternalNames,
+		KubernetesReportInvalidRoutes:      c.KubernetesReportInvalidRoutes,
 
 		// API Monitoring:
 		ApiUsageMonitoringEnable:                c.ApiUsageMonitoringEnable,
@@ -769,6 +837,7 @@ func (c *Config) ToOptions() skipper.Options {
 		BackendFlushInterval:         c.BackendFlushInterval,
 		ExperimentalUpgrade:          c.ExperimentalUpgrade,
 		ExperimentalUpgradeAudit:     c.ExperimentalUpgradeAudit,
+		UpgradeDrainTimeout:          c.UpgradeDrainTimeout,
 		ReadTimeoutServer:            c.ReadTimeoutServer,
 		ReadHeaderTimeoutServer:      c.ReadHeaderTimeoutServer,
 		WriteTimeoutServer:           c.WriteTimeoutServer,
@@ -850,17 +919,18 @@ func (c *Config) ToOptions() skipper.Options {
 	return options
 }
 
+var tlsVersionTable = map[string]uint16{
+	"1.3": tls.VersionTLS13,
+	"13":  tls.VersionTLS13,
+	"1.2": tls.VersionTLS12,
+	"12":  tls.VersionTLS12,
+	"1.1": tls.VersionTLS11,
+	"11":  tls.VersionTLS11,
+	"1.0": tls.VersionTLS10,
+	"10":  tls.VersionTLS10,
+}
+
 func (c *Config) getMinTLSVersion() uint16 {
-	tlsVersionTable := map[string]uint16{
-		"1.3": tls.VersionTLS13,
-		"13":  tls.VersionTLS13,
-		"1.2": tls.VersionTLS12,
-		"12":  tls.VersionTLS12,
-		"1.1": tls.VersionTLS11,
-		"11":  tls.VersionTLS11,
-		"1.0": tls.VersionTLS10,
-		"10":  tls.VersionTLS10,
-	}
 	if v, ok := tlsVersionTable[c.TLSMinVersion]; ok {
 		return v
 	}
@@ -868,6 +938,23 @@ func (c *Config) getMinTLSVersion() uint16 {
 	return tlsVersionTable[defaultMinTLSVersion]
 }
 
+func parseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch s {
+	case "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify-if-given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require-and-verify":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("invalid tls-client-auth: %s", s)
+	}
+}
+
 func (c *Config) parseHistogramBuckets() ([]float64, error) {
 	if c.HistogramMetricBucketsString == "" {
 		return prometheus.DefBuckets, nil
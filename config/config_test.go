@@ -86,7 +86,9 @@ func Test_NewConfig(t *testing.T) {
 			want: &Config{
 				ConfigFile:                              "testdata/test.yaml",
 				Address:                                 "localhost:8080",
+				CertDirRefreshIntervalTLS:               time.Minute,
 				StatusChecks:                            nil,
+				ProxyProtocolTrustedCIDRs:               commaListFlag(),
 				ExpectedBytesPerRequest:                 50 * 1024,
 				SupportListener:                         ":9911",
 				MaxLoopbacks:                            12,
@@ -115,6 +117,7 @@ func Test_NewConfig(t *testing.T) {
 				CloneRoute:                              &routeChangerConfig{},
 				EditRoute:                               &routeChangerConfig{},
 				SourcePollTimeout:                       3000,
+				RouteHistorySize:                        32,
 				KubernetesEastWestRangeDomains:          commaListFlag(),
 				KubernetesHealthcheck:                   true,
 				KubernetesHTTPSRedirect:                 true,
@@ -133,6 +136,7 @@ func Test_NewConfig(t *testing.T) {
 				IdleConnsPerHost:                        64,
 				CloseIdleConnsPeriod:                    20 * time.Second,
 				BackendFlushInterval:                    20 * time.Millisecond,
+				UpgradeDrainTimeout:                     30 * time.Second,
 				ReadTimeoutServer:                       5 * time.Minute,
 				ReadHeaderTimeoutServer:                 1 * time.Minute,
 				WriteTimeoutServer:                      1 * time.Minute,
@@ -289,3 +293,26 @@ func TestDeprecatedFlags(t *testing.T) {
 		}
 	}
 }
+
+func Test_parseClientAuth(t *testing.T) {
+	for input, want := range map[string]tls.ClientAuthType{
+		"none":               tls.NoClientCert,
+		"request":            tls.RequestClientCert,
+		"require":            tls.RequireAnyClientCert,
+		"verify-if-given":    tls.VerifyClientCertIfGiven,
+		"require-and-verify": tls.RequireAndVerifyClientCert,
+	} {
+		got, err := parseClientAuth(input)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", input, err)
+		} else if got != want {
+			t.Errorf("invalid client auth for %q, expected: %v, got: %v", input, want, got)
+		}
+	}
+
+	for _, input := range []string{"", "always"} {
+		if _, err := parseClientAuth(input); err == nil {
+			t.Errorf("failed to fail: %q", input)
+		}
+	}
+}
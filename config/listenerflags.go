@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper"
+)
+
+const listenerUsage = `set additional proxy listeners, e.g. -listener name=internal,address=:9091
+	possible listener properties:
+	name: the name of the listener, used by the Listener predicate, required
+	address: the address to listen on, required
+	tls-cert: path of the TLS certificate, when using TLS
+	tls-key: path of the TLS key, when using TLS
+	tcp-queue: true enables the TCP LIFO queue on the listener
+	max-concurrency: the max number of concurrently accepted connections, when the TCP queue is enabled
+	max-queue: the max number of pending connections, when the TCP queue is enabled
+	proxy-protocol: true enables accepting PROXY protocol headers on the listener`
+
+type listenerFlags []skipper.ListenerOptions
+
+// yaml representation of a listener, with the same keys as the flag
+type listenerYAML struct {
+	Name                      string `yaml:"name"`
+	Address                   string `yaml:"address"`
+	CertPathTLS               string `yaml:"tls-cert"`
+	KeyPathTLS                string `yaml:"tls-key"`
+	EnableTCPQueue            bool   `yaml:"tcp-queue"`
+	MaxTCPListenerConcurrency int    `yaml:"max-concurrency"`
+	MaxTCPListenerQueue       int    `yaml:"max-queue"`
+	EnableProxyProtocol       bool   `yaml:"proxy-protocol"`
+}
+
+var errInvalidListenerConfig = errors.New("invalid listener config, name and address are required")
+
+func (l listenerFlags) String() string {
+	s := make([]string, len(l))
+	for i, li := range l {
+		p := []string{"name=" + li.Name, "address=" + li.Address}
+		if li.CertPathTLS != "" {
+			p = append(p, "tls-cert="+li.CertPathTLS)
+		}
+
+		if li.KeyPathTLS != "" {
+			p = append(p, "tls-key="+li.KeyPathTLS)
+		}
+
+		if li.EnableTCPQueue {
+			p = append(p, "tcp-queue=true")
+		}
+
+		if li.MaxTCPListenerConcurrency != 0 {
+			p = append(p, fmt.Sprintf("max-concurrency=%d", li.MaxTCPListenerConcurrency))
+		}
+
+		if li.MaxTCPListenerQueue != 0 {
+			p = append(p, fmt.Sprintf("max-queue=%d", li.MaxTCPListenerQueue))
+		}
+
+		if li.EnableProxyProtocol {
+			p = append(p, "proxy-protocol=true")
+		}
+
+		s[i] = strings.Join(p, ",")
+	}
+
+	return strings.Join(s, "\n")
+}
+
+func (l *listenerFlags) Set(value string) error {
+	var lo skipper.ListenerOptions
+
+	vs := strings.Split(value, ",")
+	for _, vi := range vs {
+		kv := strings.SplitN(vi, "=", 2)
+		if len(kv) != 2 {
+			return errInvalidListenerConfig
+		}
+
+		var err error
+		switch kv[0] {
+		case "name":
+			lo.Name = kv[1]
+		case "address":
+			lo.Address = kv[1]
+		case "tls-cert":
+			lo.CertPathTLS = kv[1]
+		case "tls-key":
+			lo.KeyPathTLS = kv[1]
+		case "tcp-queue":
+			lo.EnableTCPQueue, err = strconv.ParseBool(kv[1])
+		case "max-concurrency":
+			lo.MaxTCPListenerConcurrency, err = strconv.Atoi(kv[1])
+		case "max-queue":
+			lo.MaxTCPListenerQueue, err = strconv.Atoi(kv[1])
+		case "proxy-protocol":
+			lo.EnableProxyProtocol, err = strconv.ParseBool(kv[1])
+		default:
+			return fmt.Errorf("invalid listener property: %s", kv[0])
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if lo.Name == "" || lo.Address == "" {
+		return errInvalidListenerConfig
+	}
+
+	*l = append(*l, lo)
+	return nil
+}
+
+func (l *listenerFlags) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var ly []listenerYAML
+	if err := unmarshal(&ly); err != nil {
+		return err
+	}
+
+	for _, li := range ly {
+		if li.Name == "" || li.Address == "" {
+			return errInvalidListenerConfig
+		}
+
+		*l = append(*l, skipper.ListenerOptions(li))
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zalando/skipper"
+	"gopkg.in/yaml.v2"
+)
+
+func Test_listenerFlags_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		wantErr bool
+		want    skipper.ListenerOptions
+	}{
+		{
+			name: "name and address",
+			args: "name=internal,address=:9091",
+			want: skipper.ListenerOptions{
+				Name:    "internal",
+				Address: ":9091",
+			},
+		},
+		{
+			name: "all properties",
+			args: "name=internal,address=127.0.0.1:9091,tls-cert=/etc/cert.pem,tls-key=/etc/key.pem,tcp-queue=true,max-concurrency=100,max-queue=10,proxy-protocol=true",
+			want: skipper.ListenerOptions{
+				Name:                      "internal",
+				Address:                   "127.0.0.1:9091",
+				CertPathTLS:               "/etc/cert.pem",
+				KeyPathTLS:                "/etc/key.pem",
+				EnableTCPQueue:            true,
+				MaxTCPListenerConcurrency: 100,
+				MaxTCPListenerQueue:       10,
+				EnableProxyProtocol:       true,
+			},
+		},
+		{
+			name:    "missing name",
+			args:    "address=:9091",
+			wantErr: true,
+		},
+		{
+			name:    "missing address",
+			args:    "name=internal",
+			wantErr: true,
+		},
+		{
+			name:    "invalid property",
+			args:    "name=internal,address=:9091,foo=bar",
+			wantErr: true,
+		},
+		{
+			name:    "invalid max concurrency",
+			args:    "name=internal,address=:9091,max-concurrency=many",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := &listenerFlags{}
+
+			if err := lf.Set(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("listenerFlags.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr {
+				l := *lf
+				if len(l) != 1 {
+					t.Fatalf("Failed to have listener created: %d != 1", len(l))
+				}
+
+				if !cmp.Equal(l[0], tt.want) {
+					t.Errorf("listenerFlags.Set() got v, want v, %v", cmp.Diff(l[0], tt.want))
+				}
+
+				if s := lf.String(); s != tt.args {
+					t.Errorf("listenerFlags.String() = %v, want %v", s, tt.args)
+				}
+			}
+		})
+	}
+}
+
+func Test_listenerFlags_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yml     string
+		wantErr bool
+		want    listenerFlags
+	}{
+		{
+			name: "multiple listeners",
+			yml: `- name: internal
+  address: :9091
+  tcp-queue: true
+  max-concurrency: 100
+- name: public-tls
+  address: :9443
+  tls-cert: /etc/cert.pem
+  tls-key: /etc/key.pem`,
+			want: listenerFlags{{
+				Name:                      "internal",
+				Address:                   ":9091",
+				EnableTCPQueue:            true,
+				MaxTCPListenerConcurrency: 100,
+			}, {
+				Name:        "public-tls",
+				Address:     ":9443",
+				CertPathTLS: "/etc/cert.pem",
+				KeyPathTLS:  "/etc/key.pem",
+			}},
+		},
+		{
+			name: "missing address",
+			yml: `- name: internal
+  tcp-queue: true`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := &listenerFlags{}
+
+			if err := yaml.Unmarshal([]byte(tt.yml), lf); (err != nil) != tt.wantErr {
+				t.Errorf("listenerFlags.UnmarshalYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !cmp.Equal(*lf, tt.want) {
+				t.Errorf("listenerFlags.UnmarshalYAML() got v, want v, %v", cmp.Diff(*lf, tt.want))
+			}
+		})
+	}
+}
@@ -225,6 +225,33 @@ func (c *clusterClient) getJSON(uri string, a interface{}) error {
 	return err
 }
 
+func (c *clusterClient) postJSON(uri string, a interface{}) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.createRequest(uri, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.Method = "POST"
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Debugf("request to %s failed: %v", uri, err)
+		return err
+	}
+
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("request failed, status: %d, %s", rsp.StatusCode, rsp.Status)
+	}
+
+	return nil
+}
+
 func (c *clusterClient) clusterHasRouteGroups() (bool, error) {
 	var crl ClusterResourceList
 	if err := c.getJSON(ZalandoResourcesClusterURI, &crl); err != nil { // it probably should bounce once
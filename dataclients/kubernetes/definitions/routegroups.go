@@ -149,6 +149,9 @@ type RouteSpec struct {
 
 	// Methods defines valid HTTP methods for the specified RouteSpec
 	Methods []string `json:"methods,omitempty"`
+
+	// Priority sets the explicit priority of the generated routes
+	Priority int `json:"priority,omitempty"`
 }
 
 func (meta *Metadata) ToResourceID() ResourceID {
@@ -0,0 +1,236 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/routing"
+)
+
+const (
+	eventsNamespaceFmt   = "/api/v1/namespaces/%s/events"
+	invalidRouteReason   = "InvalidRoute"
+	eventSourceComponent = "skipper"
+	eventQueueSize       = 16
+)
+
+type objectReference struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Uid        string `json:"uid,omitempty"`
+}
+
+type eventMetadata struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type eventSource struct {
+	Component string `json:"component"`
+}
+
+type event struct {
+	Metadata       eventMetadata   `json:"metadata"`
+	InvolvedObject objectReference `json:"involvedObject"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Type           string          `json:"type"`
+	Source         eventSource     `json:"source"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+	Count          int             `json:"count"`
+}
+
+// routeOrigin is an Ingress or a RouteGroup, identified by the prefixes
+// of the IDs of the routes generated from it.
+type routeOrigin struct {
+	object   objectReference
+	prefixes []string
+}
+
+// eventReporter creates Kubernetes events on the Ingress and RouteGroup
+// objects, whose routes were found invalid by the routing.
+type eventReporter struct {
+	client   *clusterClient
+	mu       sync.Mutex
+	origins  []routeOrigin
+	reported map[string]string
+	events   chan []*event
+	quit     <-chan struct{}
+}
+
+func newEventReporter(c *clusterClient, quit <-chan struct{}) *eventReporter {
+	r := &eventReporter{
+		client:   c,
+		reported: make(map[string]string),
+		events:   make(chan []*event, eventQueueSize),
+		quit:     quit,
+	}
+
+	go r.run()
+	return r
+}
+
+func ingressOrigin(m *definitions.Metadata) routeOrigin {
+	ns := nonWord.ReplaceAllString(m.Namespace, "_")
+	name := nonWord.ReplaceAllString(m.Name, "_")
+	return routeOrigin{
+		object: objectReference{
+			Kind:       "Ingress",
+			APIVersion: "extensions/v1beta1",
+			Namespace:  m.Namespace,
+			Name:       m.Name,
+			Uid:        m.Uid,
+		},
+
+		// the custom routes of the ingress are prefixed only with a
+		// single underscore
+		prefixes: []string{fmt.Sprintf("%s_%s__%s_", ingressRouteIDPrefix, ns, name)},
+	}
+}
+
+func routeGroupOrigin(m *definitions.Metadata) routeOrigin {
+	ns := toSymbol(namespaceString(m.Namespace))
+	name := toSymbol(m.Name)
+	return routeOrigin{
+		object: objectReference{
+			Kind:       "RouteGroup",
+			APIVersion: "zalando.org/v1",
+			Namespace:  namespaceString(m.Namespace),
+			Name:       m.Name,
+			Uid:        m.Uid,
+		},
+		prefixes: []string{
+			fmt.Sprintf("kube_rg__%s__%s__", ns, name),
+			fmt.Sprintf("kube_rg__internal_%s__%s__", ns, name),
+		},
+	}
+}
+
+// setState updates the known objects that the routes can originate
+// from.
+func (r *eventReporter) setState(s *clusterState) {
+	var origins []routeOrigin
+	for _, i := range s.ingresses {
+		if i.Metadata != nil {
+			origins = append(origins, ingressOrigin(i.Metadata))
+		}
+	}
+
+	for _, rg := range s.routeGroups {
+		if rg.Metadata != nil {
+			origins = append(origins, routeGroupOrigin(rg.Metadata))
+		}
+	}
+
+	r.mu.Lock()
+	r.origins = origins
+	r.mu.Unlock()
+}
+
+// origin finds the object of a route ID, by the longest matching ID
+// prefix. The caller must hold the lock.
+func (r *eventReporter) origin(id string) (objectReference, bool) {
+	if strings.HasPrefix(id, "kubeew") {
+		id = ingressRouteIDPrefix + id[len("kubeew"):]
+	}
+
+	var (
+		found   objectReference
+		longest int
+	)
+
+	for _, o := range r.origins {
+		for _, p := range o.prefixes {
+			if len(p) > longest && strings.HasPrefix(id, p) {
+				found, longest = o.object, len(p)
+			}
+		}
+	}
+
+	return found, longest > 0
+}
+
+func invalidRouteMessage(ir routing.InvalidRoute) string {
+	switch {
+	case ir.Filter != "":
+		return fmt.Sprintf("route %s is invalid, filter %s: %s", ir.Id, ir.Filter, ir.Error)
+	case ir.Predicate != "":
+		return fmt.Sprintf("route %s is invalid, predicate %s: %s", ir.Id, ir.Predicate, ir.Error)
+	default:
+		return fmt.Sprintf("route %s is invalid: %s", ir.Id, ir.Error)
+	}
+}
+
+// report creates events only for the routes that became invalid, or
+// whose error changed since the last report, to avoid creating events
+// on every poll.
+func (r *eventReporter) report(invalid []routing.InvalidRoute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*event
+	reported := make(map[string]string, len(invalid))
+	now := time.Now().UTC()
+	for _, ir := range invalid {
+		reported[ir.Id] = ir.Error
+		if e, ok := r.reported[ir.Id]; ok && e == ir.Error {
+			continue
+		}
+
+		o, ok := r.origin(ir.Id)
+		if !ok {
+			continue
+		}
+
+		events = append(events, &event{
+			Metadata: eventMetadata{
+				Namespace: o.Namespace,
+				Name:      fmt.Sprintf("%s.%x", o.Name, now.UnixNano()+int64(len(events))),
+			},
+			InvolvedObject: o,
+			Reason:         invalidRouteReason,
+			Message:        invalidRouteMessage(ir),
+			Type:           "Warning",
+			Source:         eventSource{Component: eventSourceComponent},
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+			Count:          1,
+		})
+	}
+
+	r.reported = reported
+	if len(events) == 0 {
+		return
+	}
+
+	select {
+	case r.events <- events:
+	default:
+		log.Errorf("Failed to report %d invalid routes as Kubernetes events, queue is full", len(events))
+	}
+}
+
+func (r *eventReporter) run() {
+	for {
+		select {
+		case events := <-r.events:
+			for _, e := range events {
+				uri := fmt.Sprintf(eventsNamespaceFmt, e.Metadata.Namespace)
+				if err := r.client.postJSON(uri, e); err != nil {
+					log.Errorf("Failed to create Kubernetes event for %s %s/%s: %v",
+						e.InvolvedObject.Kind, e.InvolvedObject.Namespace, e.InvolvedObject.Name, err)
+				}
+			}
+		case <-r.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/routing"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	paths  []string
+	events []*event
+}
+
+func (r *eventRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var e event
+	if req.Method != "POST" || json.NewDecoder(req.Body).Decode(&e) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	r.paths = append(r.paths, req.URL.Path)
+	r.events = append(r.events, &e)
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (r *eventRecorder) waitFor(t *testing.T, n int) []*event {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		r.mu.Lock()
+		c := len(r.events)
+		r.mu.Unlock()
+		if c >= n {
+			break
+		}
+
+		select {
+		case <-timeout:
+			t.Fatalf("timeout while waiting for %d events, got: %d", n, c)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*event(nil), r.events...)
+}
+
+func newTestEventReporter(t *testing.T) (*eventReporter, *eventRecorder) {
+	rec := &eventRecorder{}
+	s := httptest.NewServer(rec)
+	quit := make(chan struct{})
+	t.Cleanup(func() {
+		close(quit)
+		s.Close()
+	})
+
+	r := newEventReporter(&clusterClient{apiURL: s.URL, httpClient: s.Client()}, quit)
+	r.setState(&clusterState{
+		ingresses: []*definitions.IngressItem{
+			{Metadata: &definitions.Metadata{Namespace: "default", Name: "foo", Uid: "uid-foo"}},
+			{Metadata: &definitions.Metadata{Namespace: "default", Name: "foo-bar", Uid: "uid-foo-bar"}},
+		},
+		routeGroups: []*definitions.RouteGroupItem{
+			{Metadata: &definitions.Metadata{Namespace: "team", Name: "baz", Uid: "uid-baz"}},
+		},
+	})
+
+	return r, rec
+}
+
+func TestInvalidRouteOrigin(t *testing.T) {
+	r, _ := newTestEventReporter(t)
+	for _, test := range []struct {
+		id   string
+		kind string
+		name string
+	}{
+		{id: routeID("default", "foo", "example.org", "/", "svc"), kind: "Ingress", name: "foo"},
+		{id: routeID("default", "foo-bar", "example.org", "/", "svc"), kind: "Ingress", name: "foo-bar"},
+		{id: routeIDForCustom("default", "foo", "custom", "example.org", 0), kind: "Ingress", name: "foo"},
+		{id: eastWestRouteID(routeID("default", "foo", "example.org", "/", "svc")), kind: "Ingress", name: "foo"},
+		{id: rgRouteID("team", "baz", "get", 0, 1, false), kind: "RouteGroup", name: "baz"},
+		{id: rgRouteID("team", "baz", "get", 0, 1, true), kind: "RouteGroup", name: "baz"},
+		{id: routeIDForRedirectRoute(rgRouteID("team", "baz", "", 0, 0, false), true), kind: "RouteGroup", name: "baz"},
+		{id: rgRouteID("team", "qux", "", 0, 0, false)},
+		{id: "kube__healthz_up"},
+	} {
+		t.Run(test.id, func(t *testing.T) {
+			o, ok := r.origin(test.id)
+			if test.kind == "" {
+				if ok {
+					t.Fatalf("unexpected origin: %v", o)
+				}
+
+				return
+			}
+
+			if !ok || o.Kind != test.kind || o.Name != test.name {
+				t.Errorf("invalid origin, expected: %s %s, got: %v", test.kind, test.name, o)
+			}
+		})
+	}
+}
+
+func TestReportInvalidRoutes(t *testing.T) {
+	r, rec := newTestEventReporter(t)
+
+	ingressRoute := routeID("default", "foo", "example.org", "/", "svc")
+	rgRoute := rgRouteID("team", "baz", "get", 0, 0, false)
+	r.report([]routing.InvalidRoute{
+		{Id: ingressRoute, Filter: "setPath", Error: "invalid filter parameters"},
+		{Id: rgRoute, Predicate: "Foo", Error: "predicate not found: 'Foo'"},
+		{Id: "unknown", Error: "invalid backend"},
+	})
+
+	events := rec.waitFor(t, 2)
+	sort.Slice(events, func(i, j int) bool { return events[i].InvolvedObject.Kind < events[j].InvolvedObject.Kind })
+
+	ing, rg := events[0], events[1]
+	if ing.InvolvedObject.Kind != "Ingress" || ing.InvolvedObject.Uid != "uid-foo" || ing.Metadata.Namespace != "default" ||
+		ing.Reason != invalidRouteReason || ing.Type != "Warning" ||
+		!strings.Contains(ing.Message, ingressRoute) || !strings.Contains(ing.Message, "filter setPath") {
+		t.Errorf("invalid ingress event: %+v", ing)
+	}
+
+	if rg.InvolvedObject.Kind != "RouteGroup" || rg.InvolvedObject.Uid != "uid-baz" || rg.Metadata.Namespace != "team" ||
+		!strings.Contains(rg.Message, "predicate Foo") {
+		t.Errorf("invalid route group event: %+v", rg)
+	}
+
+	rec.mu.Lock()
+	paths := append([]string(nil), rec.paths...)
+	rec.mu.Unlock()
+	sort.Strings(paths)
+	if paths[0] != "/api/v1/namespaces/default/events" || paths[1] != "/api/v1/namespaces/team/events" {
+		t.Errorf("invalid event paths: %v", paths)
+	}
+
+	// the same errors are not reported again, only the changed ones
+	r.report([]routing.InvalidRoute{
+		{Id: ingressRoute, Filter: "setPath", Error: "invalid filter parameters"},
+		{Id: rgRoute, Predicate: "Foo", Error: "invalid predicate parameters"},
+	})
+
+	events = rec.waitFor(t, 3)
+	if len(events) != 3 || !strings.Contains(events[2].Message, "invalid predicate parameters") {
+		t.Errorf("invalid events: %d", len(events))
+	}
+
+	// reported again after it was fixed and broken again
+	r.report(nil)
+	r.report([]routing.InvalidRoute{{Id: ingressRoute, Filter: "setPath", Error: "invalid filter parameters"}})
+	rec.waitFor(t, 4)
+}
+
+func TestClientReportInvalidRoutesDisabled(t *testing.T) {
+	var _ routing.InvalidRouteReporter = &Client{}
+
+	// must not panic without the reporter
+	(&Client{}).ReportInvalidRoutes([]routing.InvalidRoute{{Id: "foo"}})
+}
@@ -57,6 +57,7 @@ var nonWord = regexp.MustCompile(`\W`)
 var errNotAllowedExternalName = errors.New("ingress with not allowed external name service")
 
 func (ic *ingressContext) addHostRoute(host string, route *eskip.Route) {
+	setSourceMetadata(route, "Ingress", ic.ingress.Metadata)
 	ic.hostRoutes[host] = append(ic.hostRoutes[host], route)
 }
 
@@ -707,6 +708,7 @@ func (ing *ingress) ingressRoute(
 	var route *eskip.Route
 	if r, ok, err := ing.convertDefaultBackend(state, i); ok {
 		route = r
+		setSourceMetadata(route, "Ingress", i.Metadata)
 	} else if err != nil {
 		ic.logger.Errorf("error while converting default backend: %v", err)
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
 )
 
 const (
@@ -105,6 +106,11 @@ type Options struct {
 	// filter, and the available predicates need to include the Source() predicate.
 	ProvideHealthcheck bool
 
+	// Draining, when closed, makes the healthcheck route fail, the same
+	// way as receiving the TERM signal. Used only when
+	// ProvideHealthcheck is set.
+	Draining <-chan struct{}
+
 	// ProvideHTTPSRedirect, when set, tells the data client to append an HTTPS redirect route to the
 	// ingress routes. This route will detect the X-Forwarded-Proto=http and respond with a 301 message
 	// to the HTTPS equivalent of the same request (using the redirectTo(301, "https:") filter). The
@@ -180,6 +186,11 @@ type Options struct {
 	// AllowedExternalNames contains regexp patterns of those domain names that are allowed to be
 	// used with external name services (type=ExternalName).
 	AllowedExternalNames []*regexp.Regexp
+
+	// ReportInvalidRoutes enables creating Warning events on the
+	// Ingress and RouteGroup objects, whose routes are invalid. It
+	// requires the permission to create events.
+	ReportInvalidRoutes bool
 }
 
 // Client is a Skipper DataClient implementation used to create routes based on Kubernetes Ingress settings.
@@ -195,8 +206,10 @@ type Client struct {
 	httpsRedirectCode      int
 	current                map[string]*eskip.Route
 	sigs                   chan os.Signal
+	draining               <-chan struct{}
 	quit                   chan struct{}
 	defaultFiltersDir      string
+	events                 *eventReporter
 }
 
 // New creates and initializes a Kubernetes DataClient.
@@ -266,6 +279,11 @@ func New(o Options) (*Client, error) {
 	ing := newIngress(o)
 	rg := newRouteGroups(o)
 
+	var events *eventReporter
+	if o.ReportInvalidRoutes {
+		events = newEventReporter(clusterClient, quit)
+	}
+
 	return &Client{
 		ClusterClient:          clusterClient,
 		ingress:                ing,
@@ -275,9 +293,11 @@ func New(o Options) (*Client, error) {
 		httpsRedirectCode:      o.HTTPSRedirectCode,
 		current:                make(map[string]*eskip.Route),
 		sigs:                   sigs,
+		draining:               o.Draining,
 		reverseSourcePredicate: o.ReverseSourcePredicate,
 		quit:                   quit,
 		defaultFiltersDir:      o.DefaultFiltersDir,
+		events:                 events,
 	}, nil
 }
 
@@ -340,6 +360,10 @@ func (c *Client) loadAndConvert() ([]*eskip.Route, error) {
 		return nil, err
 	}
 
+	if c.events != nil {
+		c.events.setState(state)
+	}
+
 	defaultFilters := c.fetchDefaultFilterConfigs()
 
 	ri, err := c.ingress.convert(state, defaultFilters)
@@ -413,6 +437,9 @@ func (c *Client) hasReceivedTerm() bool {
 	case s := <-c.sigs:
 		log.Infof("shutdown, caused by %s, set health check to be unhealthy", s)
 		c.termReceived = true
+	case <-c.draining:
+		log.Info("draining, set health check to be unhealthy")
+		c.termReceived = true
 	default:
 	}
 
@@ -496,6 +523,14 @@ func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
 	return updatedRoutes, deletedIDs, nil
 }
 
+// ReportInvalidRoutes implements routing.InvalidRouteReporter. When
+// enabled, it creates events on the objects of the invalid routes.
+func (c *Client) ReportInvalidRoutes(invalid []routing.InvalidRoute) {
+	if c.events != nil {
+		c.events.report(invalid)
+	}
+}
+
 func (c *Client) Close() {
 	if c != nil && c.quit != nil {
 		close(c.quit)
@@ -2052,6 +2052,38 @@ func TestHealthcheckOnTerm(t *testing.T) {
 
 		checkHealthcheck(t, r, true, false, false)
 	})
+
+	t.Run("healthcheck false after draining started, update", func(t *testing.T) {
+		draining := make(chan struct{})
+		c, err := New(Options{
+			KubernetesURL:      api.server.URL,
+			ProvideHealthcheck: true,
+			Draining:           draining,
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		defer c.Close()
+
+		r, err := c.LoadAll()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		checkHealthcheck(t, r, true, true, false)
+
+		close(draining)
+		r, _, err = c.LoadUpdate()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		checkHealthcheck(t, r, true, false, false)
+	})
 }
 
 func TestCatchAllRoutes(t *testing.T) {
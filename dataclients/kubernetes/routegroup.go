@@ -424,7 +424,7 @@ func implicitGroupRoutes(ctx *routeGroupContext) ([]*eskip.Route, error) {
 
 func transformExplicitGroupRoute(ctx *routeContext) (*eskip.Route, error) {
 	gr := ctx.groupRoute
-	r := &eskip.Route{Id: ctx.id}
+	r := &eskip.Route{Id: ctx.id, Priority: gr.Priority}
 
 	// Path or PathSubtree, prefer Path if we have, because it is more specifc
 	if gr.Path != "" {
@@ -614,6 +614,10 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 				continue
 			}
 
+			for _, route := range ri {
+				setSourceMetadata(route, "RouteGroup", rg.Metadata)
+			}
+
 			catchAll := hostCatchAllRoutes(ctx.hostRoutes, func(host string) string {
 				// "catchall" won't conflict with any HTTP method
 				return rgRouteID("", toSymbol(host), "catchall", 0, 0, false)
@@ -650,6 +654,10 @@ func (r *routeGroups) convert(s *clusterState, df defaultFilters) ([]*eskip.Rout
 				continue
 			}
 
+			for _, route := range internalRi {
+				setSourceMetadata(route, "RouteGroup", rg.Metadata)
+			}
+
 			catchAll := hostCatchAllRoutes(internalCtx.hostRoutes, func(host string) string {
 				// "catchall" won't conflict with any HTTP method
 				return rgRouteID("", toSymbol(host), "catchall", 0, 0, true)
@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/eskip"
+)
+
+// The route metadata keys identifying the Ingress or RouteGroup that a
+// route was generated from. The catch-all routes shared by the resources
+// of the same host don't have them.
+const (
+	MetadataKind      = "kubernetes_kind"
+	MetadataNamespace = "kubernetes_namespace"
+	MetadataName      = "kubernetes_name"
+)
+
+// setSourceMetadata sets the source resource in the route metadata,
+// preserving the metadata defined by the user, e.g. in the custom routes
+// of an ingress. The map is always replaced, because the routes created
+// from the same definition share it.
+func setSourceMetadata(r *eskip.Route, kind string, m *definitions.Metadata) {
+	if r == nil || m == nil {
+		return
+	}
+
+	md := make(map[string]string, len(r.Metadata)+3)
+	for k, v := range r.Metadata {
+		md[k] = v
+	}
+
+	md[MetadataKind] = kind
+	md[MetadataNamespace] = namespaceString(m.Namespace)
+	md[MetadataName] = m.Name
+	r.Metadata = md
+}
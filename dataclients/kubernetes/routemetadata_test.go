@@ -0,0 +1,74 @@
+package kubernetes_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/dataclients/kubernetes"
+	"github.com/zalando/skipper/dataclients/kubernetes/kubernetestest"
+)
+
+func TestRouteSourceMetadata(t *testing.T) {
+	ingress, err := os.Open("testdata/ingress/eastwest/ing-with-1host-1prule-1customroute.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer ingress.Close()
+
+	routeGroup, err := os.Open("testdata/routegroups/convert/priority.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer routeGroup.Close()
+
+	a, err := kubernetestest.NewAPI(kubernetestest.TestAPIOptions{}, ingress, routeGroup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	c, err := kubernetes.New(kubernetes.Options{KubernetesURL: s.URL, KubernetesEnableEastWest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer c.Close()
+
+	routes, err := c.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ingressRoutes, routeGroupRoutes int
+	for _, r := range routes {
+		kind := r.Metadata[kubernetes.MetadataKind]
+		switch {
+		case strings.Contains(r.Id, "catchall"):
+			if r.Metadata != nil {
+				t.Errorf("unexpected metadata of the catch-all route %s: %v", r.Id, r.Metadata)
+			}
+		case kind == "Ingress":
+			ingressRoutes++
+			if r.Metadata[kubernetes.MetadataNamespace] != "foo" || r.Metadata[kubernetes.MetadataName] != "qux" {
+				t.Errorf("invalid metadata of route %s: %v", r.Id, r.Metadata)
+			}
+		case kind == "RouteGroup":
+			routeGroupRoutes++
+			if r.Metadata[kubernetes.MetadataNamespace] != "default" || r.Metadata[kubernetes.MetadataName] != "myapp" {
+				t.Errorf("invalid metadata of route %s: %v", r.Id, r.Metadata)
+			}
+		default:
+			t.Errorf("missing source metadata of route %s", r.Id)
+		}
+	}
+
+	if ingressRoutes != 4 || routeGroupRoutes != 4 {
+		t.Errorf("invalid number of routes, ingress: %d, routegroup: %d", ingressRoutes, routeGroupRoutes)
+	}
+}
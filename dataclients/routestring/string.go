@@ -5,6 +5,10 @@
 //
 //     skipper -inline-routes '* -> inlineContent("Hello, world!") -> <shunt>'
 //
+// or in the YAML format:
+//
+//     skipper -inline-routes-yaml '{routes: [{backend: {type: shunt}}]}'
+//
 package routestring
 
 import (
@@ -27,6 +31,17 @@ func New(r string) (routing.DataClient, error) {
 	return &routes{parsed: parsed}, nil
 }
 
+// NewYAML creates a data client that parses a YAML document of routes, as
+// accepted by eskip.ParseYAML, and serves it for the routing package.
+func NewYAML(r string) (routing.DataClient, error) {
+	parsed, err := eskip.ParseYAML([]byte(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return &routes{parsed: parsed}, nil
+}
+
 func (r *routes) LoadAll() ([]*eskip.Route, error) {
 	return r.parsed, nil
 }
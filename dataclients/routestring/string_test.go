@@ -109,3 +109,46 @@ func TestRouteString(t *testing.T) {
 		})
 	}
 }
+
+func TestRouteStringYAML(t *testing.T) {
+	if _, err := NewYAML("routes: [{id: foo}]"); err == nil {
+		t.Error("failed to fail")
+	}
+
+	dc, err := NewYAML(`
+routes:
+- id: static_content
+  filters:
+  - name: static
+    args: [/, /var/www]
+  backend:
+    type: shunt
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := dc.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*eskip.Route{{
+		Id: "static_content",
+		Filters: []*eskip.Filter{{
+			Name: "static",
+			Args: []interface{}{
+				"/",
+				"/var/www",
+			},
+		}},
+		BackendType: eskip.ShuntBackend,
+		Shunt:       true,
+	}}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Error("invalid routes received")
+		t.Log("got:     ", litter.Sdump(r))
+		t.Log("expected:", litter.Sdump(expected))
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Package drain implements the draining mode of a Skipper instance.
+
+When draining starts, the instance keeps serving the requests, but it
+signals to the load balancers and to the clients that it is going away:
+the health check routes using the Shutdown predicate start failing, the
+HTTP/1 responses get the Connection: close header, the HTTP/2
+connections receive a GOAWAY frame, and the upgraded connections are
+closed after a grace period. Draining can't be stopped, it is followed
+by the shutdown of the instance.
+
+Draining is started at runtime, without sending a signal to the process,
+with a POST request to the /drain endpoint of the support listener:
+
+	curl -X POST -H "Authorization: Bearer $(cat /etc/skipper/drain-token)" localhost:9911/drain
+
+The endpoint is enabled only when a token is configured. The current
+state is reported by a GET request to the same endpoint, and as the
+"draining" gauge.
+*/
+package drain
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/metrics"
+	"github.com/zalando/skipper/secrets"
+)
+
+// MetricsKey is the key of the gauge, that is set to 1 when the
+// instance is draining, and 0 otherwise.
+const MetricsKey = "draining"
+
+// Drainer holds the draining state of an instance.
+type Drainer struct {
+	metrics  metrics.Metrics
+	once     sync.Once
+	draining int32
+	done     chan struct{}
+}
+
+type state struct {
+	Draining bool `json:"draining"`
+}
+
+// New creates a Drainer in non-draining state. The metrics argument is
+// optional.
+func New(m metrics.Metrics) *Drainer {
+	d := &Drainer{metrics: m, done: make(chan struct{})}
+	if m != nil {
+		m.UpdateGauge(MetricsKey, 0)
+	}
+
+	return d
+}
+
+// Drain starts draining. Calling it multiple times has no additional
+// effect.
+func (d *Drainer) Drain() {
+	d.once.Do(func() {
+		log.Info("Start draining")
+		atomic.StoreInt32(&d.draining, 1)
+		if d.metrics != nil {
+			d.metrics.UpdateGauge(MetricsKey, 1)
+		}
+
+		close(d.done)
+	})
+}
+
+// Draining tells whether draining was started.
+func (d *Drainer) Draining() bool {
+	return atomic.LoadInt32(&d.draining) != 0
+}
+
+// Done returns a channel that is closed when draining starts.
+func (d *Drainer) Done() <-chan struct{} {
+	return d.done
+}
+
+// Wrap returns a handler that sets the Connection: close header on the
+// HTTP/1 and HTTP/2 responses, while draining. The HTTP/2 server sends
+// a GOAWAY frame when the header is set.
+func (d *Drainer) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 3 && d.Draining() {
+			w.Header().Set("Connection", "close")
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// NewHandler returns the handler of the drain endpoint. The requests
+// need to present the token, found in the secret tokenName, as a bearer
+// token. GET requests return the current state, and POST requests start
+// draining.
+func (d *Drainer) NewHandler(sr secrets.SecretsReader, tokenName string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := sr.GetSecret(tokenName)
+		if !ok || len(token) == 0 {
+			log.Errorf("Drain token not found: %s", tokenName)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), token) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			d.Drain()
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state{Draining: d.Draining()})
+	})
+}
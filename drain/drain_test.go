@@ -0,0 +1,165 @@
+package drain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/metrics/metricstest"
+	"github.com/zalando/skipper/secrets"
+)
+
+func TestDrain(t *testing.T) {
+	m := &metricstest.MockMetrics{}
+	d := New(m)
+	if v, ok := m.Gauge(MetricsKey); !ok || v != 0 {
+		t.Errorf("invalid gauge, expected: 0, got: %v", v)
+	}
+
+	if d.Draining() {
+		t.Fatal("unexpected draining")
+	}
+
+	select {
+	case <-d.Done():
+		t.Fatal("unexpected draining")
+	default:
+	}
+
+	d.Drain()
+	d.Drain()
+
+	if !d.Draining() {
+		t.Fatal("failed to start draining")
+	}
+
+	select {
+	case <-d.Done():
+	default:
+		t.Fatal("failed to close done channel")
+	}
+
+	if v, _ := m.Gauge(MetricsKey); v != 1 {
+		t.Errorf("invalid gauge, expected: 1, got: %v", v)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	d := New(nil)
+	h := d.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+
+	serve := func(protoMajor int) string {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.ProtoMajor = protoMajor
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Header().Get("Connection")
+	}
+
+	if c := serve(1); c != "" {
+		t.Errorf("unexpected Connection header: %s", c)
+	}
+
+	d.Drain()
+	for _, p := range []int{1, 2} {
+		if c := serve(p); c != "close" {
+			t.Errorf("invalid Connection header for HTTP/%d, expected: close, got: %s", p, c)
+		}
+	}
+
+	if c := serve(3); c != "" {
+		t.Errorf("unexpected Connection header for HTTP/3: %s", c)
+	}
+}
+
+type secretMap map[string][]byte
+
+func (m secretMap) GetSecret(s string) ([]byte, bool) {
+	b, ok := m[s]
+	return b, ok
+}
+
+func (secretMap) Close() {}
+
+var _ secrets.SecretsReader = secretMap{}
+
+func TestHandler(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		secrets  secretMap
+		method   string
+		auth     string
+		status   int
+		body     string
+		draining bool
+	}{{
+		title:   "no token",
+		secrets: secretMap{},
+		method:  "POST",
+		auth:    "Bearer foo",
+		status:  http.StatusInternalServerError,
+	}, {
+		title:   "no auth",
+		secrets: secretMap{"token": []byte("foo")},
+		method:  "POST",
+		status:  http.StatusUnauthorized,
+	}, {
+		title:   "wrong token",
+		secrets: secretMap{"token": []byte("foo")},
+		method:  "POST",
+		auth:    "Bearer bar",
+		status:  http.StatusUnauthorized,
+	}, {
+		title:   "not bearer",
+		secrets: secretMap{"token": []byte("foo")},
+		method:  "POST",
+		auth:    "Basic foo",
+		status:  http.StatusUnauthorized,
+	}, {
+		title:   "get state",
+		secrets: secretMap{"token": []byte("foo")},
+		method:  "GET",
+		auth:    "Bearer foo",
+		status:  http.StatusOK,
+		body:    `{"draining":false}`,
+	}, {
+		title:   "invalid method",
+		secrets: secretMap{"token": []byte("foo")},
+		method:  "DELETE",
+		auth:    "Bearer foo",
+		status:  http.StatusMethodNotAllowed,
+	}, {
+		title:    "start draining",
+		secrets:  secretMap{"token": []byte("foo")},
+		method:   "POST",
+		auth:     "Bearer foo",
+		status:   http.StatusOK,
+		body:     `{"draining":true}`,
+		draining: true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			d := New(nil)
+			h := d.NewHandler(test.secrets, "token")
+
+			r := httptest.NewRequest(test.method, "/drain", nil)
+			if test.auth != "" {
+				r.Header.Set("Authorization", test.auth)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Errorf("invalid status, expected: %d, got: %d", test.status, w.Code)
+			}
+
+			if b := strings.TrimSpace(w.Body.String()); test.body != "" && b != test.body {
+				t.Errorf("invalid body, expected: %s, got: %s", test.body, b)
+			}
+
+			if d.Draining() != test.draining {
+				t.Errorf("invalid draining state, expected: %t, got: %t", test.draining, d.Draining())
+			}
+		})
+	}
+}
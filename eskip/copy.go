@@ -51,6 +51,19 @@ func CopyFilters(f []*Filter) []*Filter {
 	return c
 }
 
+func copyMetadata(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+
+	return c
+}
+
 // Copy creates a canonical copy of the input route. See also Canonical().
 func Copy(r *Route) *Route {
 	if r == nil {
@@ -67,6 +80,8 @@ func Copy(r *Route) *Route {
 	c.LBAlgorithm = r.LBAlgorithm
 	c.LBEndpoints = make([]string, len(r.LBEndpoints))
 	copy(c.LBEndpoints, r.LBEndpoints)
+	c.Priority = r.Priority
+	c.Metadata = copyMetadata(r.Metadata)
 	return c
 }
 
@@ -46,6 +46,13 @@ func TestCopy(t *testing.T) {
 		if c.LBEndpoints[0] == r.LBEndpoints[0] {
 			t.Error("failed to copy LB endpoints")
 		}
+
+		if r.Metadata != nil {
+			r.Metadata["test-map-identity"] = "foo"
+			if _, ok := c.Metadata["test-map-identity"]; ok {
+				t.Error("failed to copy metadata")
+			}
+		}
 	}
 
 	t.Run("filters", func(t *testing.T) {
@@ -133,6 +140,7 @@ func TestCopy(t *testing.T) {
 				BackendType: LBBackend,
 				LBAlgorithm: "roundRobin",
 				LBEndpoints: []string{"10.0.0.1:80", "10.0.0.2:80"},
+				Metadata:    map[string]string{"owner": "team-a"},
 			}
 
 			c := Copy(r)
@@ -224,6 +224,24 @@ The dynamic backend means that a filter must be present in the filter chain whic
 must set the target url explicitly.
 
 
+Route Attributes
+
+A route definition can contain attributes between square brackets, after
+the route id. The priority attribute controls the matching order:
+
+	route1 [priority=100]: Path("/api") -> "https://api.example.org";
+
+When multiple routes are found in the same position of the path tree,
+the routes with higher priority are evaluated first, regardless of the
+number of their predicates and their weight. The default priority is 0.
+
+Every other attribute is stored in the Metadata field of the route, and
+needs to have a string value. The metadata doesn't affect the routing.
+The keys that are not valid symbols need to be quoted:
+
+	route1 [owner="team-a", "example.org/ticket"="ABC-1"]: Path("/api") -> "https://api.example.org";
+
+
 Comments
 
 An eskip document can contain comments. The rule for comments is simple:
@@ -268,5 +286,34 @@ Serializing
 Serializing a single route happens by calling its String method.
 Serializing a complete routing table happens by calling the
 eskip.String method.
+
+
+Formatting
+
+The eskip.Format function formats an eskip document in a canonical way,
+unlike the serialization, keeping the comments, the empty lines between
+the routes, and the order and the original form of the predicates and
+the filters. The same formatting is applied by the eskip fmt command.
+
+
+YAML Format
+
+The routes can be defined also as YAML documents, with the eskip.ParseYAML
+function, and serialized to YAML with the eskip.MarshalYAML function. The
+conversion between the two formats doesn't lose any information:
+
+	routes:
+	- id: route1
+	  priority: 100
+	  predicates:
+	  - name: Path
+	    args: ["/api"]
+	  backend:
+	    type: lb
+	    algorithm: roundRobin
+	    endpoints: ["http://10.2.0.1:8080", "http://10.2.0.2:8080"]
+
+The JSON schema of the YAML documents can be found in the
+routes.schema.json file of this package.
 */
 package eskip
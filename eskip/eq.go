@@ -16,6 +16,16 @@ func comparePredicateName(p []*Predicate) func(int, int) bool {
 	}
 }
 
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
 func hasDuplicateID(r []*Route) bool {
 	for i := 1; i < len(r); i++ {
 		if r[i-1].Id == r[i].Id {
@@ -107,6 +117,10 @@ func eq2(left, right *Route) bool {
 		return false
 	}
 
+	if lc.Priority != rc.Priority {
+		return false
+	}
+
 	return true
 }
 
@@ -130,7 +144,7 @@ func eq2Lists(left, right []*Route) bool {
 // Duplicate IDs are considered invalid for Eq, and it returns false
 // in this case.
 //
-// The Name and Namespace fields are ignored.
+// The Name, Namespace and Metadata fields are ignored.
 //
 // If there are multiple methods, only the last one is considered, to
 // reproduce the route matching (even if how it works, may not be the
@@ -176,6 +190,10 @@ func EqLists(r ...[]*Route) bool {
 // Canonical creates a copy of the route, but doesn't necessarily creates a
 // copy of every field. See also Copy().
 //
+// The legacy header predicates are converted in the order of the header
+// names, so that routes with the same legacy headers have the same
+// canonical form.
+//
 func Canonical(r *Route) *Route {
 	if r == nil {
 		return nil
@@ -221,17 +239,18 @@ func Canonical(r *Route) *Route {
 		)
 	}
 
-	// legacy header:
-	for name, value := range r.Headers {
+	// legacy header, sorted by the name, because the predicates are
+	// compared in order:
+	for _, name := range sortedKeys(r.Headers) {
 		c.Predicates = append(
 			c.Predicates,
-			&Predicate{Name: "Header", Args: []interface{}{name, value}},
+			&Predicate{Name: "Header", Args: []interface{}{name, r.Headers[name]}},
 		)
 	}
 
 	// legacy header regexp:
-	for name, values := range r.HeaderRegexps {
-		for _, value := range values {
+	for _, name := range sortedKeys(r.HeaderRegexps) {
+		for _, value := range r.HeaderRegexps[name] {
 			c.Predicates = append(
 				c.Predicates,
 				&Predicate{Name: "HeaderRegexp", Args: []interface{}{name, value}},
@@ -263,6 +282,9 @@ func Canonical(r *Route) *Route {
 		sort.Strings(c.LBEndpoints)
 	}
 
+	c.Priority = r.Priority
+	c.Metadata = copyMetadata(r.Metadata)
+
 	// Name and Namespace stripped
 
 	return c
@@ -36,6 +36,9 @@ func TestEq(t *testing.T) {
 	}, {
 		title:  "non-eq id",
 		routes: []*Route{{Id: "foo"}, {Id: "bar"}},
+	}, {
+		title:  "non-eq priority",
+		routes: []*Route{{Id: "foo", Priority: 1}, {Id: "foo"}},
 	}, {
 		title:  "non-eq predicate count",
 		routes: []*Route{{Predicates: []*Predicate{{}, {}}}, {Predicates: []*Predicate{{}}}},
@@ -126,6 +129,16 @@ func TestEq(t *testing.T) {
 			LBEndpoints: []string{"https://one.example.org", "https://two.example.org"},
 		}},
 		expect: true,
+	}, {
+		title: "eq legacy headers",
+		routes: []*Route{{
+			Headers:       map[string]string{"X-A": "a", "X-B": "b", "X-C": "c", "X-D": "d", "X-E": "e"},
+			HeaderRegexps: map[string][]string{"X-F": {"f"}, "X-G": {"g"}, "X-H": {"h"}, "X-I": {"i"}},
+		}, {
+			Headers:       map[string]string{"X-A": "a", "X-B": "b", "X-C": "c", "X-D": "d", "X-E": "e"},
+			HeaderRegexps: map[string][]string{"X-F": {"f"}, "X-G": {"g"}, "X-H": {"h"}, "X-I": {"i"}},
+		}},
+		expect: true,
 	}, {
 		title:  "one out of 3 non-eq",
 		routes: []*Route{{Id: "foo"}, {Id: "foo"}, {Id: "bar"}},
@@ -272,6 +285,22 @@ func TestCanonical(t *testing.T) {
 				{Name: "HeaderRegexp", Args: []interface{}{"X-Foo", "foo"}},
 			},
 		},
+	}, {
+		title: "multiple headers to predicates, sorted by name",
+		route: &Route{
+			Headers:       map[string]string{"X-C": "c", "X-A": "a", "X-B": "b"},
+			HeaderRegexps: map[string][]string{"X-E": {"e1", "e2"}, "X-D": {"d"}},
+		},
+		expect: &Route{
+			Predicates: []*Predicate{
+				{Name: "Header", Args: []interface{}{"X-A", "a"}},
+				{Name: "Header", Args: []interface{}{"X-B", "b"}},
+				{Name: "Header", Args: []interface{}{"X-C", "c"}},
+				{Name: "HeaderRegexp", Args: []interface{}{"X-D", "d"}},
+				{Name: "HeaderRegexp", Args: []interface{}{"X-E", "e1"}},
+				{Name: "HeaderRegexp", Args: []interface{}{"X-E", "e2"}},
+			},
+		},
 	}, {
 		title:  "legacy shunt",
 		route:  &Route{Shunt: true},
@@ -295,6 +324,19 @@ func TestCanonical(t *testing.T) {
 	}
 }
 
+func TestCanonicalCopiesMetadata(t *testing.T) {
+	r := &Route{Metadata: map[string]string{"owner": "team-foo"}}
+	c := Canonical(r)
+	if !reflect.DeepEqual(c.Metadata, r.Metadata) {
+		t.Fatalf("metadata lost: %v", c.Metadata)
+	}
+
+	c.Metadata["owner"] = "team-bar"
+	if r.Metadata["owner"] != "team-foo" {
+		t.Error("metadata not copied")
+	}
+}
+
 func TestCanonicalList(t *testing.T) {
 	for _, test := range []struct {
 		title  string
@@ -5,6 +5,7 @@ package eskip
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"regexp"
 	"strings"
@@ -235,6 +236,14 @@ type parsedRoute struct {
 	backend     string
 	lbAlgorithm string
 	lbEndpoints []string
+	attributes  []*routeAttribute
+}
+
+// An attribute of a route definition, e.g. [priority=100]. The name of
+// the metadata attributes can be quoted, e.g. ["example.org/owner"="foo"].
+type routeAttribute struct {
+	name  string
+	value interface{}
 }
 
 // A Predicate object represents a parsed, in-memory, route matching predicate
@@ -330,6 +339,19 @@ type Route struct {
 	// load balancing backends.
 	LBEndpoints []string
 
+	// Priority of the route. When multiple routes are found in the
+	// same position of the path tree, the routes with higher priority
+	// are evaluated first, regardless of their predicates and weight.
+	// The default priority is 0.
+	// E.g. route1 [priority=100]: Path("/x") -> ...
+	Priority int
+
+	// Metadata contains arbitrary information about the route, e.g.
+	// the owner team or the source of the route. It doesn't affect the
+	// routing. The key "priority" is reserved.
+	// E.g. route1 [owner="team-a", "example.org/ticket"="ABC-1"]: Path("/x") -> ...
+	Metadata map[string]string
+
 	// Name is deprecated and not used.
 	Name string
 
@@ -408,6 +430,8 @@ func (r *Route) Copy() *Route {
 		}
 	}
 
+	c.Metadata = copyMetadata(r.Metadata)
+
 	if len(r.LBEndpoints) > 0 {
 		c.LBEndpoints = make([]string, len(r.LBEndpoints))
 		copy(c.LBEndpoints, r.LBEndpoints)
@@ -585,11 +609,54 @@ func newRouteDefinition(r *parsedRoute) (*Route, error) {
 		rd.BackendType = NetworkBackend
 	}
 
+	if err := applyAttributes(rd, r.attributes); err != nil {
+		return nil, err
+	}
+
 	err := applyPredicates(rd, r)
 
 	return rd, err
 }
 
+const priorityAttribute = "priority"
+
+// applies the route attributes of the form [name=value] to the route
+// definition. The attributes other than the priority are stored as
+// metadata, and their value needs to be a string. The priority is
+// reserved, it cannot be used as a metadata key.
+func applyAttributes(rd *Route, attrs []*routeAttribute) error {
+	set := make(map[string]bool)
+	for _, a := range attrs {
+		if set[a.name] {
+			return fmt.Errorf("duplicate route attribute in %s: %s", rd.Id, a.name)
+		}
+
+		set[a.name] = true
+		switch {
+		case a.name == priorityAttribute:
+			p, ok := a.value.(float64)
+			if !ok || p != math.Trunc(p) {
+				return fmt.Errorf("invalid route priority in %s: %v, expected an integer", rd.Id, a.value)
+			}
+
+			rd.Priority = int(p)
+		default:
+			v, ok := a.value.(string)
+			if !ok {
+				return fmt.Errorf("invalid route metadata in %s: %s=%v, expected a string", rd.Id, a.name, a.value)
+			}
+
+			if rd.Metadata == nil {
+				rd.Metadata = make(map[string]string)
+			}
+
+			rd.Metadata[a.name] = v
+		}
+	}
+
+	return nil
+}
+
 // executes the parser.
 func parse(code string) ([]*parsedRoute, error) {
 	l := newLexer(code)
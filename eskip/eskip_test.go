@@ -364,6 +364,12 @@ func TestRouteJSON(t *testing.T) {
 	}, {
 		&Route{Method: "GET", BackendType: DynamicBackend},
 		`{"id":"","backend":"<dynamic>","predicates":[{"name":"Method","args":["GET"]}],"filters":[]}` + "\n",
+	}, {
+		&Route{Id: "foo", BackendType: ShuntBackend, Priority: 100},
+		`{"id":"foo","backend":"<shunt>","predicates":[],"filters":[],"priority":100}` + "\n",
+	}, {
+		&Route{Id: "foo", BackendType: ShuntBackend, Metadata: map[string]string{"owner": "team-a"}},
+		`{"id":"foo","backend":"<shunt>","predicates":[],"filters":[],"metadata":{"owner":"team-a"}}` + "\n",
 	}, {
 		&Route{
 			Method:      "PUT",
@@ -818,6 +824,25 @@ func TestClonePreProcessor(t *testing.T) {
 
 }
 
+func TestPreProcessorsKeepMetadata(t *testing.T) {
+	routes, err := Parse(`r1 [owner="team-a"]: Source("1.2.3.4/26") -> status(201) -> <shunt>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []interface{ Do([]*Route) []*Route }{
+		NewEditor(regexp.MustCompile("Source[(](.*)[)]"), "ClientIP($1)"),
+		NewClone(regexp.MustCompile("Source[(](.*)[)]"), "ClientIP($1)"),
+		&DefaultFilters{Append: []*Filter{{Name: "setPath", Args: []interface{}{"/"}}}},
+	} {
+		for _, r := range p.Do([]*Route{Copy(routes[0])}) {
+			if r.Metadata["owner"] != "team-a" {
+				t.Errorf("failed to keep the metadata of route %s with %T", r.Id, p)
+			}
+		}
+	}
+}
+
 func TestPredicateString(t *testing.T) {
 	for _, tt := range []struct {
 		name      string
@@ -0,0 +1,278 @@
+package eskip
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// the indentation of the filters and the backend in the formatted
+// routes, the same as the default of the pretty printing
+const formatIndent = "  "
+
+type comment struct {
+	text        string
+	blankBefore bool
+}
+
+// the part of a route printed in a single line: the route id with the
+// attributes and the predicates, a filter, or the backend. The comments
+// in their own line are attached to the following segment, the comments
+// at the end of a line to the segment on the same line.
+type segment struct {
+	tokens   []sourceToken
+	leading  []*comment
+	trailing []*comment
+}
+
+type routeNode struct {
+	leading     []*comment
+	blankBefore bool
+	definition  bool
+	segments    []*segment
+}
+
+// the concrete syntax tree of an eskip document
+type document struct {
+	routes   []*routeNode
+	trailing []*comment
+}
+
+// executes the parser in the concrete syntax tree mode. The routes are
+// validated the same way as in Parse.
+func parseCST(code string) (*eskipLex, error) {
+	l := newCSTLexer(code)
+	eskipParse(l)
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	for _, r := range l.routes {
+		if _, err := newRouteDefinition(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+func blankLineBetween(code string, start, end int) bool {
+	return strings.Count(code[start:end], "\n") > 1
+}
+
+func startsLine(code string, pos int) bool {
+	lineStart := strings.LastIndexByte(code[:pos], '\n') + 1
+	return strings.TrimSpace(code[lineStart:pos]) == ""
+}
+
+func lastSegment(r *routeNode) *segment {
+	return r.segments[len(r.segments)-1]
+}
+
+func newDocument(code string, l *eskipLex) *document {
+	var (
+		d        document
+		current  *routeNode
+		last     *routeNode
+		pending  []*comment
+		previous int
+	)
+
+	tokens, comments := l.tokens, l.comments
+	for len(tokens) > 0 || len(comments) > 0 {
+		if len(comments) > 0 && (len(tokens) == 0 || comments[0].start < tokens[0].start) {
+			c := comments[0]
+			comments = comments[1:]
+
+			cn := &comment{
+				text:        strings.TrimRightFunc(c.text, unicode.IsSpace),
+				blankBefore: blankLineBetween(code, previous, c.start),
+			}
+
+			previous = c.end
+			switch {
+			case startsLine(code, c.start):
+				pending = append(pending, cn)
+			case current != nil:
+				s := lastSegment(current)
+				s.trailing = append(s.trailing, cn)
+			case last != nil:
+				s := lastSegment(last)
+				s.trailing = append(s.trailing, cn)
+			default:
+				pending = append(pending, cn)
+			}
+
+			continue
+		}
+
+		t := tokens[0]
+		tokens = tokens[1:]
+
+		if t.id == semicolon {
+			if current != nil {
+				s := lastSegment(current)
+				s.trailing = append(s.trailing, pending...)
+				pending = nil
+				last, current = current, nil
+			}
+
+			previous = t.end
+			continue
+		}
+
+		if current == nil {
+			current = &routeNode{
+				leading:     pending,
+				blankBefore: blankLineBetween(code, previous, t.start),
+				segments:    []*segment{{}},
+			}
+
+			pending = nil
+			d.routes = append(d.routes, current)
+		}
+
+		if t.id == arrow {
+			current.segments = append(current.segments, &segment{})
+		}
+
+		s := lastSegment(current)
+		s.leading = append(s.leading, pending...)
+		pending = nil
+		s.tokens = append(s.tokens, t)
+		previous = t.end
+	}
+
+	for i, r := range d.routes {
+		r.definition = l.routes[i].id != ""
+	}
+
+	d.trailing = pending
+	return &d
+}
+
+// the formatted routes contain no whitespace inside the parentheses and
+// the brackets, and before the commas
+func tokenSeparator(previous, next sourceToken) string {
+	switch next.id {
+	case closeparen, comma, closebracket, colon, closearrow, equals, openparen:
+		return ""
+	}
+
+	switch previous.id {
+	case openparen, openbracket, openarrow, equals:
+		return ""
+	}
+
+	return " "
+}
+
+func (s *segment) String() string {
+	var b bytes.Buffer
+	for i, t := range s.tokens {
+		if i > 0 {
+			b.WriteString(tokenSeparator(s.tokens[i-1], t))
+		}
+
+		b.WriteString(t.text)
+	}
+
+	return b.String()
+}
+
+type formatter struct {
+	buf   bytes.Buffer
+	empty bool
+}
+
+// prints a line, preceded by an empty line when requested, except for
+// the beginning of the document
+func (f *formatter) line(blank bool, indent, s string) {
+	if blank && !f.empty {
+		f.buf.WriteString("\n")
+	}
+
+	f.buf.WriteString(indent)
+	f.buf.WriteString(s)
+	f.buf.WriteString("\n")
+	f.empty = false
+}
+
+func (f *formatter) comments(c []*comment, indent string) {
+	for _, ci := range c {
+		f.line(ci.blankBefore, indent, ci.text)
+	}
+}
+
+func (f *formatter) route(r *routeNode) {
+	f.comments(r.leading, "")
+
+	blank := r.blankBefore
+	for i, s := range r.segments {
+		indent, trailingIndent := "", formatIndent
+		if i > 0 {
+			indent = formatIndent
+		}
+
+		if i == len(r.segments)-1 {
+			trailingIndent = ""
+		}
+
+		for _, c := range s.leading {
+			f.line(blank, indent, c.text)
+			blank = false
+		}
+
+		line := s.String()
+		if i == len(r.segments)-1 && r.definition {
+			line += ";"
+		}
+
+		var trailing []*comment
+		if len(s.trailing) > 0 {
+			line += " " + s.trailing[0].text
+			trailing = s.trailing[1:]
+		}
+
+		f.line(blank, indent, line)
+		blank = false
+
+		// the further comments at the end of the lines of a segment are
+		// printed in their own lines, where parsing them again attaches
+		// them to the same position
+		for _, c := range trailing {
+			f.line(false, trailingIndent, c.text)
+		}
+	}
+}
+
+func (d *document) format() string {
+	f := &formatter{empty: true}
+	for _, r := range d.routes {
+		f.route(r)
+	}
+
+	f.comments(d.trailing, "")
+	return f.buf.String()
+}
+
+// Format formats an eskip document in a canonical way, keeping the
+// comments, the order of the routes and of their predicates and filters,
+// and the original form of the arguments. Every route definition starts
+// in a new line, with the predicates in the same line as the route id,
+// and every filter and the backend in a separate, indented line. The
+// comments at the end of a line stay at the end of the same part of the
+// route, and the comments in their own lines are printed before the
+// route, filter or backend that follows them. Multiple empty lines are
+// merged, and the empty lines inside the routes are removed.
+//
+// Formatting the same document again doesn't change it. It fails for
+// the same documents as Parse.
+func Format(code string) (string, error) {
+	l, err := parseCST(code)
+	if err != nil {
+		return "", err
+	}
+
+	return newDocument(code, l).format(), nil
+}
@@ -0,0 +1,136 @@
+package eskip
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		code     string
+		expected string
+	}{{
+		title: "empty",
+	}, {
+		title:    "only comments",
+		code:     "// foo\n\n\n// bar   \n",
+		expected: "// foo\n\n// bar\n",
+	}, {
+		title:    "expression",
+		code:     `*->  <shunt>`,
+		expected: "*\n  -> <shunt>\n",
+	}, {
+		title: "definitions",
+		code: `foo:Path("/foo")&&Method( "GET" )->setPath("/")->"https://foo.example.org";bar: * -> <shunt>
+		`,
+		expected: `foo: Path("/foo") && Method("GET")
+  -> setPath("/")
+  -> "https://foo.example.org";
+bar: *
+  -> <shunt>;
+`,
+	}, {
+		title: "keeps the predicate order and the form of the arguments",
+		code: `r: Method("GET") && Host(/^example[.]org$/) && Header("X-Foo", ` + "`bar`" + `) && Traffic(.30)
+			-> modPath(/\/foo/, "") -> <shunt>`,
+		expected: `r: Method("GET") && Host(/^example[.]org$/) && Header("X-Foo", ` + "`bar`" + `) && Traffic(.30)
+  -> modPath(/\/foo/, "")
+  -> <shunt>;
+`,
+	}, {
+		title: "attributes and load balanced backend",
+		code:  `r [ priority = 3 , "example.org/owner"="foo" ] : * -> < roundRobin , "http://10.0.0.1" , "http://10.0.0.2" >;`,
+		expected: `r [priority=3, "example.org/owner"="foo"]: *
+  -> <roundRobin, "http://10.0.0.1", "http://10.0.0.2">;
+`,
+	}, {
+		title: "comments and empty lines",
+		code: `// header
+
+
+// foo route
+foo: Path("/foo") // matches foo
+	// sets the path
+	-> setPath("/") -> <shunt>; // done
+
+bar: * -> <shunt>;
+// end
+`,
+		expected: `// header
+
+// foo route
+foo: Path("/foo") // matches foo
+  // sets the path
+  -> setPath("/")
+  -> <shunt>; // done
+
+bar: *
+  -> <shunt>;
+// end
+`,
+	}, {
+		title: "comments in multiline predicates",
+		code: `r: Path("/foo") && // path
+			Method("GET") // method
+
+			-> <shunt>`,
+		expected: `r: Path("/foo") && Method("GET") // path
+  // method
+  -> <shunt>;
+`,
+	}, {
+		title: "comment before the semicolon",
+		code: `r: * -> <shunt>
+		// backend
+		;`,
+		expected: `r: *
+  -> <shunt>; // backend
+`,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			f, err := Format(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if f != test.expected {
+				t.Fatalf("invalid format, expected:\n%s\ngot:\n%s", test.expected, f)
+			}
+
+			again, err := Format(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if again != f {
+				t.Errorf("format not stable, expected:\n%s\ngot:\n%s", f, again)
+			}
+
+			original, err := Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			formatted, err := Parse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !EqLists(original, formatted) {
+				t.Errorf("routes changed, expected: %s, got: %s", String(original...), String(formatted...))
+			}
+		})
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	for _, code := range []string{
+		`r: * -> `,
+		`r: * -> <"http://10.0.0.1", "https://10.0.0.2">`,
+		`r [priority="high"]: * -> <shunt>`,
+	} {
+		if _, err := Format(code); err == nil {
+			t.Errorf("failed to fail: %s", code)
+		}
+	}
+}
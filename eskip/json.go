@@ -92,15 +92,19 @@ func (r *Route) MarshalJSON() ([]byte, error) {
 	e.SetEscapeHTML(false)
 
 	if err := e.Encode(&struct {
-		Id         string       `json:"id"`
-		Backend    string       `json:"backend"`
-		Predicates []*Predicate `json:"predicates"`
-		Filters    []*Filter    `json:"filters"`
+		Id         string            `json:"id"`
+		Backend    string            `json:"backend"`
+		Predicates []*Predicate      `json:"predicates"`
+		Filters    []*Filter         `json:"filters"`
+		Priority   int               `json:"priority,omitempty"`
+		Metadata   map[string]string `json:"metadata,omitempty"`
 	}{
 		Id:         r.Id,
 		Backend:    backend,
 		Predicates: marshalJsonPredicates(r),
 		Filters:    filters,
+		Priority:   r.Priority,
+		Metadata:   r.Metadata,
 	}); err != nil {
 		return nil, err
 	}
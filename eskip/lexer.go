@@ -22,6 +22,14 @@ type scannerFunc func(string) (token, string, error)
 
 func (sf scannerFunc) scan(code string) (token, string, error) { return sf(code) }
 
+// a token or a comment, recorded with its position and its original
+// text in the concrete syntax tree mode of the lexer
+type sourceToken struct {
+	id         int
+	text       string
+	start, end int
+}
+
 type eskipLex struct {
 	code          string
 	lastToken     *token
@@ -29,6 +37,12 @@ type eskipLex struct {
 	err           error
 	initialLength int
 	routes        []*parsedRoute
+
+	// when set, the lexer records the tokens and the comments
+	cst      bool
+	original string
+	tokens   []sourceToken
+	comments []sourceToken
 }
 
 type fixedScanner string
@@ -63,6 +77,9 @@ var fixedTokens = []fixedScanner{
 	"<dynamic>",
 	"<",
 	">",
+	"[",
+	"]",
+	"=",
 }
 
 var fixedTokenIDs = map[fixedScanner]int{
@@ -79,6 +96,9 @@ var fixedTokenIDs = map[fixedScanner]int{
 	"<dynamic>":  dynamic,
 	"<":          openarrow,
 	">":          closearrow,
+	"[":          openbracket,
+	"]":          closebracket,
+	"=":          equals,
 }
 
 func (t token) String() string { return t.val }
@@ -101,6 +121,15 @@ func newLexer(code string) *eskipLex {
 		initialLength: len(code)}
 }
 
+// creates a lexer in the concrete syntax tree mode, that keeps the
+// original text and the position of the tokens and the comments
+func newCSTLexer(code string) *eskipLex {
+	l := newLexer(code)
+	l.cst = true
+	l.original = code
+	return l
+}
+
 func isWhitespace(c byte) bool  { return unicode.IsSpace(rune(c)) }
 func isNewline(c byte) bool     { return c == newlineChar }
 func isUnderscore(c byte) bool  { return c == underscore }
@@ -288,6 +317,12 @@ func scanNumber(code string) (t token, rest string, err error) {
 	return
 }
 
+func scanNegativeNumber(code string) (t token, rest string, err error) {
+	t, rest, err = scanNumber(code[1:])
+	t.val = "-" + t.val
+	return
+}
+
 func scanSymbol(code string) (t token, rest string, err error) {
 	b, rest := scanWhile(code, isSymbolChar)
 	t.id = symbol
@@ -320,6 +355,10 @@ func selectVaryingScanner(code string) scanner {
 		sf = scanNumber
 	}
 
+	if code[0] == '-' && len(code) > 1 && isNumberChar(code[1]) {
+		sf = scanNegativeNumber
+	}
+
 	if isAlpha(code[0]) || isUnderscore(code[0]) {
 		sf = scanSymbol
 	}
@@ -339,6 +378,21 @@ func selectScanner(code string) scanner {
 	return selectVaryingScanner(code)
 }
 
+func (l *eskipLex) position() int {
+	return l.initialLength - len(l.code)
+}
+
+// records the source of the last scanned token or comment
+func (l *eskipLex) record(id int, start int, comment bool) {
+	end := l.position()
+	st := sourceToken{id: id, start: start, end: end, text: l.original[start:end]}
+	if comment {
+		l.comments = append(l.comments, st)
+	} else {
+		l.tokens = append(l.tokens, st)
+	}
+}
+
 func (l *eskipLex) next() (t token, err error) {
 	l.code = scanWhitespace(l.code)
 	if len(l.code) == 0 {
@@ -352,13 +406,21 @@ func (l *eskipLex) next() (t token, err error) {
 		return
 	}
 
+	start := l.position()
 	t, l.code, err = s.scan(l.code)
 	if err == void {
+		if l.cst {
+			l.record(0, start, true)
+		}
+
 		return l.next()
 	}
 
 	if err == nil {
 		l.lastToken = &t
+		if l.cst {
+			l.record(t.id, start, false)
+		}
 	}
 
 	return
@@ -41,6 +41,8 @@ type eskipSymType struct {
 	stringvals  []string
 	lbAlgorithm string
 	lbEndpoints []string
+	attribute   *routeAttribute
+	attributes  []*routeAttribute
 }
 
 const and = 57346
@@ -60,6 +62,9 @@ const stringliteral = 57359
 const symbol = 57360
 const openarrow = 57361
 const closearrow = 57362
+const openbracket = 57363
+const closebracket = 57364
+const equals = 57365
 
 var eskipToknames = [...]string{
 	"$end",
@@ -82,6 +87,9 @@ var eskipToknames = [...]string{
 	"symbol",
 	"openarrow",
 	"closearrow",
+	"openbracket",
+	"closebracket",
+	"equals",
 }
 
 var eskipStatenames = [...]string{}
@@ -90,7 +98,7 @@ const eskipEofCode = 1
 const eskipErrCode = 2
 const eskipInitialStackSize = 16
 
-//line parser.y:287
+//line parser.y:318
 
 //line yacctab:1
 var eskipExca = [...]int{
@@ -101,62 +109,69 @@ var eskipExca = [...]int{
 
 const eskipPrivate = 57344
 
-const eskipLast = 62
+const eskipLast = 76
 
 var eskipAct = [...]int{
-	34, 40, 32, 31, 24, 17, 20, 21, 22, 25,
-	27, 26, 19, 48, 36, 9, 37, 25, 41, 9,
-	16, 25, 25, 3, 10, 7, 14, 42, 29, 43,
-	4, 55, 8, 45, 44, 49, 45, 30, 28, 19,
-	50, 15, 13, 47, 46, 38, 23, 51, 52, 39,
-	53, 42, 54, 12, 35, 11, 33, 18, 5, 6,
-	2, 1,
+	39, 3, 45, 37, 36, 25, 33, 18, 52, 51,
+	50, 13, 20, 57, 9, 31, 21, 22, 23, 26,
+	28, 27, 9, 49, 14, 35, 34, 30, 47, 41,
+	17, 42, 26, 46, 10, 8, 26, 26, 15, 7,
+	48, 58, 59, 4, 20, 68, 53, 54, 54, 56,
+	61, 55, 29, 60, 16, 63, 64, 62, 65, 66,
+	47, 43, 67, 69, 12, 24, 11, 44, 40, 38,
+	19, 5, 32, 6, 2, 1,
 }
 
 var eskipPact = [...]int{
-	14, -1000, 11, -1000, -1000, 49, 34, -1000, 15, -1000,
-	2, -8, 10, 10, 4, -1000, -1000, -1000, 39, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 0, 18, -1000, 15,
-	-1000, 27, -1000, -1000, -1000, -1000, -1000, -1000, -8, -7,
-	26, 31, -1000, 4, -1000, 4, -1000, -1000, -1000, 5,
-	5, 24, -1000, -1000, 26, -1000,
+	17, -1000, 21, -1000, -1000, 60, 3, -1000, 27, -1000,
+	12, 2, 9, 9, 8, 19, -1000, -1000, -1000, 55,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 15, 29, -1000,
+	27, -1000, 1, -1000, -14, -15, 39, -1000, -1000, -1000,
+	-1000, -1000, -1000, 2, -7, 32, 33, -1000, 19, 42,
+	8, 19, 19, -1000, 19, -1000, -1000, -1000, 20, 20,
+	38, 9, -1000, -1000, -1000, -1000, -1000, 32, -1000, -1000,
 }
 
 var eskipPgo = [...]int{
-	0, 61, 60, 23, 30, 59, 58, 5, 57, 25,
-	3, 4, 2, 56, 0, 54, 1, 49, 46,
+	0, 75, 74, 1, 43, 73, 72, 6, 3, 71,
+	7, 70, 39, 4, 5, 69, 0, 68, 2, 67,
+	65,
 }
 
 var eskipR1 = [...]int{
-	0, 1, 1, 2, 2, 2, 2, 4, 5, 3,
-	3, 6, 6, 9, 9, 8, 8, 11, 10, 10,
-	10, 12, 12, 12, 16, 16, 17, 17, 18, 7,
-	7, 7, 7, 7, 13, 14, 15,
+	0, 1, 1, 2, 2, 2, 2, 4, 4, 6,
+	6, 7, 7, 5, 3, 3, 9, 9, 12, 12,
+	11, 11, 14, 13, 13, 13, 8, 8, 8, 18,
+	18, 19, 19, 20, 10, 10, 10, 10, 10, 15,
+	16, 17,
 }
 
 var eskipR2 = [...]int{
-	0, 1, 1, 0, 1, 3, 2, 3, 1, 3,
-	5, 1, 3, 1, 4, 1, 3, 4, 0, 1,
-	3, 1, 1, 1, 1, 3, 1, 3, 3, 1,
-	1, 1, 1, 1, 1, 1, 1,
+	0, 1, 1, 0, 1, 3, 2, 3, 6, 1,
+	3, 3, 3, 1, 3, 5, 1, 3, 1, 4,
+	1, 3, 4, 0, 1, 3, 1, 1, 1, 1,
+	3, 1, 3, 3, 1, 1, 1, 1, 1, 1,
+	1, 1,
 }
 
 var eskipChk = [...]int{
-	-1000, -1, -2, -3, -4, -6, -5, -9, 18, 5,
-	13, 6, 4, 8, 11, -4, 18, -7, -8, -14,
-	14, 15, 16, -18, -11, 17, 19, 18, -9, 18,
-	-3, -10, -12, -13, -14, -15, 10, 12, 6, -17,
-	-16, 18, -14, 11, 7, 9, -7, -11, 20, 9,
-	9, -10, -12, -14, -16, 7,
+	-1000, -1, -2, -3, -4, -9, -5, -12, 18, 5,
+	13, 6, 4, 8, 21, 11, -4, 18, -10, -11,
+	-16, 14, 15, 16, -20, -14, 17, 19, 18, -12,
+	18, -3, -6, -7, 18, 17, -13, -8, -15, -16,
+	-17, 10, 12, 6, -19, -18, 18, -16, 11, 22,
+	9, 23, 23, 7, 9, -10, -14, 20, 9, 9,
+	-13, 8, -7, -8, -8, -8, -16, -18, 7, -3,
 }
 
 var eskipDef = [...]int{
-	3, -2, 1, 2, 4, 0, 0, 11, 8, 13,
-	6, 0, 0, 0, 18, 5, 8, 9, 0, 29,
-	30, 31, 32, 33, 15, 35, 0, 0, 12, 0,
-	7, 0, 19, 21, 22, 23, 34, 36, 0, 0,
-	26, 0, 24, 18, 14, 0, 10, 16, 28, 0,
-	0, 0, 20, 25, 27, 17,
+	3, -2, 1, 2, 4, 0, 0, 16, 13, 18,
+	6, 0, 0, 0, 0, 23, 5, 13, 14, 0,
+	34, 35, 36, 37, 38, 20, 40, 0, 0, 17,
+	0, 7, 0, 9, 0, 0, 0, 24, 26, 27,
+	28, 39, 41, 0, 0, 31, 0, 29, 23, 0,
+	0, 0, 0, 19, 0, 15, 21, 33, 0, 0,
+	0, 0, 10, 11, 12, 25, 30, 32, 22, 8,
 }
 
 var eskipTok1 = [...]int{
@@ -165,7 +180,8 @@ var eskipTok1 = [...]int{
 
 var eskipTok2 = [...]int{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
-	12, 13, 14, 15, 16, 17, 18, 19, 20,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
+	22, 23,
 }
 
 var eskipTok3 = [...]int{
@@ -511,54 +527,88 @@ eskipdefault:
 
 	case 1:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:75
+//line parser.y:80
 		{
 			eskipVAL.routes = eskipDollar[1].routes
 			eskiplex.(*eskipLex).routes = eskipVAL.routes
 		}
 	case 2:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:80
+//line parser.y:85
 		{
 			eskipVAL.routes = []*parsedRoute{eskipDollar[1].route}
 			eskiplex.(*eskipLex).routes = eskipVAL.routes
 		}
 	case 4:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:87
+//line parser.y:92
 		{
 			eskipVAL.routes = []*parsedRoute{eskipDollar[1].route}
 		}
 	case 5:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:91
+//line parser.y:96
 		{
 			eskipVAL.routes = eskipDollar[1].routes
 			eskipVAL.routes = append(eskipVAL.routes, eskipDollar[3].route)
 		}
 	case 6:
 		eskipDollar = eskipS[eskippt-2 : eskippt+1]
-//line parser.y:96
+//line parser.y:101
 		{
 			eskipVAL.routes = eskipDollar[1].routes
 		}
 	case 7:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:101
+//line parser.y:106
 		{
 			eskipVAL.route = eskipDollar[3].route
 			eskipVAL.route.id = eskipDollar[1].token
 		}
 	case 8:
+		eskipDollar = eskipS[eskippt-6 : eskippt+1]
+//line parser.y:111
+		{
+			eskipVAL.route = eskipDollar[6].route
+			eskipVAL.route.id = eskipDollar[1].token
+			eskipVAL.route.attributes = eskipDollar[3].attributes
+			eskipDollar[3].attributes = nil
+		}
+	case 9:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:107
+//line parser.y:119
+		{
+			eskipVAL.attributes = []*routeAttribute{eskipDollar[1].attribute}
+		}
+	case 10:
+		eskipDollar = eskipS[eskippt-3 : eskippt+1]
+//line parser.y:123
+		{
+			eskipVAL.attributes = eskipDollar[1].attributes
+			eskipVAL.attributes = append(eskipVAL.attributes, eskipDollar[3].attribute)
+		}
+	case 11:
+		eskipDollar = eskipS[eskippt-3 : eskippt+1]
+//line parser.y:129
+		{
+			eskipVAL.attribute = &routeAttribute{name: eskipDollar[1].token, value: eskipDollar[3].arg}
+		}
+	case 12:
+		eskipDollar = eskipS[eskippt-3 : eskippt+1]
+//line parser.y:133
+		{
+			eskipVAL.attribute = &routeAttribute{name: eskipDollar[1].token, value: eskipDollar[3].arg}
+		}
+	case 13:
+		eskipDollar = eskipS[eskippt-1 : eskippt+1]
+//line parser.y:138
 		{
 			eskipVAL.token = eskipDollar[1].token
 			eskiplex.(*eskipLex).lastRouteID = eskipDollar[1].token
 		}
-	case 9:
+	case 14:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:113
+//line parser.y:144
 		{
 			eskipVAL.route = &parsedRoute{
 				matchers:    eskipDollar[1].matchers,
@@ -573,9 +623,9 @@ eskipdefault:
 			eskipDollar[1].matchers = nil
 			eskipDollar[3].lbEndpoints = nil
 		}
-	case 10:
+	case 15:
 		eskipDollar = eskipS[eskippt-5 : eskippt+1]
-//line parser.y:128
+//line parser.y:159
 		{
 			eskipVAL.route = &parsedRoute{
 				matchers:    eskipDollar[1].matchers,
@@ -592,121 +642,121 @@ eskipdefault:
 			eskipDollar[3].filters = nil
 			eskipDollar[5].lbEndpoints = nil
 		}
-	case 11:
+	case 16:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:146
+//line parser.y:177
 		{
 			eskipVAL.matchers = []*matcher{eskipDollar[1].matcher}
 		}
-	case 12:
+	case 17:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:150
+//line parser.y:181
 		{
 			eskipVAL.matchers = eskipDollar[1].matchers
 			eskipVAL.matchers = append(eskipVAL.matchers, eskipDollar[3].matcher)
 		}
-	case 13:
+	case 18:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:156
+//line parser.y:187
 		{
 			eskipVAL.matcher = &matcher{"*", nil}
 		}
-	case 14:
+	case 19:
 		eskipDollar = eskipS[eskippt-4 : eskippt+1]
-//line parser.y:160
+//line parser.y:191
 		{
 			eskipVAL.matcher = &matcher{eskipDollar[1].token, eskipDollar[3].args}
 			eskipDollar[3].args = nil
 		}
-	case 15:
+	case 20:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:166
+//line parser.y:197
 		{
 			eskipVAL.filters = []*Filter{eskipDollar[1].filter}
 		}
-	case 16:
+	case 21:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:170
+//line parser.y:201
 		{
 			eskipVAL.filters = eskipDollar[1].filters
 			eskipVAL.filters = append(eskipVAL.filters, eskipDollar[3].filter)
 		}
-	case 17:
+	case 22:
 		eskipDollar = eskipS[eskippt-4 : eskippt+1]
-//line parser.y:176
+//line parser.y:207
 		{
 			eskipVAL.filter = &Filter{
 				Name: eskipDollar[1].token,
 				Args: eskipDollar[3].args}
 			eskipDollar[3].args = nil
 		}
-	case 19:
+	case 24:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:185
+//line parser.y:216
 		{
 			eskipVAL.args = []interface{}{eskipDollar[1].arg}
 		}
-	case 20:
+	case 25:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:189
+//line parser.y:220
 		{
 			eskipVAL.args = eskipDollar[1].args
 			eskipVAL.args = append(eskipVAL.args, eskipDollar[3].arg)
 		}
-	case 21:
+	case 26:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:195
+//line parser.y:226
 		{
 			eskipVAL.arg = eskipDollar[1].numval
 		}
-	case 22:
+	case 27:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:199
+//line parser.y:230
 		{
 			eskipVAL.arg = eskipDollar[1].stringval
 		}
-	case 23:
+	case 28:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:203
+//line parser.y:234
 		{
 			eskipVAL.arg = eskipDollar[1].regexpval
 		}
-	case 24:
+	case 29:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:208
+//line parser.y:239
 		{
 			eskipVAL.stringvals = []string{eskipDollar[1].stringval}
 		}
-	case 25:
+	case 30:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:212
+//line parser.y:243
 		{
 			eskipVAL.stringvals = eskipDollar[1].stringvals
 			eskipVAL.stringvals = append(eskipVAL.stringvals, eskipDollar[3].stringval)
 		}
-	case 26:
+	case 31:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:218
+//line parser.y:249
 		{
 			eskipVAL.lbEndpoints = eskipDollar[1].stringvals
 		}
-	case 27:
+	case 32:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:222
+//line parser.y:253
 		{
 			eskipVAL.lbAlgorithm = eskipDollar[1].token
 			eskipVAL.lbEndpoints = eskipDollar[3].stringvals
 		}
-	case 28:
+	case 33:
 		eskipDollar = eskipS[eskippt-3 : eskippt+1]
-//line parser.y:228
+//line parser.y:259
 		{
 			eskipVAL.lbAlgorithm = eskipDollar[2].lbAlgorithm
 			eskipVAL.lbEndpoints = eskipDollar[2].lbEndpoints
 		}
-	case 29:
+	case 34:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:234
+//line parser.y:265
 		{
 			eskipVAL.backend = eskipDollar[1].stringval
 			eskipVAL.shunt = false
@@ -714,36 +764,36 @@ eskipdefault:
 			eskipVAL.dynamic = false
 			eskipVAL.lbBackend = false
 		}
-	case 30:
+	case 35:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:242
+//line parser.y:273
 		{
 			eskipVAL.shunt = true
 			eskipVAL.loopback = false
 			eskipVAL.dynamic = false
 			eskipVAL.lbBackend = false
 		}
-	case 31:
+	case 36:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:249
+//line parser.y:280
 		{
 			eskipVAL.shunt = false
 			eskipVAL.loopback = true
 			eskipVAL.dynamic = false
 			eskipVAL.lbBackend = false
 		}
-	case 32:
+	case 37:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:256
+//line parser.y:287
 		{
 			eskipVAL.shunt = false
 			eskipVAL.loopback = false
 			eskipVAL.dynamic = true
 			eskipVAL.lbBackend = false
 		}
-	case 33:
+	case 38:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:263
+//line parser.y:294
 		{
 			eskipVAL.shunt = false
 			eskipVAL.loopback = false
@@ -752,21 +802,21 @@ eskipdefault:
 			eskipVAL.lbAlgorithm = eskipDollar[1].lbAlgorithm
 			eskipVAL.lbEndpoints = eskipDollar[1].lbEndpoints
 		}
-	case 34:
+	case 39:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:273
+//line parser.y:304
 		{
 			eskipVAL.numval = convertNumber(eskipDollar[1].token)
 		}
-	case 35:
+	case 40:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:278
+//line parser.y:309
 		{
 			eskipVAL.stringval = eskipDollar[1].token
 		}
-	case 36:
+	case 41:
 		eskipDollar = eskipS[eskippt-1 : eskippt+1]
-//line parser.y:283
+//line parser.y:314
 		{
 			eskipVAL.regexpval = eskipDollar[1].token
 		}
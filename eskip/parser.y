@@ -49,6 +49,8 @@ func convertNumber(s string) float64 {
 	stringvals []string
 	lbAlgorithm string
 	lbEndpoints []string
+	attribute *routeAttribute
+	attributes []*routeAttribute
 }
 
 %token and
@@ -68,6 +70,9 @@ func convertNumber(s string) float64 {
 %token symbol
 %token openarrow
 %token closearrow
+%token openbracket
+%token closebracket
+%token equals
 
 %%
 
@@ -102,6 +107,32 @@ routedef:
 		$$.route = $3.route
 		$$.route.id = $1.token
 	}
+	|
+	routeid openbracket attributes closebracket colon route {
+		$$.route = $6.route
+		$$.route.id = $1.token
+		$$.route.attributes = $3.attributes
+		$3.attributes = nil
+	}
+
+attributes:
+	attribute {
+		$$.attributes = []*routeAttribute{$1.attribute}
+	}
+	|
+	attributes comma attribute {
+		$$.attributes = $1.attributes
+		$$.attributes = append($$.attributes, $3.attribute)
+	}
+
+attribute:
+	symbol equals arg {
+		$$.attribute = &routeAttribute{name: $1.token, value: $3.arg}
+	}
+	|
+	stringliteral equals arg {
+		$$.attribute = &routeAttribute{name: $1.token, value: $3.arg}
+	}
 
 routeid:
 	symbol {
@@ -15,6 +15,7 @@
 package eskip
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -332,3 +333,138 @@ func TestLBBackend(t *testing.T) {
 		})
 	}
 }
+
+func TestNegativeNumber(t *testing.T) {
+	routes, err := Parse(`* -> number(-3.14) -> <shunt>`)
+	if err != nil {
+		t.Fatal("failed to parse number", err)
+	}
+
+	if v := routes[0].Filters[0].Args[0]; v != -3.14 {
+		t.Error("failed to parse negative number", v)
+	}
+}
+
+func TestRoutePriority(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		code     string
+		priority int
+		fail     bool
+	}{{
+		title: "no attributes",
+		code:  `r: * -> <shunt>`,
+	}, {
+		title:    "priority",
+		code:     `r [priority=100]: * -> <shunt>`,
+		priority: 100,
+	}, {
+		title:    "priority with whitespace",
+		code:     `r [ priority = 100 ] : * -> <shunt>`,
+		priority: 100,
+	}, {
+		title:    "negative priority",
+		code:     `r [priority=-1]: * -> <shunt>`,
+		priority: -1,
+	}, {
+		title: "empty attributes",
+		code:  `r []: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "fractional priority",
+		code:  `r [priority=1.5]: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "string priority",
+		code:  `r [priority="100"]: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "duplicate priority",
+		code:  `r [priority=1, priority=2]: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "unknown attribute",
+		code:  `r [foo=1]: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "attributes without route id",
+		code:  `[priority=100] * -> <shunt>`,
+		fail:  true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			routes, err := Parse(test.code)
+			if test.fail {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if routes[0].Priority != test.priority {
+				t.Errorf("invalid priority, expected: %d, got: %d", test.priority, routes[0].Priority)
+			}
+		})
+	}
+}
+
+func TestRouteMetadata(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		code     string
+		priority int
+		metadata map[string]string
+		fail     bool
+	}{{
+		title:    "no metadata",
+		code:     `r [priority=1]: * -> <shunt>`,
+		priority: 1,
+	}, {
+		title:    "symbol keys",
+		code:     `r [owner="team-a", ticket="ABC-1"]: * -> <shunt>`,
+		metadata: map[string]string{"owner": "team-a", "ticket": "ABC-1"},
+	}, {
+		title:    "quoted keys",
+		code:     `r ["example.org/owner"="team-a"]: * -> <shunt>`,
+		metadata: map[string]string{"example.org/owner": "team-a"},
+	}, {
+		title: "priority is reserved",
+		code:  `r ["priority"="high"]: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "non-string value",
+		code:  `r [owner=1]: * -> <shunt>`,
+		fail:  true,
+	}, {
+		title: "duplicate key",
+		code:  `r [owner="team-a", "owner"="team-b"]: * -> <shunt>`,
+		fail:  true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			routes, err := Parse(test.code)
+			if test.fail {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if routes[0].Priority != test.priority {
+				t.Errorf("invalid priority, expected: %d, got: %d", test.priority, routes[0].Priority)
+			}
+
+			if !reflect.DeepEqual(routes[0].Metadata, test.metadata) {
+				t.Errorf("invalid metadata, expected: %v, got: %v", test.metadata, routes[0].Metadata)
+			}
+		})
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sort"
 	"strings"
+	"unicode/utf8"
 )
 
 type PrettyPrintInfo struct {
@@ -212,8 +214,58 @@ func fprintExpression(w io.Writer, route *Route, prettyPrintInfo PrettyPrintInfo
 	fmt.Fprint(w, route.Print(prettyPrintInfo))
 }
 
+// tells whether a metadata key can be printed as a symbol. Only ASCII
+// symbols are accepted, and they cannot start with a digit.
+func isSymbol(s string) bool {
+	if s == "" || isDigit(s[0]) {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf || !isSymbolChar(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// the metadata keys that can't be printed as symbols are quoted
+func metadataKeyString(k string) string {
+	if isSymbol(k) {
+		return k
+	}
+
+	return fmt.Sprintf(`"%s"`, escape(k, `"`))
+}
+
+// serializes the route attributes, e.g. [priority=100], when set. The
+// metadata is printed in the order of the keys.
+func (r *Route) attributeString() string {
+	var attrs []string
+	if r.Priority != 0 {
+		attrs = appendFmt(attrs, "%s=%d", priorityAttribute, r.Priority)
+	}
+
+	keys := make([]string, 0, len(r.Metadata))
+	for k := range r.Metadata {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, fmt.Sprintf(`%s="%s"`, metadataKeyString(k), escape(r.Metadata[k], `"`)))
+	}
+
+	if len(attrs) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" [%s]", strings.Join(attrs, ", "))
+}
+
 func fprintDefinition(w io.Writer, route *Route, prettyPrintInfo PrettyPrintInfo) {
-	fmt.Fprintf(w, "%s: %s", route.Id, route.Print(prettyPrintInfo))
+	fmt.Fprintf(w, "%s%s: %s", route.Id, route.attributeString(), route.Print(prettyPrintInfo))
 }
 
 func fprintDefinitions(w io.Writer, routes []*Route, prettyPrintInfo PrettyPrintInfo) {
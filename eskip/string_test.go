@@ -3,6 +3,7 @@ package eskip
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -481,3 +482,54 @@ testRoute3: Path("/baz")
 		})
 	})
 }
+
+func TestPrintPriority(t *testing.T) {
+	r := &Route{Id: "route1", Priority: 100, BackendType: ShuntBackend}
+	expected := `route1 [priority=100]: * -> <shunt>;`
+	if s := String(r); s != expected {
+		t.Errorf("invalid output, expected: %s, got: %s", expected, s)
+	}
+
+	parsed, err := Parse(String(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Eq(parsed[0], r) {
+		t.Errorf("print/parse failed: %s", String(parsed...))
+	}
+
+	r.Priority = -5
+	if parsed, err := Parse(String(r)); err != nil || parsed[0].Priority != -5 {
+		t.Errorf("print/parse failed for negative priority: %v", err)
+	}
+
+	// expressions have no id, and no attributes
+	r.Id = ""
+	if s := String(r); s != "* -> <shunt>" {
+		t.Errorf("invalid output: %s", s)
+	}
+}
+
+func TestPrintMetadata(t *testing.T) {
+	r := &Route{
+		Id:          "route1",
+		Priority:    100,
+		Metadata:    map[string]string{"owner": "team-a", "example.org/ticket": `ABC-"1"`},
+		BackendType: ShuntBackend,
+	}
+
+	expected := `route1 [priority=100, "example.org/ticket"="ABC-\"1\"", owner="team-a"]: * -> <shunt>;`
+	if s := String(r); s != expected {
+		t.Errorf("invalid output, expected: %s, got: %s", expected, s)
+	}
+
+	parsed, err := Parse(String(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed[0].Priority != r.Priority || !reflect.DeepEqual(parsed[0].Metadata, r.Metadata) {
+		t.Errorf("print/parse failed: %s", String(parsed...))
+	}
+}
@@ -0,0 +1,339 @@
+package eskip
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The YAML document format of the routes. It's described by the JSON
+// schema in routes.schema.json.
+type yamlDocument struct {
+	Routes []*yamlRoute `yaml:"routes"`
+}
+
+type yamlRoute struct {
+	Id         string            `yaml:"id,omitempty"`
+	Priority   int               `yaml:"priority,omitempty"`
+	Metadata   map[string]string `yaml:"metadata,omitempty"`
+	Predicates []*yamlNameArgs   `yaml:"predicates,omitempty"`
+	Filters    []*yamlNameArgs   `yaml:"filters,omitempty"`
+	Backend    *yamlBackend      `yaml:"backend"`
+}
+
+type yamlNameArgs struct {
+	Name string        `yaml:"name"`
+	Args []interface{} `yaml:"args,omitempty"`
+}
+
+type yamlBackend struct {
+	Type      string   `yaml:"type"`
+	Address   string   `yaml:"address,omitempty"`
+	Algorithm string   `yaml:"algorithm,omitempty"`
+	Endpoints []string `yaml:"endpoints,omitempty"`
+}
+
+var (
+	errMissingBackend          = errors.New("missing backend")
+	errMissingBackendType      = errors.New("missing backend type")
+	errMissingBackendAddress   = errors.New("missing backend address")
+	errMissingBackendEndpoints = errors.New("missing backend endpoints")
+)
+
+// the YAML arguments need to be of the same types as the ones created
+// by the eskip parser: float64 for the numbers and string for the strings
+// and regular expressions
+func yamlArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	a := make([]interface{}, len(args))
+	for i, ai := range args {
+		switch v := ai.(type) {
+		case int:
+			a[i] = float64(v)
+		case int64:
+			a[i] = float64(v)
+		case uint64:
+			a[i] = float64(v)
+		case float64:
+			a[i] = v
+		case string:
+			a[i] = v
+		default:
+			return nil, fmt.Errorf("unsupported argument type: %v, expected a number or a string", ai)
+		}
+	}
+
+	return a, nil
+}
+
+// the fields of the backend need to match its type
+func applyYAMLBackend(pr *parsedRoute, b *yamlBackend) error {
+	if b == nil {
+		return errMissingBackend
+	}
+
+	if b.Type == "" {
+		return errMissingBackendType
+	}
+
+	t, err := BackendTypeFromString(b.Type)
+	if err != nil {
+		return err
+	}
+
+	if t != NetworkBackend && b.Address != "" {
+		return fmt.Errorf("address not allowed for the backend type %s", t)
+	}
+
+	if t != LBBackend && (b.Algorithm != "" || len(b.Endpoints) > 0) {
+		return fmt.Errorf("algorithm and endpoints not allowed for the backend type %s", t)
+	}
+
+	switch t {
+	case NetworkBackend:
+		if b.Address == "" {
+			return errMissingBackendAddress
+		}
+
+		pr.backend = b.Address
+	case ShuntBackend:
+		pr.shunt = true
+	case LoopBackend:
+		pr.loopback = true
+	case DynamicBackend:
+		pr.dynamic = true
+	case LBBackend:
+		if len(b.Endpoints) == 0 {
+			return errMissingBackendEndpoints
+		}
+
+		pr.lbBackend = true
+		pr.lbAlgorithm = b.Algorithm
+		pr.lbEndpoints = b.Endpoints
+	}
+
+	return nil
+}
+
+// the route ids and the predicate and filter names need to be valid
+// symbols, so that the routes can be printed in the eskip format
+func checkYAMLName(kind, name, id string) error {
+	if !isSymbol(name) {
+		return fmt.Errorf("invalid %s name in %s: %q", kind, id, name)
+	}
+
+	return nil
+}
+
+func parseYAMLRoute(r *yamlRoute) (*Route, error) {
+	if r.Id != "" {
+		if err := checkYAMLName("route", r.Id, r.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	pr := &parsedRoute{id: r.Id}
+	for _, p := range r.Predicates {
+		if err := checkYAMLName("predicate", p.Name, r.Id); err != nil {
+			return nil, err
+		}
+
+		args, err := yamlArgs(p.Args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid predicate %s in %s: %w", p.Name, r.Id, err)
+		}
+
+		pr.matchers = append(pr.matchers, &matcher{name: p.Name, args: args})
+	}
+
+	for _, f := range r.Filters {
+		if err := checkYAMLName("filter", f.Name, r.Id); err != nil {
+			return nil, err
+		}
+
+		args, err := yamlArgs(f.Args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %s in %s: %w", f.Name, r.Id, err)
+		}
+
+		pr.filters = append(pr.filters, &Filter{Name: f.Name, Args: args})
+	}
+
+	if err := applyYAMLBackend(pr, r.Backend); err != nil {
+		return nil, fmt.Errorf("invalid backend in %s: %w", r.Id, err)
+	}
+
+	if r.Priority != 0 {
+		pr.attributes = append(pr.attributes, &routeAttribute{name: priorityAttribute, value: float64(r.Priority)})
+	}
+
+	for _, k := range sortedKeys(r.Metadata) {
+		pr.attributes = append(pr.attributes, &routeAttribute{name: k, value: r.Metadata[k]})
+	}
+
+	return newRouteDefinition(pr)
+}
+
+// ParseYAML parses a YAML document of route definitions. The document
+// contains the routes in a list under the top level key 'routes'. The
+// routes are validated the same way as the ones parsed from the eskip
+// text format, and the unknown fields are rejected.
+//
+// Example:
+//
+//	routes:
+//	- id: api
+//	  predicates:
+//	  - name: Path
+//	    args: ["/api"]
+//	  filters:
+//	  - name: setRequestHeader
+//	    args: ["X-Foo", "bar"]
+//	  backend:
+//	    type: lb
+//	    algorithm: roundRobin
+//	    endpoints: ["http://10.2.0.1:8080", "http://10.2.0.2:8080"]
+//
+// The JSON schema of the document format is published in
+// eskip/routes.schema.json.
+func ParseYAML(data []byte) ([]*Route, error) {
+	var doc yamlDocument
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, err
+	}
+
+	routes := make([]*Route, len(doc.Routes))
+	for i, r := range doc.Routes {
+		if r == nil {
+			return nil, fmt.Errorf("empty route definition at position %d", i)
+		}
+
+		rd, err := parseYAMLRoute(r)
+		if err != nil {
+			return nil, err
+		}
+
+		routes[i] = rd
+	}
+
+	return routes, nil
+}
+
+// mirrors how argsString prints the arguments
+func marshalYAMLArgs(args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+
+	a := make([]interface{}, len(args))
+	for i, ai := range args {
+		switch v := ai.(type) {
+		case int, float64, string:
+			a[i] = v
+		default:
+			if m, ok := ai.(interface{ MarshalText() ([]byte, error) }); ok {
+				if t, err := m.MarshalText(); err == nil {
+					a[i] = string(t)
+				} else {
+					a[i] = "[error]"
+				}
+			} else {
+				a[i] = fmt.Sprint(ai)
+			}
+		}
+	}
+
+	return a
+}
+
+// the legacy fields are converted to predicates, in the same order as
+// they are printed in the eskip format, with the headers sorted
+func yamlPredicates(r *Route) []*yamlNameArgs {
+	var p []*yamlNameArgs
+	appendPredicate := func(name string, args ...interface{}) {
+		p = append(p, &yamlNameArgs{Name: name, Args: args})
+	}
+
+	if r.Path != "" {
+		appendPredicate("Path", r.Path)
+	}
+
+	for _, h := range r.HostRegexps {
+		appendPredicate("Host", h)
+	}
+
+	for _, pr := range r.PathRegexps {
+		appendPredicate("PathRegexp", pr)
+	}
+
+	if r.Method != "" {
+		appendPredicate("Method", r.Method)
+	}
+
+	for _, k := range sortedKeys(r.Headers) {
+		appendPredicate("Header", k, r.Headers[k])
+	}
+
+	for _, k := range sortedKeys(r.HeaderRegexps) {
+		for _, rx := range r.HeaderRegexps[k] {
+			appendPredicate("HeaderRegexp", k, rx)
+		}
+	}
+
+	for _, pi := range r.Predicates {
+		if pi.Name != "Any" {
+			p = append(p, &yamlNameArgs{Name: pi.Name, Args: marshalYAMLArgs(pi.Args)})
+		}
+	}
+
+	return p
+}
+
+func yamlRouteBackend(r *Route) *yamlBackend {
+	b := &yamlBackend{Type: r.BackendType.String()}
+	switch {
+	case r.Shunt:
+		b.Type = "shunt"
+	case r.BackendType == NetworkBackend:
+		b.Address = r.Backend
+	case r.BackendType == LBBackend:
+		b.Algorithm = r.LBAlgorithm
+		b.Endpoints = r.LBEndpoints
+	}
+
+	return b
+}
+
+func toYAMLRoute(r *Route) *yamlRoute {
+	yr := &yamlRoute{
+		Id:         r.Id,
+		Priority:   r.Priority,
+		Metadata:   r.Metadata,
+		Predicates: yamlPredicates(r),
+		Backend:    yamlRouteBackend(r),
+	}
+
+	for _, f := range r.Filters {
+		yr.Filters = append(yr.Filters, &yamlNameArgs{Name: f.Name, Args: marshalYAMLArgs(f.Args)})
+	}
+
+	return yr
+}
+
+// MarshalYAML serializes a list of route definitions into the YAML
+// document format accepted by ParseYAML. The legacy fields of the routes,
+// like Path or Method, are converted to predicates.
+func MarshalYAML(routes ...*Route) ([]byte, error) {
+	doc := yamlDocument{Routes: make([]*yamlRoute, 0, len(routes))}
+	for _, r := range routes {
+		if r != nil {
+			doc.Routes = append(doc.Routes, toYAMLRoute(r))
+		}
+	}
+
+	return yaml.Marshal(&doc)
+}
@@ -0,0 +1,284 @@
+package eskip
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYAMLRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		doc   string
+	}{{
+		title: "network backend",
+		doc:   `r: Path("/foo") -> "https://www.example.org"`,
+	}, {
+		title: "shunt with filters",
+		doc:   `r: * -> setRequestHeader("X-Foo", "bar") -> status(418) -> inlineContent("I'm a teapot") -> <shunt>`,
+	}, {
+		title: "loopback and dynamic",
+		doc: `
+			r1: Path("/foo") -> setPath("/bar") -> <loopback>;
+			r2: Path("/bar") -> setDynamicBackendUrl("https://www.example.org") -> <dynamic>;
+		`,
+	}, {
+		title: "legacy predicates",
+		doc: `r: Host(/^www[.]example[.]org$/) && PathRegexp(/^\/foo/) && Method("GET") &&
+			Header("X-Foo", "foo") && Header("X-Bar", "bar") && HeaderRegexp("X-Baz", /^baz/) -> <shunt>`,
+	}, {
+		title: "custom predicates with numbers",
+		doc:   `r: PathSubtree("/foo") && Traffic(.3) && Weight(10) && Cookie("foo", "bar") -> <shunt>`,
+	}, {
+		title: "load balanced backend",
+		doc:   `r: Path("/foo") -> <roundRobin, "http://10.2.0.1:8080", "http://10.2.0.2:8080">`,
+	}, {
+		title: "load balanced backend with the default algorithm",
+		doc:   `r: Path("/foo") -> <"http://10.2.0.1:8080", "http://10.2.0.2:8080">`,
+	}, {
+		title: "priority and metadata",
+		doc:   `r [priority=3, owner="team-foo", "app.kubernetes.io/name"="foo"]: Path("/foo") -> <shunt>`,
+	}, {
+		title: "strings that look like other values in YAML",
+		doc:   `r: Header("X-Foo", "true") && Header("X-Bar", "42") -> setQuery("null", "~") -> <shunt>`,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			routes, err := Parse(test.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			y, err := MarshalYAML(routes...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := ParseYAML(y)
+			if err != nil {
+				t.Fatalf("failed to parse the YAML: %v\n%s", err, y)
+			}
+
+			if !EqLists(routes, parsed) {
+				t.Errorf("round trip failed, expected: %s, got: %s", String(routes...), String(parsed...))
+			}
+
+			for i := range routes {
+				if routes[i].Priority != parsed[i].Priority || !reflect.DeepEqual(routes[i].Metadata, parsed[i].Metadata) {
+					t.Errorf("attributes lost in %s: %+v, %+v", routes[i].Id, parsed[i].Priority, parsed[i].Metadata)
+				}
+			}
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	routes, err := ParseYAML([]byte(`
+routes:
+- id: api
+  priority: 1
+  metadata:
+    owner: team-foo
+  predicates:
+  - name: Path
+    args: ["/api"]
+  - name: Traffic
+    args: [0.5]
+  filters:
+  - name: setRequestHeader
+    args: [X-Foo, bar]
+  - name: status
+    args: [418]
+  backend:
+    type: lb
+    algorithm: roundRobin
+    endpoints:
+    - http://10.2.0.1:8080
+    - http://10.2.0.2:8080
+- id: catchAll
+  backend:
+    type: shunt
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := Parse(`
+		api [priority=1, owner="team-foo"]: Path("/api") && Traffic(0.5)
+			-> setRequestHeader("X-Foo", "bar")
+			-> status(418)
+			-> <roundRobin, "http://10.2.0.1:8080", "http://10.2.0.2:8080">;
+		catchAll: * -> <shunt>;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !EqLists(routes, expected) {
+		t.Errorf("invalid routes, expected: %s, got: %s", String(expected...), String(routes...))
+	}
+
+	if !reflect.DeepEqual(routes[1].Filters, expected[1].Filters) || routes[0].Path != "/api" {
+		t.Errorf("unexpected route definition: %+v", routes[0])
+	}
+
+	if routes[0].Priority != 1 || routes[0].Metadata["owner"] != "team-foo" {
+		t.Errorf("invalid attributes: %d, %v", routes[0].Priority, routes[0].Metadata)
+	}
+
+	if _, ok := routes[0].Filters[1].Args[0].(float64); !ok {
+		t.Errorf("invalid numeric argument type: %T", routes[0].Filters[1].Args[0])
+	}
+}
+
+func TestParseYAMLInvalid(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		doc   string
+		err   string
+	}{{
+		title: "unknown field",
+		doc:   "routes:\n- id: r\n  backend: {type: shunt}\n  foo: bar",
+		err:   "field foo not found",
+	}, {
+		title: "missing backend",
+		doc:   "routes:\n- id: r",
+		err:   "missing backend",
+	}, {
+		title: "missing backend type",
+		doc:   "routes:\n- id: r\n  backend: {address: https://www.example.org}",
+		err:   "missing backend type",
+	}, {
+		title: "unsupported backend type",
+		doc:   "routes:\n- id: r\n  backend: {type: foo}",
+		err:   "unsupported backend type",
+	}, {
+		title: "network backend without address",
+		doc:   "routes:\n- id: r\n  backend: {type: network}",
+		err:   "missing backend address",
+	}, {
+		title: "shunt with address",
+		doc:   "routes:\n- id: r\n  backend: {type: shunt, address: https://www.example.org}",
+		err:   "address not allowed",
+	}, {
+		title: "lb without endpoints",
+		doc:   "routes:\n- id: r\n  backend: {type: lb, algorithm: roundRobin}",
+		err:   "missing backend endpoints",
+	}, {
+		title: "mixed lb endpoint protocols",
+		doc:   "routes:\n- id: r\n  backend: {type: lb, endpoints: [http://10.2.0.1, https://10.2.0.2]}",
+		err:   errMixedProtocols.Error(),
+	}, {
+		title: "invalid route id",
+		doc:   "routes:\n- id: foo-bar\n  backend: {type: shunt}",
+		err:   "invalid route name",
+	}, {
+		title: "invalid filter name",
+		doc:   "routes:\n- id: r\n  filters: [{name: foo()}]\n  backend: {type: shunt}",
+		err:   "invalid filter name",
+	}, {
+		title: "unsupported argument",
+		doc:   "routes:\n- id: r\n  predicates: [{name: Foo, args: [true]}]\n  backend: {type: shunt}",
+		err:   "unsupported argument type",
+	}, {
+		title: "invalid legacy predicate",
+		doc:   "routes:\n- id: r\n  predicates: [{name: Path, args: [42]}]\n  backend: {type: shunt}",
+		err:   "invalid",
+	}, {
+		title: "invalid metadata key",
+		doc:   "routes:\n- id: r\n  metadata: {priority: high}\n  backend: {type: shunt}",
+		err:   "invalid route priority",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			_, err := ParseYAML([]byte(test.doc))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got: %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	routes, err := Parse(`
+		r1 [owner="team-foo"]: Method("GET") && Path("/foo") && Header("X-B", "b") && Header("X-A", "a")
+			-> setPath("/bar")
+			-> "https://www.example.org";
+		r2: * -> <shunt>;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y, err := MarshalYAML(routes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `routes:
+- id: r1
+  metadata:
+    owner: team-foo
+  predicates:
+  - name: Path
+    args:
+    - /foo
+  - name: Method
+    args:
+    - GET
+  - name: Header
+    args:
+    - X-A
+    - a
+  - name: Header
+    args:
+    - X-B
+    - b
+  filters:
+  - name: setPath
+    args:
+    - /bar
+  backend:
+    type: network
+    address: https://www.example.org
+- id: r2
+  backend:
+    type: shunt
+`
+
+	if string(y) != expected {
+		t.Errorf("invalid YAML, expected:\n%s\ngot:\n%s", expected, y)
+	}
+}
+
+func TestYAMLSchema(t *testing.T) {
+	b, err := os.ReadFile("routes.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Definitions struct {
+			Backend struct {
+				Properties struct {
+					Type struct {
+						Enum []string `json:"enum"`
+					} `json:"type"`
+				} `json:"properties"`
+			} `json:"backend"`
+		} `json:"definitions"`
+	}
+
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	for _, bt := range []BackendType{NetworkBackend, ShuntBackend, LoopBackend, DynamicBackend, LBBackend} {
+		expected = append(expected, bt.String())
+	}
+
+	if got := schema.Definitions.Backend.Properties.Type.Enum; !reflect.DeepEqual(got, expected) {
+		t.Errorf("invalid backend types in the schema, expected: %v, got: %v", expected, got)
+	}
+}
@@ -1,6 +1,7 @@
 /*
 Package eskipfile implements the DataClient interface for reading the skipper route definitions from an eskip
-formatted file.
+formatted file. Files with the .yaml or .yml extension are parsed as YAML documents of routes, see
+eskip.ParseYAML.
 
 (See the DataClient interface in the skipper/routing package and the eskip
 format in the skipper/eskip package.)
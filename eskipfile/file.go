@@ -1,7 +1,10 @@
 package eskipfile
 
 import (
+	"net/url"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/zalando/skipper/eskip"
 )
@@ -10,15 +13,40 @@ import (
 // to create instances of it.
 type Client struct{ routes []*eskip.Route }
 
+// tells whether a route file, or the URL of a remote route file, is in
+// the YAML format, based on its extension
+func isYAML(name string) bool {
+	if u, err := url.Parse(name); err == nil && u.Scheme != "" {
+		name = u.Path
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// parses the content of a route file, in the eskip or the YAML format
+func parseFile(name string, content []byte) ([]*eskip.Route, error) {
+	if isYAML(name) {
+		return eskip.ParseYAML(content)
+	}
+
+	return eskip.Parse(string(content))
+}
+
 // Opens an eskip file and parses it, returning a DataClient implementation. If reading or parsing the file
-// fails, returns an error. This implementation doesn't provide file watch.
+// fails, returns an error. This implementation doesn't provide file watch. Files with the .yaml or .yml
+// extension are parsed as YAML documents, see eskip.ParseYAML.
 func Open(path string) (*Client, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	routes, err := eskip.Parse(string(content))
+	routes, err := parseFile(path, content)
 	if err != nil {
 		return nil, err
 	}
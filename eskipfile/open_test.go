@@ -18,8 +18,8 @@ func TestOpenFails(t *testing.T) {
 	}
 }
 
-func TestOpenSucceeds(t *testing.T) {
-	f, err := Open("fixtures/test.eskip")
+func testOpenSucceeds(t *testing.T, name string) {
+	f, err := Open(name)
 	if err != nil {
 		t.Error(err)
 		return
@@ -53,3 +53,26 @@ func TestOpenSucceeds(t *testing.T) {
 	check("foo", "/foo")
 	check("bar", "/bar")
 }
+
+func TestOpenSucceeds(t *testing.T) {
+	testOpenSucceeds(t, "fixtures/test.eskip")
+}
+
+func TestOpenYAML(t *testing.T) {
+	testOpenSucceeds(t, "fixtures/test.yaml")
+}
+
+func TestIsYAML(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"routes.eskip": false,
+		"routes":       false,
+		"routes.yaml":  true,
+		"ROUTES.YML":   true,
+		"https://www.example.org/routes.yaml?v=1":   true,
+		"https://www.example.org/routes.yaml.eskip": false,
+	} {
+		if got := isYAML(name); got != expected {
+			t.Errorf("invalid format for %s, expected YAML: %v, got: %v", name, expected, got)
+		}
+	}
+}
@@ -48,7 +48,13 @@ func RemoteWatch(o *RemoteWatchOptions) (routing.DataClient, error) {
 		return Watch(o.RemoteFile), nil
 	}
 
-	tempFilename, err := os.CreateTemp("", "routes")
+	// the extension of the temporary file tells the format of the routes
+	pattern := "routes"
+	if isYAML(o.RemoteFile) {
+		pattern += "*.yaml"
+	}
+
+	tempFilename, err := os.CreateTemp("", pattern)
 
 	if err != nil {
 		return nil, err
@@ -97,7 +97,7 @@ func (c *WatchClient) loadAll() watchResponse {
 		return watchResponse{err: err}
 	}
 
-	r, err := eskip.Parse(string(content))
+	r, err := parseFile(c.fileName, content)
 	if err != nil {
 		return watchResponse{err: err}
 	}
@@ -117,7 +117,7 @@ func (c *WatchClient) loadUpdates() watchResponse {
 		return watchResponse{err: err}
 	}
 
-	r, err := eskip.Parse(string(content))
+	r, err := parseFile(c.fileName, content)
 	if err != nil {
 		return watchResponse{err: err}
 	}
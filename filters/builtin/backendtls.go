@@ -0,0 +1,43 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type backendTLS struct {
+	profile string
+}
+
+// NewBackendTLS creates a filter specification whose filter instances
+// select a named backend TLS profile, configured in the proxy, for the
+// connections to the backend of the route. The profiles can set a
+// client certificate, a CA bundle, a server name and a minimal TLS
+// version, per backend:
+//
+// 	* -> backendTLS("partner-a") -> "https://api.partner-a.example.org";
+//
+// When the profile is not configured, the proxy responds with 502 Bad
+// Gateway.
+func NewBackendTLS() filters.Spec { return &backendTLS{} }
+
+func (*backendTLS) Name() string { return filters.BackendTLSName }
+
+func (*backendTLS) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	profile, ok := args[0].(string)
+	if !ok || profile == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &backendTLS{profile: profile}, nil
+}
+
+func (f *backendTLS) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.BackendTLSProfile] = f.profile
+}
+
+func (*backendTLS) Response(filters.FilterContext) {}
@@ -0,0 +1,59 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestBackendTLSArgs(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		args  []interface{}
+		fail  bool
+	}{{
+		title: "no args",
+		fail:  true,
+	}, {
+		title: "too many args",
+		args:  []interface{}{"a", "b"},
+		fail:  true,
+	}, {
+		title: "empty name",
+		args:  []interface{}{""},
+		fail:  true,
+	}, {
+		title: "invalid type",
+		args:  []interface{}{float64(1)},
+		fail:  true,
+	}, {
+		title: "profile",
+		args:  []interface{}{"partner-a"},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			_, err := NewBackendTLS().CreateFilter(test.args)
+			if test.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !test.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestBackendTLS(t *testing.T) {
+	f, err := NewBackendTLS().CreateFilter([]interface{}{"partner-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &filtertest.Context{FStateBag: map[string]interface{}{
+		filters.BackendTLSProfile: "partner-b",
+	}}
+
+	f.Request(ctx)
+	if p := ctx.StateBag()[filters.BackendTLSProfile]; p != "partner-a" {
+		t.Errorf("invalid profile, expected: partner-a, got: %v", p)
+	}
+}
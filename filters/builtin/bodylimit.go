@@ -0,0 +1,182 @@
+package builtin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+	snet "github.com/zalando/skipper/net"
+)
+
+type bodyLimitType int
+
+const (
+	requestBodyLimit bodyLimitType = iota
+	responseBodyLimit
+)
+
+type bodyLimit struct {
+	typ   bodyLimitType
+	limit int64
+}
+
+var errResponseBodyTooLarge = errors.New("response body exceeds the limit")
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// NewRequestBodyLimit creates a filter specification whose filter
+// instances reject requests with bodies larger than the configured
+// limit. Requests with a larger Content-Length are rejected with 413
+// before they reach the backend. The bodies of requests without a
+// Content-Length are cut off once the limit is crossed, and, when this
+// happens before the backend responded, the proxy responds with 413.
+//
+// The limit is either the number of bytes, or a string with one of the
+// B, KB, MB or GB units, e.g:
+//
+// 	* -> requestBodyLimit("10MB") -> "https://www.example.org";
+//
+func NewRequestBodyLimit() filters.Spec {
+	return &bodyLimit{typ: requestBodyLimit}
+}
+
+// NewResponseBodyLimit creates a filter specification whose filter
+// instances abort responses with bodies larger than the configured
+// limit. Responses with a larger Content-Length are not forwarded and
+// the proxy responds with 502. Otherwise, the response is streamed
+// until the limit is crossed, and then the client connection is reset.
+//
+// The limit has the same format as in the requestBodyLimit filter:
+//
+// 	* -> responseBodyLimit("50MB") -> "https://www.example.org";
+//
+func NewResponseBodyLimit() filters.Spec {
+	return &bodyLimit{typ: responseBodyLimit}
+}
+
+func (s *bodyLimit) Name() string {
+	if s.typ == requestBodyLimit {
+		return filters.RequestBodyLimitName
+	}
+
+	return filters.ResponseBodyLimitName
+}
+
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			multiplier = u.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)/multiplier {
+		return 0, filters.ErrInvalidFilterParameters
+	}
+
+	return n * multiplier, nil
+}
+
+// sizeArg accepts the number of bytes, or a string with one of the B,
+// KB, MB or GB units.
+func sizeArg(a interface{}) (int64, error) {
+	switch v := a.(type) {
+	case string:
+		return parseSize(v)
+	case float64:
+		if v < 0 || v != float64(int64(v)) {
+			return 0, filters.ErrInvalidFilterParameters
+		}
+
+		return int64(v), nil
+	case int:
+		if v < 0 {
+			return 0, filters.ErrInvalidFilterParameters
+		}
+
+		return int64(v), nil
+	default:
+		return 0, filters.ErrInvalidFilterParameters
+	}
+}
+
+func (s *bodyLimit) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	l, err := sizeArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &bodyLimit{typ: s.typ, limit: l}, nil
+}
+
+func (f *bodyLimit) Request(ctx filters.FilterContext) {
+	if f.typ != requestBodyLimit {
+		return
+	}
+
+	req := ctx.Request()
+	if req.ContentLength > f.limit {
+		ctx.Serve(&http.Response{StatusCode: http.StatusRequestEntityTooLarge})
+		return
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	// the proxy maps *http.MaxBytesError to 413, when the backend
+	// request fails, while reading the body
+	req.Body = http.MaxBytesReader(nil, req.Body, f.limit)
+}
+
+func (f *bodyLimit) Response(ctx filters.FilterContext) {
+	if f.typ != responseBodyLimit {
+		return
+	}
+
+	rsp := ctx.Response()
+	if rsp.Body == nil || rsp.Body == http.NoBody {
+		return
+	}
+
+	if rsp.ContentLength > f.limit {
+		log.Errorf(
+			"Response body from %s exceeds the limit: content length %d, limit %d.",
+			ctx.BackendUrl(),
+			rsp.ContentLength,
+			f.limit,
+		)
+
+		rsp.Body.Close()
+		ctx.Serve(&http.Response{StatusCode: http.StatusBadGateway})
+		return
+	}
+
+	backend := ctx.BackendUrl()
+	rsp.Body = snet.NewTruncatedBody(rsp.Body, f.limit, ctx.ResponseWriter(), errResponseBodyTooLarge, func() {
+		log.Errorf(
+			"Response body from %s exceeds the limit of %d bytes, aborting the response.",
+			backend,
+			f.limit,
+		)
+	})
+}
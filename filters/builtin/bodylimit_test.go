@@ -0,0 +1,261 @@
+package builtin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestBodyLimitArgs(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		args     []interface{}
+		expected int64
+		fail     bool
+	}{{
+		title: "no args",
+		fail:  true,
+	}, {
+		title: "too many args",
+		args:  []interface{}{"1MB", "2MB"},
+		fail:  true,
+	}, {
+		title: "invalid type",
+		args:  []interface{}{true},
+		fail:  true,
+	}, {
+		title: "invalid unit",
+		args:  []interface{}{"10TB"},
+		fail:  true,
+	}, {
+		title: "negative",
+		args:  []interface{}{-1.0},
+		fail:  true,
+	}, {
+		title: "fraction",
+		args:  []interface{}{1.5},
+		fail:  true,
+	}, {
+		title:    "bytes as number",
+		args:     []interface{}{1024.0},
+		expected: 1024,
+	}, {
+		title:    "bytes as int",
+		args:     []interface{}{42},
+		expected: 42,
+	}, {
+		title:    "bytes as string",
+		args:     []interface{}{"42B"},
+		expected: 42,
+	}, {
+		title:    "kilobytes",
+		args:     []interface{}{"16KB"},
+		expected: 16 << 10,
+	}, {
+		title:    "megabytes",
+		args:     []interface{}{"10MB"},
+		expected: 10 << 20,
+	}, {
+		title:    "gigabytes, lower case",
+		args:     []interface{}{"2gb"},
+		expected: 2 << 30,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			f, err := NewRequestBodyLimit().CreateFilter(test.args)
+			if test.fail {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if l := f.(*bodyLimit).limit; l != test.expected {
+				t.Errorf("invalid limit, got: %d, expected: %d", l, test.expected)
+			}
+		})
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Write(b)
+	}))
+	defer backend.Close()
+
+	r, err := eskip.Parse(fmt.Sprintf(`* -> requestBodyLimit(8) -> "%s"`, backend.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := proxytest.New(MakeRegistry(), r...)
+	defer p.Close()
+
+	for _, test := range []struct {
+		title          string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{{
+		title:          "content length within the limit",
+		body:           "foobar",
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "content length at the limit",
+		body:           "foobarba",
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "content length over the limit",
+		body:           "foobarbaz",
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}, {
+		title:          "chunked within the limit",
+		body:           "foobar",
+		chunked:        true,
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "chunked over the limit",
+		body:           strings.Repeat("foobarbaz", 1<<12),
+		chunked:        true,
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(test.body)
+			if test.chunked {
+				// hiding the type of the reader prevents the client
+				// from setting the content length
+				body = io.MultiReader(body)
+			}
+
+			req, err := http.NewRequest("POST", p.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != test.expectedStatus {
+				t.Fatalf("invalid status, got: %d, expected: %d", rsp.StatusCode, test.expectedStatus)
+			}
+
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Errorf("invalid body, got: %s, expected: %s", string(b), test.body)
+			}
+		})
+	}
+}
+
+func TestResponseBodyLimit(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.URL.Query().Get("body")
+		if r.URL.Query().Get("chunked") == "true" {
+			for _, c := range body {
+				w.Write([]byte(string(c)))
+				w.(http.Flusher).Flush()
+			}
+
+			return
+		}
+
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer backend.Close()
+
+	r, err := eskip.Parse(fmt.Sprintf(`* -> responseBodyLimit(8) -> "%s"`, backend.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := proxytest.New(MakeRegistry(), r...)
+	defer p.Close()
+
+	for _, test := range []struct {
+		title          string
+		body           string
+		chunked        bool
+		expectedStatus int
+		expectAborted  bool
+	}{{
+		title:          "content length within the limit",
+		body:           "foobar",
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "content length over the limit",
+		body:           "foobarbaz",
+		expectedStatus: http.StatusBadGateway,
+	}, {
+		title:          "chunked at the limit",
+		body:           "foobarba",
+		chunked:        true,
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "chunked over the limit",
+		body:           "foobarbaz",
+		chunked:        true,
+		expectedStatus: http.StatusOK,
+		expectAborted:  true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			rsp, err := http.Get(fmt.Sprintf("%s/?body=%s&chunked=%t", p.URL, test.body, test.chunked))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != test.expectedStatus {
+				t.Fatalf("invalid status, got: %d, expected: %d", rsp.StatusCode, test.expectedStatus)
+			}
+
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if test.expectAborted {
+				if err == nil {
+					t.Error("failed to abort the response")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Errorf("invalid body, got: %s, expected: %s", string(b), test.body)
+			}
+		})
+	}
+}
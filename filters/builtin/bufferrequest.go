@@ -0,0 +1,186 @@
+package builtin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+)
+
+const bufferRequestFilePattern = "skipper-request-body-*"
+
+type bufferRequest struct {
+	maxMemory int64
+	maxDisk   int64
+}
+
+// bufferedBody holds the complete request body either in memory or,
+// when it is larger than the memory threshold, in a temporary file.
+type bufferedBody struct {
+	mx   sync.Mutex
+	mem  []byte
+	file *os.File
+	size int64
+}
+
+var errBufferLimitExceeded = errors.New("request body exceeds the buffer limit")
+
+// NewBufferRequest creates a filter specification whose filter instances
+// read the complete request body before the request is forwarded. The
+// body is kept in memory up to the first argument, and spooled to a
+// temporary file beyond that, up to the second argument. Requests with
+// larger bodies are rejected with 413. The sizes have the same format as
+// in the requestBodyLimit filter. When the second argument is 0, the body
+// is never spooled to disk.
+//
+// The buffered body can be read multiple times, by getting a new reader
+// from the GetBody function of the request. The proxy uses it when
+// retrying the backend request.
+//
+// Eskip example:
+//
+// 	* -> bufferRequest("1MB", "100MB") -> "https://www.example.org";
+//
+func NewBufferRequest() filters.Spec { return &bufferRequest{} }
+
+func (*bufferRequest) Name() string { return filters.BufferRequestName }
+
+func (*bufferRequest) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	maxMemory, err := sizeArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	maxDisk, err := sizeArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &bufferRequest{maxMemory: maxMemory, maxDisk: maxDisk}, nil
+}
+
+func (f *bufferRequest) limit() int64 {
+	if f.maxDisk > f.maxMemory {
+		return f.maxDisk
+	}
+
+	return f.maxMemory
+}
+
+func (f *bufferRequest) buffer(body io.Reader) (*bufferedBody, error) {
+	b := &bufferedBody{}
+
+	// reading one byte more than the memory threshold tells whether
+	// the body needs to be spooled to disk
+	var mem bytes.Buffer
+	n, err := io.Copy(&mem, io.LimitReader(body, f.maxMemory+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if n <= f.maxMemory {
+		b.mem = mem.Bytes()
+		b.size = n
+		return b, nil
+	}
+
+	if f.maxDisk <= f.maxMemory {
+		return nil, errBufferLimitExceeded
+	}
+
+	b.file, err = os.CreateTemp("", bufferRequestFilePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	b.size, err = io.Copy(b.file, io.MultiReader(&mem, io.LimitReader(body, f.maxDisk-n+1)))
+	if err == nil && b.size > f.maxDisk {
+		err = errBufferLimitExceeded
+	}
+
+	if err != nil {
+		b.close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (f *bufferRequest) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	if req.ContentLength > f.limit() {
+		ctx.Serve(&http.Response{StatusCode: http.StatusRequestEntityTooLarge})
+		return
+	}
+
+	b, err := f.buffer(req.Body)
+	req.Body.Close()
+	if err == errBufferLimitExceeded {
+		ctx.Serve(&http.Response{StatusCode: http.StatusRequestEntityTooLarge})
+		return
+	} else if err != nil {
+		log.Errorf("Failed to buffer request body: %v", err)
+		ctx.Serve(&http.Response{StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	if b.file != nil {
+		// the incoming request context is canceled when the request
+		// was served, or when the client went away
+		go func() {
+			<-req.Context().Done()
+			b.close()
+		}()
+	}
+
+	req.GetBody = b.reader
+	req.Body, _ = b.reader()
+	req.ContentLength = b.size
+	req.TransferEncoding = nil
+	if b.size == 0 {
+		req.Body = http.NoBody
+	}
+}
+
+func (*bufferRequest) Response(filters.FilterContext) {}
+
+func (b *bufferedBody) reader() (io.ReadCloser, error) {
+	if b.file == nil {
+		return io.NopCloser(bytes.NewReader(b.mem)), nil
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if b.size < 0 {
+		return nil, os.ErrClosed
+	}
+
+	return io.NopCloser(io.NewSectionReader(b.file, 0, b.size)), nil
+}
+
+func (b *bufferedBody) close() {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if b.size < 0 {
+		return
+	}
+
+	b.size = -1
+	b.file.Close()
+	if err := os.Remove(b.file.Name()); err != nil {
+		log.Errorf("Failed to remove request body buffer file: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package builtin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestBufferRequestArgs(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		args  []interface{}
+		fail  bool
+	}{{
+		title: "no args",
+		fail:  true,
+	}, {
+		title: "missing disk limit",
+		args:  []interface{}{"1MB"},
+		fail:  true,
+	}, {
+		title: "invalid memory limit",
+		args:  []interface{}{"foo", "1MB"},
+		fail:  true,
+	}, {
+		title: "invalid disk limit",
+		args:  []interface{}{"1MB", -1.0},
+		fail:  true,
+	}, {
+		title: "memory only",
+		args:  []interface{}{"1MB", 0.0},
+	}, {
+		title: "memory and disk",
+		args:  []interface{}{"1MB", "1GB"},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			_, err := NewBufferRequest().CreateFilter(test.args)
+			if test.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !test.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestBufferRequestReplay(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		body  string
+	}{{
+		title: "in memory",
+		body:  "foo",
+	}, {
+		title: "on disk",
+		body:  strings.Repeat("foo", 1<<10),
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			f, err := NewBufferRequest().CreateFilter([]interface{}{"1KB", "1MB"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("POST", "https://www.example.org", io.MultiReader(strings.NewReader(test.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+			if ctx.FServed {
+				t.Fatal("unexpected response")
+			}
+
+			if req.ContentLength != int64(len(test.body)) {
+				t.Errorf("invalid content length, got: %d, expected: %d", req.ContentLength, len(test.body))
+			}
+
+			b, err := io.ReadAll(req.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Error("invalid body")
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err = io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Error("invalid replayed body")
+			}
+		})
+	}
+}
+
+func TestBufferRequest(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength < 0 {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Write(b)
+	}))
+	defer backend.Close()
+
+	r, err := eskip.Parse(fmt.Sprintf(`* -> bufferRequest("1KB", "64KB") -> "%s"`, backend.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := proxytest.New(MakeRegistry(), r...)
+	defer p.Close()
+
+	for _, test := range []struct {
+		title          string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{{
+		title:          "in memory",
+		body:           "foobar",
+		chunked:        true,
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "on disk",
+		body:           strings.Repeat("foobar", 1<<10),
+		chunked:        true,
+		expectedStatus: http.StatusOK,
+	}, {
+		title:          "content length over the limit",
+		body:           strings.Repeat("foobar", 1<<14),
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}, {
+		title:          "chunked over the limit",
+		body:           strings.Repeat("foobar", 1<<14),
+		chunked:        true,
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(test.body)
+			if test.chunked {
+				body = io.MultiReader(body)
+			}
+
+			rsp, err := http.Post(p.URL, "text/plain", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != test.expectedStatus {
+				t.Fatalf("invalid status, got: %d, expected: %d", rsp.StatusCode, test.expectedStatus)
+			}
+
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Error("invalid body")
+			}
+		})
+	}
+
+	// the temporary files are removed asynchronously
+	for i := 0; i < 100; i++ {
+		var files []os.DirEntry
+		files, err = os.ReadDir(tmp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Error("failed to remove the temporary files")
+}
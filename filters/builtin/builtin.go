@@ -159,6 +159,12 @@ func MakeRegistry() filters.Registry {
 		NewHeaderToQuery(),
 		NewQueryToHeader(),
 		NewBackendTimeout(),
+		NewRequestBodyLimit(),
+		NewResponseBodyLimit(),
+		NewBufferRequest(),
+		NewEarlyHints(),
+		NewForwardClientCert(),
+		NewBackendTLS(),
 		NewSetDynamicBackendHostFromHeader(),
 		NewSetDynamicBackendSchemeFromHeader(),
 		NewSetDynamicBackendUrlFromHeader(),
@@ -181,10 +187,12 @@ func MakeRegistry() filters.Registry {
 		diag.NewNormalRequestLatency(),
 		diag.NewUniformResponseLatency(),
 		diag.NewNormalResponseLatency(),
+		diag.NewFaultInjection(),
 		tee.NewTee(),
 		tee.NewTeeDeprecated(),
 		tee.NewTeeNoFollow(),
 		tee.NewTeeLoopback(),
+		tee.NewMirror(),
 		sed.New(),
 		sed.NewDelimited(),
 		sed.NewRequest(),
@@ -210,6 +218,8 @@ func MakeRegistry() filters.Registry {
 		auth.NewForwardTokenField(),
 		scheduler.NewLIFO(),
 		scheduler.NewLIFOGroup(),
+		scheduler.NewFifo(),
+		scheduler.NewFifoGroup(),
 		rfc.NewPath(),
 		rfc.NewHost(),
 		fadein.NewFadeIn(),
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
 )
@@ -19,15 +21,16 @@ import (
 const bufferSize = 8192
 
 type encoding struct {
-	name string
-	q    float32
+	name       string
+	q          float32
+	preference int
 }
 
 type encodings []*encoding
 
 type compress struct {
-	mime  []string
-	level int
+	mime   []string
+	levels map[string]int
 }
 
 type encoder interface {
@@ -37,10 +40,28 @@ type encoder interface {
 }
 
 var (
-	supportedEncodings  = []string{"gzip", "deflate"}
+	// supportedEncodings are in the order of preference, when the client
+	// accepts multiple encodings with the same q-value. Gzip comes first,
+	// to keep the encoding of the clients that don't set q-values.
+	supportedEncodings  = []string{"gzip", "br", "zstd", "deflate"}
 	unsupportedEncoding = errors.New("unsupported encoding")
 )
 
+// the default levels are the best-speed levels of the encodings
+var defaultLevels = map[string]int{
+	"br":      brotli.BestSpeed,
+	"zstd":    1,
+	"gzip":    flate.BestSpeed,
+	"deflate": flate.BestSpeed,
+}
+
+var levelRanges = map[string][2]int{
+	"br":      {brotli.BestSpeed, brotli.BestCompression},
+	"zstd":    {1, 22},
+	"gzip":    {gzip.HuffmanOnly, gzip.BestCompression},
+	"deflate": {flate.HuffmanOnly, flate.BestCompression},
+}
+
 var defaultCompressMIME = []string{
 	"text/plain",
 	"text/html",
@@ -54,24 +75,31 @@ var defaultCompressMIME = []string{
 }
 
 var (
-	gzipPool = &sync.Pool{New: func() interface{} {
-		ge, err := newEncoder("gzip", flate.BestSpeed)
-		if err != nil {
-			log.Error(err)
-		}
-		return ge
-	}}
-	deflatePool = &sync.Pool{New: func() interface{} {
-		fe, err := newEncoder("deflate", flate.BestSpeed)
+	gzipPool    = newEncoderPool("gzip")
+	deflatePool = newEncoderPool("deflate")
+	brotliPool  = newEncoderPool("br")
+	zstdPool    = newEncoderPool("zstd")
+)
+
+func newEncoderPool(enc string) *sync.Pool {
+	return &sync.Pool{New: func() interface{} {
+		e, err := newEncoder(enc, defaultLevels[enc])
 		if err != nil {
 			log.Error(err)
 		}
-		return fe
+		return e
 	}}
-)
+}
+
+func (e encodings) Len() int { return len(e) }
+func (e encodings) Less(i, j int) bool {
+	// higher first
+	if e[i].q != e[j].q {
+		return e[i].q > e[j].q
+	}
 
-func (e encodings) Len() int           { return len(e) }
-func (e encodings) Less(i, j int) bool { return e[i].q > e[j].q } // higher first
+	return e[i].preference < e[j].preference
+}
 func (e encodings) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
 
 // Returns a filter specification that is used to compress the response content.
@@ -110,11 +138,22 @@ func (e encodings) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
 //
 // 	* -> compress(9, "image/tiff") -> "https://www.example.org"
 //
+// The numeric level applies to gzip and deflate. The level of each encoding can
+// be set with arguments in the format of "<encoding>:<level>", following the
+// numeric level, in front of the MIME types. The possible values are 0-11 for
+// br, 1-22 for zstd, and the same as above for gzip and deflate. The default is
+// best-speed for all encodings. Example:
+//
+// 	* -> compress("br:5", "zstd:3", "gzip:6") -> "https://www.example.org"
+//
 // The filter also checks the incoming request, if it accepts the supported
 // encodings, explicitly stated in the Accept-Encoding header. The filter currently
-// supports gzip and deflate. It does not assume that the client accepts any
-// encoding if the Accept-Encoding header is not set. It ignores * in the
-// Accept-Encoding header.
+// supports gzip, br, zstd and deflate. It selects the encoding with the highest
+// q-value, and from the encodings with the same q-value, it prefers them in the
+// above order, so gzip, unless the client prefers an other encoding with a
+// higher q-value. It does not assume that the client accepts any encoding if the
+// Accept-Encoding header is not set. It ignores * in the Accept-Encoding header,
+// and the encodings with q=0.
 //
 // When compressing the response, it updates the response header. It deletes the
 // the Content-Length value triggering the proxy to always return the response
@@ -131,23 +170,39 @@ func (c *compress) Name() string {
 
 func (c *compress) CreateFilter(args []interface{}) (filters.Filter, error) {
 	f := &compress{
-		mime:  defaultCompressMIME,
-		level: flate.BestSpeed}
+		mime:   defaultCompressMIME,
+		levels: make(map[string]int)}
+
+	for enc, level := range defaultLevels {
+		f.levels[enc] = level
+	}
 
 	if len(args) == 0 {
 		return f, nil
 	}
 
 	if lf, ok := args[0].(float64); ok && math.Trunc(lf) == lf {
-		f.level = int(lf)
-		if f.level < gzip.HuffmanOnly || f.level > gzip.BestCompression {
+		level := int(lf)
+		if !validLevel("gzip", level) || !validLevel("deflate", level) {
 			return nil, filters.ErrInvalidFilterParameters
 		}
 
-		if f.level < flate.HuffmanOnly || f.level > flate.BestCompression {
-			return nil, filters.ErrInvalidFilterParameters
+		f.levels["gzip"] = level
+		f.levels["deflate"] = level
+		args = args[1:]
+	}
+
+	for len(args) > 0 {
+		enc, level, ok, err := encodingLevelArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			break
 		}
 
+		f.levels[enc] = level
 		args = args[1:]
 	}
 
@@ -172,6 +227,36 @@ func (c *compress) CreateFilter(args []interface{}) (filters.Filter, error) {
 	return f, nil
 }
 
+func validLevel(enc string, level int) bool {
+	r := levelRanges[enc]
+	return level >= r[0] && level <= r[1]
+}
+
+// encodingLevelArg parses the arguments in the format of
+// "<encoding>:<level>". It returns false, if the argument has
+// a different format.
+func encodingLevelArg(a interface{}) (enc string, level int, ok bool, err error) {
+	s, isString := a.(string)
+	if !isString {
+		return
+	}
+
+	i := strings.Index(s, ":")
+	if i < 0 || !stringsContain(supportedEncodings, s[:i]) {
+		return
+	}
+
+	enc = s[:i]
+	level, err = strconv.Atoi(s[i+1:])
+	if err != nil || !validLevel(enc, level) {
+		err = filters.ErrInvalidFilterParameters
+		return
+	}
+
+	ok = true
+	return
+}
+
 func (c *compress) Request(_ filters.FilterContext) {}
 
 func stringsContain(ss []string, s string, transform ...func(string) string) bool {
@@ -210,7 +295,9 @@ func canEncodeEntity(r *http.Response, mime []string) bool {
 	return true
 }
 
-func acceptedEncoding(r *http.Request) string {
+// acceptedEncodings returns the supported encodings accepted by the
+// client, in the order of preference.
+func acceptedEncodings(r *http.Request) []string {
 	var encs encodings
 	for _, s := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
 		sp := strings.Split(s, ";")
@@ -219,12 +306,19 @@ func acceptedEncoding(r *http.Request) string {
 		}
 
 		name := strings.ToLower(strings.TrimSpace(sp[0]))
-		if !stringsContain(supportedEncodings, name) {
+		preference := -1
+		for i, se := range supportedEncodings {
+			if se == name {
+				preference = i
+				break
+			}
+		}
+
+		if preference < 0 {
 			continue
 		}
 
-		enc := &encoding{name, 1}
-		encs = append(encs, enc)
+		enc := &encoding{name, 1, preference}
 
 		for _, spi := range sp[1:] {
 			spi = strings.TrimSpace(spi)
@@ -240,14 +334,28 @@ func acceptedEncoding(r *http.Request) string {
 			enc.q = float32(q)
 			break
 		}
+
+		if enc.q > 0 {
+			encs = append(encs, enc)
+		}
 	}
 
+	sort.Sort(encs)
+	names := make([]string, len(encs))
+	for i, e := range encs {
+		names[i] = e.name
+	}
+
+	return names
+}
+
+func acceptedEncoding(r *http.Request) string {
+	encs := acceptedEncodings(r)
 	if len(encs) == 0 {
 		return ""
 	}
 
-	sort.Sort(encs)
-	return encs[0].name
+	return encs[0]
 }
 
 func responseHeader(r *http.Response, enc string) {
@@ -272,6 +380,14 @@ func newEncoder(enc string, level int) (encoder, error) {
 		return gzip.NewWriterLevel(nil, level)
 	case "deflate":
 		return flate.NewWriter(nil, level)
+	case "br":
+		return brotli.NewWriterLevel(nil, level), nil
+	case "zstd":
+		return zstd.NewWriter(
+			nil,
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+			zstd.WithEncoderConcurrency(1),
+		)
 	default:
 		unsupported()
 		return nil, nil
@@ -284,6 +400,10 @@ func encoderPool(enc string) *sync.Pool {
 		return gzipPool
 	case "deflate":
 		return deflatePool
+	case "br":
+		return brotliPool
+	case "zstd":
+		return zstdPool
 	default:
 		unsupported()
 		return nil
@@ -299,7 +419,7 @@ func encode(out *io.PipeWriter, in io.ReadCloser, enc string, level int) {
 	defer func() {
 		if e != nil {
 			cerr := e.Close()
-			if cerr == nil && level == flate.BestSpeed {
+			if cerr == nil && level == defaultLevels[enc] {
 				encoderPool(enc).Put(e)
 			}
 		}
@@ -312,7 +432,7 @@ func encode(out *io.PipeWriter, in io.ReadCloser, enc string, level int) {
 		in.Close()
 	}()
 
-	if level == flate.BestSpeed {
+	if level == defaultLevels[enc] {
 		e = encoderPool(enc).Get().(encoder)
 
 		// if the pool.New failed to create an encoder,
@@ -372,5 +492,5 @@ func (c *compress) Response(ctx filters.FilterContext) {
 	}
 
 	responseHeader(rsp, enc)
-	responseBody(rsp, enc, c.level)
+	responseBody(rsp, enc, c.levels[enc])
 }
@@ -9,11 +9,14 @@ import (
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/filters/filtertest"
@@ -77,6 +80,15 @@ func decoder(enc string, r io.Reader) io.Reader {
 		return rr
 	case "deflate":
 		return flate.NewReader(r)
+	case "br":
+		return brotli.NewReader(r)
+	case "zstd":
+		rr, err := zstd.NewReader(r)
+		if err != nil {
+			panic(err)
+		}
+
+		return rr.IOReadCloser()
 	default:
 		panic(unsupportedEncoding)
 	}
@@ -215,12 +227,112 @@ func TestCompressArgs(t *testing.T) {
 			}
 		}
 
-		if c.level != ti.expectedLevel {
-			t.Error(ti.msg, "invalid level", ti.expectedLevel, c.level)
+		if c.levels["gzip"] != ti.expectedLevel || c.levels["deflate"] != ti.expectedLevel {
+			t.Error(ti.msg, "invalid level", ti.expectedLevel, c.levels["gzip"], c.levels["deflate"])
 		}
 	}
 }
 
+func TestCompressEncodingLevelArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg            string
+		args           []interface{}
+		fail           bool
+		expectedLevels map[string]int
+		expectedMime   []string
+	}{{
+		msg:            "defaults",
+		expectedLevels: defaultLevels,
+		expectedMime:   defaultCompressMIME,
+	}, {
+		msg:  "brotli and zstd",
+		args: []interface{}{"br:5", "zstd:3"},
+		expectedLevels: map[string]int{
+			"br":      5,
+			"zstd":    3,
+			"gzip":    flate.BestSpeed,
+			"deflate": flate.BestSpeed,
+		},
+		expectedMime: defaultCompressMIME,
+	}, {
+		msg:  "numeric level, separate gzip level and mime types",
+		args: []interface{}{float64(6), "gzip:9", "x/custom"},
+		expectedLevels: map[string]int{
+			"br":      brotli.BestSpeed,
+			"zstd":    1,
+			"gzip":    9,
+			"deflate": 6,
+		},
+		expectedMime: []string{"x/custom"},
+	}, {
+		msg:  "brotli level too big",
+		args: []interface{}{"br:12"},
+		fail: true,
+	}, {
+		msg:  "zstd level too small",
+		args: []interface{}{"zstd:0"},
+		fail: true,
+	}, {
+		msg:  "invalid level",
+		args: []interface{}{"gzip:foo"},
+		fail: true,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			f, err := NewCompress().CreateFilter(ti.args)
+			if ti.fail {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := f.(*compress)
+			if !reflect.DeepEqual(c.levels, ti.expectedLevels) {
+				t.Error("invalid levels", ti.expectedLevels, c.levels)
+			}
+
+			if !reflect.DeepEqual(c.mime, ti.expectedMime) {
+				t.Error("invalid mime types", ti.expectedMime, c.mime)
+			}
+		})
+	}
+}
+
+func TestAcceptedEncoding(t *testing.T) {
+	for _, ti := range []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"x-custom", ""},
+		{"gzip, deflate", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"gzip, deflate, br", "gzip"},
+		{"br, zstd, deflate, gzip", "gzip"},
+		{"gzip, zstd", "gzip"},
+		{"zstd, br", "br"},
+		{"deflate, zstd", "zstd"},
+		{"gzip;q=0.8, br", "br"},
+		{"br;q=0.5, zstd;q=0.8, gzip", "gzip"},
+		{"br;q=0.5, zstd;q=0.8", "zstd"},
+		{"BR", "br"},
+		{"br;q=0, gzip;q=0.1", "gzip"},
+		{"br;q=0", ""},
+	} {
+		t.Run(ti.acceptEncoding, func(t *testing.T) {
+			req := &http.Request{Header: http.Header{"Accept-Encoding": []string{ti.acceptEncoding}}}
+			if enc := acceptedEncoding(req); enc != ti.expected {
+				t.Errorf("invalid encoding, expected: %q, got: %q", ti.expected, enc)
+			}
+		})
+	}
+}
+
 func TestCompress(t *testing.T) {
 	for _, ti := range []struct {
 		msg            string
@@ -391,6 +503,42 @@ func TestCompress(t *testing.T) {
 		http.Header{
 			"Content-Encoding": []string{"deflate"},
 			"Vary":             []string{"Accept-Encoding"}},
+	}, {
+		"brotli",
+		http.Header{},
+		3 * 8192,
+		nil,
+		"gzip;q=0.5, deflate;q=0.5, br",
+		http.Header{
+			"Content-Encoding": []string{"br"},
+			"Vary":             []string{"Accept-Encoding"}},
+	}, {
+		"brotli, best compression",
+		http.Header{},
+		3 * 8192,
+		[]interface{}{"br:11"},
+		"br",
+		http.Header{
+			"Content-Encoding": []string{"br"},
+			"Vary":             []string{"Accept-Encoding"}},
+	}, {
+		"zstd",
+		http.Header{},
+		maxTestContent,
+		nil,
+		"gzip;q=0.5, zstd",
+		http.Header{
+			"Content-Encoding": []string{"zstd"},
+			"Vary":             []string{"Accept-Encoding"}},
+	}, {
+		"zstd, custom level",
+		http.Header{},
+		3 * 8192,
+		[]interface{}{"zstd:9"},
+		"zstd",
+		http.Header{
+			"Content-Encoding": []string{"zstd"},
+			"Vary":             []string{"Accept-Encoding"}},
 	}, {
 		"drops content length",
 		http.Header{"Content-Length": []string{strconv.Itoa(3 * 8192)}},
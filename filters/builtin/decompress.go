@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
 )
@@ -43,9 +45,21 @@ type decodingError struct {
 
 type decompress struct{}
 
+// brotliDecoder and zstdDecoder make the decoders closable without
+// releasing them, so that they can be returned to the pool
+type brotliDecoder struct {
+	*brotli.Reader
+}
+
+type zstdDecoder struct {
+	*zstd.Decoder
+}
+
 var supportedEncodingsDecompress = map[string]*sync.Pool{
 	"gzip":    {},
 	"deflate": {},
+	"br":      {},
+	"zstd":    {},
 }
 
 func init() {
@@ -63,11 +77,30 @@ func newDecoder(enc string) io.ReadCloser {
 	switch enc {
 	case "gzip":
 		return new(gzip.Reader)
+	case "br":
+		return brotliDecoder{brotli.NewReader(nil)}
+	case "zstd":
+		// with the concurrency of 1, the decoder doesn't start
+		// background goroutines, and it doesn't need to be closed
+		d, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			// only invalid options can cause an error
+			panic(err)
+		}
+
+		return zstdDecoder{d}
 	default:
 		return flate.NewReader(nil)
 	}
 }
 
+func (brotliDecoder) Close() error { return nil }
+
+func (d zstdDecoder) Close() error {
+	// releases the reference to the original body
+	return d.Reset(nil)
+}
+
 func fromPool(enc string) (io.ReadCloser, bool) {
 	d, ok := supportedEncodingsDecompress[enc].Get().(io.ReadCloser)
 	return d, ok
@@ -77,6 +110,10 @@ func reset(decoder, original io.ReadCloser, enc string) error {
 	switch enc {
 	case "gzip":
 		return decoder.(*gzip.Reader).Reset(original)
+	case "br":
+		return decoder.(brotliDecoder).Reset(original)
+	case "zstd":
+		return decoder.(zstdDecoder).Reset(original)
 	default:
 		return decoder.(flate.Resetter).Reset(original, nil)
 	}
@@ -150,7 +187,7 @@ func (e decodingError) Error() string {
 
 // NewDecompress creates a filter specification for the decompress() filter.
 // The filter attempts to decompress the response body, if it was compressed
-// with any of deflate or gzip.
+// with any of deflate, gzip, br or zstd.
 //
 // If decompression is not possible, but the body is compressed, then it indicates it
 // with the "filter::decompress::not-possible" key in the state-bag. If the decompression
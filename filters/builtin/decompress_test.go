@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/proxy/proxytest"
@@ -95,6 +97,10 @@ func compressedBody(t *testing.T, content io.Reader, enc string) io.Reader {
 	switch enc {
 	case "gzip":
 		c = gzip.NewWriter(&b)
+	case "br":
+		c = brotli.NewWriter(&b)
+	case "zstd":
+		c, err = zstd.NewWriter(&b)
 	default:
 		c, err = flate.NewWriter(&b, flate.DefaultCompression)
 	}
@@ -132,7 +138,7 @@ func TestDecompress(t *testing.T) {
 	})
 
 	t.Run("cannot decompress", func(t *testing.T) {
-		b := backend(t, "br", bytes.NewReader([]byte{1, 2, 3}))
+		b := backend(t, "x-custom", bytes.NewReader([]byte{1, 2, 3}))
 		defer b.Close()
 
 		p := decompressingProxy(t, b.URL)
@@ -211,6 +217,29 @@ func TestDecompress(t *testing.T) {
 		}
 	})
 
+	for _, enc := range []string{"br", "zstd"} {
+		t.Run("decompress, "+enc, func(t *testing.T) {
+			b := backend(t, enc, compressedBody(t, bytes.NewBufferString("Hello, world!"), enc))
+			defer b.Close()
+
+			p := decompressingProxy(t, b.URL)
+			defer p.Close()
+
+			status, h, content := request(t, p.URL)
+			if status != http.StatusOK {
+				t.Error(status)
+			}
+
+			if _, has := h["Content-Encoding"]; has {
+				t.Error("Failed to remove Content-Encoding header")
+			}
+
+			if content != "Hello, world!" {
+				t.Error("Failed to return the content unchanged.")
+			}
+		})
+	}
+
 	t.Run("decompress, multiple compressions", func(t *testing.T) {
 		var body io.Reader
 		body = bytes.NewBufferString("Hello, world!")
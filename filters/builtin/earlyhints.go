@@ -0,0 +1,146 @@
+package builtin
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	earlyHintsLearn = "learn"
+
+	// the maximum number of Link headers learned from the backend
+	// responses
+	maxLearnedEarlyHints = 16
+)
+
+type earlyHints struct {
+	links   []string
+	learn   bool
+	learned atomic.Value // []string
+}
+
+// NewEarlyHints creates a filter specification whose filter instances
+// send a 103 Early Hints informational response with Link headers to
+// the client, before the backend request is made. The filter accepts
+// the Link header values as arguments, e.g:
+//
+// 	* -> earlyHints("</style.css>; rel=preload; as=style") -> "https://www.example.org";
+//
+// When the "learn" argument is set, the filter sends additionally the
+// Link headers with rel=preload or rel=preconnect, received with the
+// last successful backend response of the route:
+//
+// 	* -> earlyHints("learn") -> "https://www.example.org";
+//
+func NewEarlyHints() filters.Spec { return &earlyHints{} }
+
+func (*earlyHints) Name() string { return filters.EarlyHintsName }
+
+func (*earlyHints) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &earlyHints{}
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok || s == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if s == earlyHintsLearn {
+			f.learn = true
+			continue
+		}
+
+		f.links = append(f.links, s)
+	}
+
+	f.learned.Store([]string(nil))
+	return f, nil
+}
+
+func (f *earlyHints) hints() []string {
+	learned := f.learned.Load().([]string)
+	if len(learned) == 0 {
+		return f.links
+	}
+
+	links := append([]string(nil), f.links...)
+	for _, l := range learned {
+		if !stringsContain(links, l) {
+			links = append(links, l)
+		}
+	}
+
+	return links
+}
+
+func (f *earlyHints) Request(ctx filters.FilterContext) {
+	// informational responses are not supported by HTTP/1.0
+	if !ctx.Request().ProtoAtLeast(1, 1) {
+		return
+	}
+
+	links := f.hints()
+	if len(links) == 0 {
+		return
+	}
+
+	w := ctx.ResponseWriter()
+	h := w.Header()
+	previous, hadLinks := h["Link"]
+	h["Link"] = links
+	w.WriteHeader(http.StatusEarlyHints)
+
+	// the headers of the response writer are sent also with the final
+	// response, so the hints are removed from them
+	if hadLinks {
+		h["Link"] = previous
+	} else {
+		delete(h, "Link")
+	}
+}
+
+func isHintLink(link string) bool {
+	for _, p := range strings.Split(link, ";")[1:] {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !strings.HasPrefix(p, "rel=") {
+			continue
+		}
+
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimPrefix(p, "rel="), `"`)) {
+			if rel == "preload" || rel == "preconnect" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (f *earlyHints) Response(ctx filters.FilterContext) {
+	if !f.learn {
+		return
+	}
+
+	rsp := ctx.Response()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return
+	}
+
+	var learned []string
+	for _, v := range rsp.Header["Link"] {
+		for _, link := range strings.Split(v, ",") {
+			link = strings.TrimSpace(link)
+			if len(learned) < maxLearnedEarlyHints && isHintLink(link) && !stringsContain(learned, link) {
+				learned = append(learned, link)
+			}
+		}
+	}
+
+	f.learned.Store(learned)
+}
@@ -0,0 +1,121 @@
+package builtin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestEarlyHintsArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{42},
+		{""},
+	} {
+		if _, err := NewEarlyHints().CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
+
+func TestEarlyHints(t *testing.T) {
+	var (
+		mx          sync.Mutex
+		backendLink string
+	)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		defer mx.Unlock()
+		if backendLink != "" {
+			w.Header().Set("Link", backendLink)
+		}
+
+		w.Write([]byte("Hello, world!"))
+	}))
+	defer backend.Close()
+
+	r, err := eskip.Parse(fmt.Sprintf(`
+		static: Path("/static") -> earlyHints("</style.css>; rel=preload; as=style") -> "%s";
+		learn: Path("/learn") -> earlyHints("learn") -> "%s";
+	`, backend.URL, backend.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := proxytest.New(MakeRegistry(), r...)
+	defer p.Close()
+
+	get := func(path string) (hints []string, rsp *http.Response) {
+		trace := &httptrace.ClientTrace{
+			Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+				if code == http.StatusEarlyHints {
+					hints = append(hints, header["Link"]...)
+				}
+
+				return nil
+			},
+		}
+
+		req, err := http.NewRequest("GET", p.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+		rsp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+		if rsp.StatusCode != http.StatusOK {
+			t.Fatalf("invalid status: %d", rsp.StatusCode)
+		}
+
+		return
+	}
+
+	t.Run("configured", func(t *testing.T) {
+		hints, rsp := get("/static")
+		if !reflect.DeepEqual(hints, []string{"</style.css>; rel=preload; as=style"}) {
+			t.Errorf("invalid hints: %v", hints)
+		}
+
+		if l := rsp.Header.Get("Link"); l != "" {
+			t.Errorf("unexpected Link header in the final response: %s", l)
+		}
+	})
+
+	t.Run("learned", func(t *testing.T) {
+		if hints, _ := get("/learn"); len(hints) != 0 {
+			t.Errorf("unexpected hints: %v", hints)
+		}
+
+		mx.Lock()
+		backendLink = `</app.js>; rel=preload; as=script, </next>; rel="next", <https://cdn.example.org>; rel=preconnect`
+		mx.Unlock()
+
+		if hints, _ := get("/learn"); len(hints) != 0 {
+			t.Errorf("unexpected hints: %v", hints)
+		}
+
+		hints, rsp := get("/learn")
+		expected := []string{"</app.js>; rel=preload; as=script", "<https://cdn.example.org>; rel=preconnect"}
+		if !reflect.DeepEqual(hints, expected) {
+			t.Errorf("invalid hints, expected: %v, got: %v", expected, hints)
+		}
+
+		if rsp.Header.Get("Link") != backendLink {
+			t.Errorf("invalid Link header in the final response: %s", rsp.Header.Get("Link"))
+		}
+	})
+}
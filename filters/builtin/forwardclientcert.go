@@ -0,0 +1,124 @@
+package builtin
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net/url"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+	snet "github.com/zalando/skipper/net"
+)
+
+const forwardedClientCertHeader = "X-Forwarded-Client-Cert"
+
+const (
+	clientCertHash    = "Hash"
+	clientCertCert    = "Cert"
+	clientCertChain   = "Chain"
+	clientCertSubject = "Subject"
+	clientCertURI     = "URI"
+	clientCertDNS     = "DNS"
+)
+
+var defaultClientCertFields = []string{clientCertHash, clientCertSubject, clientCertURI, clientCertDNS}
+
+type forwardClientCert struct {
+	fields []string
+}
+
+// NewForwardClientCert creates a filter specification whose filter
+// instances forward the identity of the verified TLS client
+// certificate to the backend, in the X-Forwarded-Client-Cert header,
+// in the format used by Envoy:
+//
+// 	X-Forwarded-Client-Cert: Hash=<sha256>;Subject="CN=client";URI=spiffe://example.org/client
+//
+// The header received from the client is always removed, to prevent
+// spoofing. The filter accepts the fields of the header as arguments,
+// from Hash, Cert, Chain, Subject, URI and DNS. When no arguments are
+// set, it sends Hash, Subject, URI and DNS:
+//
+// 	* -> forwardClientCert("Subject", "URI") -> "https://www.example.org";
+//
+func NewForwardClientCert() filters.Spec { return &forwardClientCert{} }
+
+func (*forwardClientCert) Name() string { return filters.ForwardClientCertName }
+
+func (*forwardClientCert) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return &forwardClientCert{fields: defaultClientCertFields}, nil
+	}
+
+	f := &forwardClientCert{}
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		switch s {
+		case clientCertHash, clientCertCert, clientCertChain, clientCertSubject, clientCertURI, clientCertDNS:
+			f.fields = append(f.fields, s)
+		default:
+			return nil, filters.ErrInvalidFilterParameters
+		}
+	}
+
+	return f, nil
+}
+
+func quoteClientCertValue(v string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(v, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+func encodeClientCert(c *x509.Certificate) string {
+	return url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})))
+}
+
+func (f *forwardClientCert) header(chain []*x509.Certificate) string {
+	c := chain[0]
+	var elements []string
+	for _, field := range f.fields {
+		switch field {
+		case clientCertHash:
+			h := sha256.Sum256(c.Raw)
+			elements = append(elements, clientCertHash+"="+hex.EncodeToString(h[:]))
+		case clientCertCert:
+			elements = append(elements, clientCertCert+"="+quoteClientCertValue(encodeClientCert(c)))
+		case clientCertChain:
+			var encoded strings.Builder
+			for _, ci := range chain {
+				encoded.WriteString(encodeClientCert(ci))
+			}
+
+			elements = append(elements, clientCertChain+"="+quoteClientCertValue(encoded.String()))
+		case clientCertSubject:
+			elements = append(elements, clientCertSubject+"="+quoteClientCertValue(c.Subject.String()))
+		case clientCertURI:
+			for _, uri := range c.URIs {
+				elements = append(elements, clientCertURI+"="+uri.String())
+			}
+		case clientCertDNS:
+			for _, name := range c.DNSNames {
+				elements = append(elements, clientCertDNS+"="+name)
+			}
+		}
+	}
+
+	return strings.Join(elements, ";")
+}
+
+func (f *forwardClientCert) Request(ctx filters.FilterContext) {
+	r := ctx.Request()
+	r.Header.Del(forwardedClientCertHeader)
+	if snet.VerifiedClientCertificate(r) == nil {
+		return
+	}
+
+	r.Header.Set(forwardedClientCertHeader, f.header(r.TLS.VerifiedChains[0]))
+}
+
+func (*forwardClientCert) Response(filters.FilterContext) {}
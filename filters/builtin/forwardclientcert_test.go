@@ -0,0 +1,152 @@
+package builtin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func testClientCert(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.org/orders")
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client", Organization: []string{`Example "Inc"`}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"orders.example.org", "orders"},
+		URIs:         []*url.URL{spiffe},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestForwardClientCertArgs(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		args  []interface{}
+		fail  bool
+	}{{
+		title: "no args",
+	}, {
+		title: "fields",
+		args:  []interface{}{"Hash", "Cert", "Chain", "Subject", "URI", "DNS"},
+	}, {
+		title: "invalid field",
+		args:  []interface{}{"Hash", "Issuer"},
+		fail:  true,
+	}, {
+		title: "invalid type",
+		args:  []interface{}{float64(1)},
+		fail:  true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			_, err := NewForwardClientCert().CreateFilter(test.args)
+			if test.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !test.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestForwardClientCert(t *testing.T) {
+	c := testClientCert(t)
+	hash := sha256.Sum256(c.Raw)
+	encoded := url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})))
+	verified := &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{c},
+		VerifiedChains:   [][]*x509.Certificate{{c}},
+	}
+
+	for _, test := range []struct {
+		title    string
+		args     []interface{}
+		state    *tls.ConnectionState
+		incoming string
+		expected string
+	}{{
+		title:    "no TLS, spoofed header removed",
+		incoming: "Subject=\"CN=admin\"",
+	}, {
+		title:    "not verified",
+		state:    &tls.ConnectionState{PeerCertificates: []*x509.Certificate{c}},
+		incoming: "Subject=\"CN=admin\"",
+	}, {
+		title: "default fields",
+		state: verified,
+		expected: "Hash=" + hex.EncodeToString(hash[:]) +
+			`;Subject="CN=client,O=Example \\\"Inc\\\""` +
+			";URI=spiffe://example.org/orders;DNS=orders.example.org;DNS=orders",
+	}, {
+		title:    "selected fields, replacing incoming",
+		args:     []interface{}{"URI", "Cert"},
+		state:    verified,
+		incoming: "Subject=\"CN=admin\"",
+		expected: `URI=spiffe://example.org/orders;Cert="` + encoded + `"`,
+	}, {
+		title:    "chain",
+		args:     []interface{}{"Chain"},
+		state:    verified,
+		expected: `Chain="` + encoded + `"`,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			f, err := NewForwardClientCert().CreateFilter(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := http.NewRequest("GET", "https://www.example.org", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r.TLS = test.state
+			if test.incoming != "" {
+				r.Header.Set("X-Forwarded-Client-Cert", test.incoming)
+			}
+
+			f.Request(&filtertest.Context{FRequest: r})
+			if h := r.Header.Get("X-Forwarded-Client-Cert"); h != test.expected {
+				t.Errorf("invalid header, expected: %s, got: %s", test.expected, h)
+			}
+
+			if test.expected == "" && len(r.Header.Values("X-Forwarded-Client-Cert")) != 0 {
+				t.Error("failed to remove the header")
+			}
+
+			if strings.Contains(r.Header.Get("X-Forwarded-Client-Cert"), "\n") {
+				t.Error("header contains a newline")
+			}
+		})
+	}
+}
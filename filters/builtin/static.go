@@ -1,19 +1,62 @@
 package builtin
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/filters/serve"
 )
 
+const (
+	staticPrecompressed = "precompressed"
+	staticSPA           = "spa"
+	staticETag          = "etag"
+	staticCacheControl  = "cacheControl:"
+)
+
 type static struct {
 	handler http.Handler
 }
 
+type staticHandler struct {
+	root          string
+	fileServer    http.Handler
+	precompressed bool
+	spa           bool
+	etag          bool
+
+	// file extension to Cache-Control value, "*" is the default
+	cacheControl map[string]string
+
+	// file path to etagEntry
+	etags sync.Map
+}
+
+type etagEntry struct {
+	modTime time.Time
+	size    int64
+	etag    string
+}
+
+// the file extensions of the pre-compressed files
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"zstd": ".zst",
+	"gzip": ".gz",
+}
+
 // Returns a filter Spec to serve static content from a file system
 // location. Behaves similarly to net/http.FileServer. It shunts the route.
 //
@@ -23,6 +66,26 @@ type static struct {
 // rest of the path to the directory path. Then, it uses the resulting
 // path to serve static content from the file system.
 //
+// Optionally, the following options can be passed in as additional
+// string parameters:
+//
+// 	"precompressed": serves the sibling .br, .zst or .gz files, when
+// 	they exist and the client accepts the encoding
+//
+// 	"spa": serves /index.html of the directory path, when the
+// 	requested file does not exist
+//
+// 	"etag": sets strong ETags, computed from the content of the files
+//
+// 	"cacheControl:<extension>:<value>": sets the Cache-Control header
+// 	for the files with the extension, where * means any extension
+//
+// Eskip example:
+//
+// 	* -> static("/", "/srv/www", "precompressed", "spa", "etag",
+// 		"cacheControl:.html:no-cache",
+// 		"cacheControl:*:public, max-age=31536000") -> <shunt>;
+//
 // Name: "static".
 func NewStatic() filters.Spec { return &static{} }
 
@@ -30,12 +93,12 @@ func NewStatic() filters.Spec { return &static{} }
 func (spec *static) Name() string { return filters.StaticName }
 
 // Creates instances of the static filter. Expects two parameters: request path
-// prefix and file system root.
+// prefix and file system root, and optionally the options.
 //
 //lint:ignore ST1016 "spec" makes sense here and we reuse the type for the filter
 func (spec *static) CreateFilter(config []interface{}) (filters.Filter, error) {
-	if len(config) != 2 {
-		return nil, fmt.Errorf("invalid number of args: %d, expected 2", len(config))
+	if len(config) < 2 {
+		return nil, fmt.Errorf("invalid number of args: %d, expected at least 2", len(config))
 	}
 
 	webRoot, ok := config[0].(string)
@@ -54,7 +117,43 @@ func (spec *static) CreateFilter(config []interface{}) (filters.Filter, error) {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
-	return &static{http.StripPrefix(webRoot, http.FileServer(http.Dir(root)))}, nil
+	fileServer := http.FileServer(http.Dir(root))
+	if len(config) == 2 {
+		return &static{http.StripPrefix(webRoot, fileServer)}, nil
+	}
+
+	h := &staticHandler{
+		root:         root,
+		fileServer:   fileServer,
+		cacheControl: make(map[string]string),
+	}
+
+	for _, c := range config[2:] {
+		o, ok := c.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		switch {
+		case o == staticPrecompressed:
+			h.precompressed = true
+		case o == staticSPA:
+			h.spa = true
+		case o == staticETag:
+			h.etag = true
+		case strings.HasPrefix(o, staticCacheControl):
+			extValue := strings.SplitN(strings.TrimPrefix(o, staticCacheControl), ":", 2)
+			if len(extValue) != 2 || extValue[0] == "" || extValue[1] == "" {
+				return nil, fmt.Errorf("invalid cache control option: %s", o)
+			}
+
+			h.cacheControl[extValue[0]] = strings.TrimSpace(extValue[1])
+		default:
+			return nil, fmt.Errorf("invalid static option: %s", o)
+		}
+	}
+
+	return &static{http.StripPrefix(webRoot, h)}, nil
 }
 
 // Serves content from the file system and marks the request served.
@@ -72,3 +171,146 @@ func existsAndAccessible(path string) (bool, error) {
 	}
 	return true, nil
 }
+
+func (h *staticHandler) filePath(name string) string {
+	return filepath.Join(h.root, filepath.FromSlash(name))
+}
+
+func (h *staticHandler) stat(name string) (os.FileInfo, bool) {
+	fi, err := os.Stat(h.filePath(name))
+	if err != nil {
+		return nil, false
+	}
+
+	return fi, true
+}
+
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + r.URL.Path)
+	fi, ok := h.stat(name)
+	switch {
+	case ok && fi.IsDir():
+		// the index of the directory is served by the handler, only
+		// when the request path points to the directory, otherwise the
+		// file server redirects
+		index := path.Join(name, "index.html")
+		if ifi, ok := h.stat(index); ok && !ifi.IsDir() && strings.HasSuffix(r.URL.Path, "/") {
+			name, fi = index, ifi
+			break
+		}
+
+		h.fileServer.ServeHTTP(w, r)
+		return
+	case ok && strings.HasSuffix(r.URL.Path, "/index.html"):
+		// the file server redirects to the directory
+		h.fileServer.ServeHTTP(w, r)
+		return
+	case !ok && h.spa && (r.Method == "GET" || r.Method == "HEAD"):
+		ifi, ok := h.stat("/index.html")
+		if !ok || ifi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		name, fi = "/index.html", ifi
+	case !ok:
+		http.NotFound(w, r)
+		return
+	}
+
+	h.serveFile(w, r, name, fi)
+}
+
+func (h *staticHandler) serveFile(w http.ResponseWriter, r *http.Request, name string, fi os.FileInfo) {
+	ext := path.Ext(name)
+	header := w.Header()
+	if cc, ok := h.cacheControl[ext]; ok {
+		header.Set("Cache-Control", cc)
+	} else if cc, ok := h.cacheControl["*"]; ok {
+		header.Set("Cache-Control", cc)
+	}
+
+	servedName, servedInfo := name, fi
+	if h.precompressed {
+		header.Add("Vary", "Accept-Encoding")
+		for _, enc := range acceptedEncodings(r) {
+			cext, ok := precompressedExtensions[enc]
+			if !ok {
+				continue
+			}
+
+			if cfi, ok := h.stat(name + cext); ok && !cfi.IsDir() {
+				servedName, servedInfo = name+cext, cfi
+				header.Set("Content-Encoding", enc)
+				break
+			}
+		}
+	}
+
+	f, err := os.Open(h.filePath(servedName))
+	if err != nil {
+		log.Errorf("Failed to open static file %s: %v", servedName, err)
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	defer f.Close()
+
+	// the content type is detected from the original file, because
+	// http.ServeContent would sniff the compressed content
+	if servedName != name {
+		ctype := mime.TypeByExtension(ext)
+		if ctype == "" {
+			ctype = sniffContentType(h.filePath(name))
+		}
+
+		header.Set("Content-Type", ctype)
+	}
+
+	if h.etag {
+		etag, err := h.getETag(servedName, servedInfo, f)
+		if err != nil {
+			log.Errorf("Failed to compute ETag for static file %s: %v", servedName, err)
+		} else {
+			header.Set("Etag", etag)
+		}
+	}
+
+	http.ServeContent(w, r, name, servedInfo.ModTime(), f)
+}
+
+func sniffContentType(name string) string {
+	f, err := os.Open(name)
+	if err != nil {
+		return "application/octet-stream"
+	}
+
+	defer f.Close()
+	var b [512]byte
+	n, _ := io.ReadFull(f, b[:])
+	return http.DetectContentType(b[:n])
+}
+
+// getETag returns the cached ETag of the file, or, when the file was
+// changed, computes it from its content.
+func (h *staticHandler) getETag(name string, fi os.FileInfo, f io.ReadSeeker) (string, error) {
+	if e, ok := h.etags.Load(name); ok {
+		ee := e.(etagEntry)
+		if ee.modTime.Equal(fi.ModTime()) && ee.size == fi.Size() {
+			return ee.etag, nil
+		}
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(name, etagEntry{modTime: fi.ModTime(), size: fi.Size(), etag: etag})
+	return etag, nil
+}
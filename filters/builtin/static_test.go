@@ -20,7 +20,10 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
@@ -228,3 +231,225 @@ func TestMultipleRanges(t *testing.T) {
 		t.Error("failed to receive all ranges")
 	}
 }
+
+func TestStaticOptions(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"index.html":   "<html>index</html>",
+		"app.js":       "console.log('app')",
+		"app.js.br":    "brotli app",
+		"app.js.gz":    "gzip app",
+		"data":         "<html>data</html>",
+		"data.zst":     "zstd data",
+		"sub/page.txt": "page",
+	} {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ti := range []struct {
+		msg                     string
+		options                 []interface{}
+		path                    string
+		acceptEncoding          string
+		expectedStatus          int
+		expectedContent         string
+		expectedContentType     string
+		expectedContentEncoding string
+		expectedCacheControl    string
+	}{{
+		msg:                 "no options",
+		path:                "/static/app.js",
+		acceptEncoding:      "br",
+		expectedStatus:      http.StatusOK,
+		expectedContent:     "console.log('app')",
+		expectedContentType: mime.TypeByExtension(".js"),
+	}, {
+		msg:                     "precompressed, brotli preferred",
+		options:                 []interface{}{"precompressed"},
+		path:                    "/static/app.js",
+		acceptEncoding:          "gzip;q=0.8, br",
+		expectedStatus:          http.StatusOK,
+		expectedContent:         "brotli app",
+		expectedContentType:     mime.TypeByExtension(".js"),
+		expectedContentEncoding: "br",
+	}, {
+		msg:                     "precompressed, gzip on equal q-values",
+		options:                 []interface{}{"precompressed"},
+		path:                    "/static/app.js",
+		acceptEncoding:          "br, gzip",
+		expectedStatus:          http.StatusOK,
+		expectedContent:         "gzip app",
+		expectedContentType:     mime.TypeByExtension(".js"),
+		expectedContentEncoding: "gzip",
+	}, {
+		msg:                 "precompressed, not accepted",
+		options:             []interface{}{"precompressed"},
+		path:                "/static/app.js",
+		acceptEncoding:      "br;q=0, deflate",
+		expectedStatus:      http.StatusOK,
+		expectedContent:     "console.log('app')",
+		expectedContentType: mime.TypeByExtension(".js"),
+	}, {
+		msg:                     "precompressed, content type sniffed from the original",
+		options:                 []interface{}{"precompressed"},
+		path:                    "/static/data",
+		acceptEncoding:          "zstd",
+		expectedStatus:          http.StatusOK,
+		expectedContent:         "zstd data",
+		expectedContentType:     "text/html; charset=utf-8",
+		expectedContentEncoding: "zstd",
+	}, {
+		msg:            "not found without spa",
+		options:        []interface{}{"precompressed"},
+		path:           "/static/app/settings",
+		expectedStatus: http.StatusNotFound,
+	}, {
+		msg:                  "spa fallback",
+		options:              []interface{}{"spa", "cacheControl:.html:no-cache"},
+		path:                 "/static/app/settings",
+		expectedStatus:       http.StatusOK,
+		expectedContent:      "<html>index</html>",
+		expectedContentType:  "text/html; charset=utf-8",
+		expectedCacheControl: "no-cache",
+	}, {
+		msg:                  "directory index",
+		options:              []interface{}{"spa", "cacheControl:.html:no-cache"},
+		path:                 "/static/",
+		expectedStatus:       http.StatusOK,
+		expectedContent:      "<html>index</html>",
+		expectedContentType:  "text/html; charset=utf-8",
+		expectedCacheControl: "no-cache",
+	}, {
+		msg:                  "existing file with spa, default cache control",
+		options:              []interface{}{"spa", "cacheControl:.html:no-cache", "cacheControl:*:public, max-age=3600"},
+		path:                 "/static/sub/page.txt",
+		expectedStatus:       http.StatusOK,
+		expectedContent:      "page",
+		expectedContentType:  "text/plain; charset=utf-8",
+		expectedCacheControl: "public, max-age=3600",
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			fr := make(filters.Registry)
+			fr.Register(NewStatic())
+			pr := proxytest.New(fr, &eskip.Route{
+				Filters: []*eskip.Filter{{Name: filters.StaticName, Args: append([]interface{}{"/static", root}, ti.options...)}},
+				Shunt:   true})
+			defer pr.Close()
+
+			req, err := http.NewRequest("GET", pr.URL+ti.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Accept-Encoding", ti.acceptEncoding)
+			rsp, err := http.DefaultTransport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != ti.expectedStatus {
+				t.Fatalf("invalid status, expected: %d, got: %d", ti.expectedStatus, rsp.StatusCode)
+			}
+
+			if rsp.StatusCode != http.StatusOK {
+				return
+			}
+
+			content, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != ti.expectedContent {
+				t.Errorf("invalid content, expected: %s, got: %s", ti.expectedContent, string(content))
+			}
+
+			for _, h := range []struct{ name, expected string }{
+				{"Content-Type", ti.expectedContentType},
+				{"Content-Encoding", ti.expectedContentEncoding},
+				{"Cache-Control", ti.expectedCacheControl},
+			} {
+				if got := rsp.Header.Get(h.name); got != h.expected {
+					t.Errorf("invalid %s, expected: %q, got: %q", h.name, h.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStaticETag(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "app.js")
+	if err := os.WriteFile(file, []byte("console.log('app')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fr := make(filters.Registry)
+	fr.Register(NewStatic())
+	pr := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.StaticName, Args: []interface{}{"/", root, "etag"}}},
+		Shunt:   true})
+	defer pr.Close()
+
+	get := func(ifNoneMatch string) *http.Response {
+		req, err := http.NewRequest("GET", pr.URL+"/app.js", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+		return rsp
+	}
+
+	etag := get("").Header.Get("Etag")
+	if !strings.HasPrefix(etag, `"`) || len(etag) < 3 {
+		t.Fatalf("invalid strong etag: %s", etag)
+	}
+
+	if rsp := get(etag); rsp.StatusCode != http.StatusNotModified {
+		t.Errorf("failed to respond with not modified, got: %d", rsp.StatusCode)
+	}
+
+	if err := os.WriteFile(file, []byte("console.log('changed')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the modification time may not change on fast file systems
+	if err := os.Chtimes(file, time.Now(), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	rsp := get(etag)
+	if rsp.StatusCode != http.StatusOK {
+		t.Errorf("failed to respond with the changed content, got: %d", rsp.StatusCode)
+	}
+
+	if rsp.Header.Get("Etag") == etag {
+		t.Error("failed to update the etag")
+	}
+}
+
+func TestStaticInvalidOptions(t *testing.T) {
+	for _, o := range []interface{}{"foo", 42, "cacheControl:.html", "cacheControl::no-cache"} {
+		if _, err := NewStatic().CreateFilter([]interface{}{"/", "/tmp", o}); err == nil {
+			t.Errorf("failed to fail for option: %v", o)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package diag
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+	snet "github.com/zalando/skipper/net"
+)
+
+type faultType int
+
+const (
+	abortFault faultType = iota
+	latencyFault
+	resetFault
+	truncateFault
+)
+
+type faultCondition struct {
+	cookie bool
+	name   string
+	value  string
+}
+
+type faultInjection struct {
+	percentage float64
+	condition  *faultCondition
+	typ        faultType
+	status     int
+	latency    *jitter
+	truncateAt int64
+}
+
+var errFaultTruncated = errors.New("response body truncated by fault injection")
+
+// NewFaultInjection creates a filter specification whose filter instances
+// inject faults into a configurable percentage of the requests, optionally
+// only into the requests having a header or a cookie. The first optional
+// argument is the condition in the format of "header:<name>[=<value>]" or
+// "cookie:<name>[=<value>]", followed by the percentage of the requests
+// (0-100), the type of the fault and its arguments:
+//
+// 	"abort", <status code>
+// 	"latency", "uniform"|"normal", <mean>, <delta>
+// 	"reset"
+// 	"truncate", <bytes>
+//
+// Eskip examples:
+//
+// 	* -> faultInjection(5, "abort", 503) -> "https://www.example.org";
+// 	* -> faultInjection("header:X-Chaos", 50, "latency", "normal", "1s", "200ms") -> "https://www.example.org";
+// 	* -> faultInjection("cookie:chaos=on", 10, "truncate", 1024) -> "https://www.example.org";
+//
+func NewFaultInjection() filters.Spec { return &faultInjection{} }
+
+func (*faultInjection) Name() string { return filters.FaultInjectionName }
+
+func parseFaultCondition(s string) (*faultCondition, bool) {
+	var c faultCondition
+	switch {
+	case strings.HasPrefix(s, "header:"):
+		s = strings.TrimPrefix(s, "header:")
+	case strings.HasPrefix(s, "cookie:"):
+		c.cookie = true
+		s = strings.TrimPrefix(s, "cookie:")
+	default:
+		return nil, false
+	}
+
+	c.name = s
+	if i := strings.Index(s, "="); i >= 0 {
+		c.name, c.value = s[:i], s[i+1:]
+	}
+
+	if c.name == "" {
+		return nil, false
+	}
+
+	return &c, true
+}
+
+func (*faultInjection) CreateFilter(args []interface{}) (filters.Filter, error) {
+	var f faultInjection
+	if len(args) > 0 {
+		if s, ok := args[0].(string); ok {
+			c, ok := parseFaultCondition(s)
+			if !ok {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			f.condition = c
+			args = args[1:]
+		}
+	}
+
+	if len(args) < 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	p, ok := args[0].(float64)
+	if !ok || p < 0 || p > 100 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f.percentage = p
+
+	fault, ok := args[1].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	args = args[2:]
+	switch fault {
+	case "abort":
+		if len(args) != 1 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		status, ok := args[0].(float64)
+		if !ok || status < 100 || status > 599 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.typ = abortFault
+		f.status = int(status)
+	case "latency":
+		if len(args) != 3 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		var j *jitter
+		switch args[0] {
+		case "uniform":
+			j = &jitter{typ: uniformRequestDistribution}
+		case "normal":
+			j = &jitter{typ: normalRequestDistribution}
+		default:
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		jf, err := j.CreateFilter(args[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		f.typ = latencyFault
+		f.latency = jf.(*jitter)
+	case "reset":
+		if len(args) != 0 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.typ = resetFault
+	case "truncate":
+		if len(args) != 1 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		n, ok := args[0].(float64)
+		if !ok || n < 0 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.typ = truncateFault
+		f.truncateAt = int64(n)
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &f, nil
+}
+
+func (c *faultCondition) match(r *http.Request) bool {
+	if c.cookie {
+		cookie, err := r.Cookie(c.name)
+		return err == nil && (c.value == "" || cookie.Value == c.value)
+	}
+
+	v, ok := r.Header[http.CanonicalHeaderKey(c.name)]
+	if !ok {
+		return false
+	}
+
+	if c.value == "" {
+		return true
+	}
+
+	for _, vi := range v {
+		if vi == c.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *faultInjection) apply(r *http.Request) bool {
+	if f.condition != nil && !f.condition.match(r) {
+		return false
+	}
+
+	/* #nosec */
+	return rand.Float64()*100 < f.percentage
+}
+
+func (f *faultInjection) Request(ctx filters.FilterContext) {
+	if f.typ == truncateFault || !f.apply(ctx.Request()) {
+		return
+	}
+
+	switch f.typ {
+	case abortFault:
+		ctx.Serve(&http.Response{StatusCode: f.status})
+	case latencyFault:
+		f.latency.Request(ctx)
+	case resetFault:
+		if err := snet.ResetConnection(ctx.ResponseWriter()); err != nil {
+			log.Errorf("Failed to inject connection reset: %v", err)
+			return
+		}
+
+		ctx.Serve(&http.Response{StatusCode: http.StatusServiceUnavailable})
+	}
+}
+
+func (f *faultInjection) Response(ctx filters.FilterContext) {
+	if f.typ != truncateFault || !f.apply(ctx.Request()) {
+		return
+	}
+
+	rsp := ctx.Response()
+	if rsp.Body == nil {
+		return
+	}
+
+	rsp.Body = snet.NewTruncatedBody(rsp.Body, f.truncateAt, ctx.ResponseWriter(), errFaultTruncated, nil)
+}
@@ -0,0 +1,174 @@
+package diag
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestFaultInjectionArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		fail bool
+	}{{
+		name: "no args",
+		fail: true,
+	}, {
+		name: "abort",
+		args: []interface{}{5.0, "abort", 503.0},
+	}, {
+		name: "abort with invalid status",
+		args: []interface{}{5.0, "abort", 42.0},
+		fail: true,
+	}, {
+		name: "invalid percentage",
+		args: []interface{}{101.0, "abort", 503.0},
+		fail: true,
+	}, {
+		name: "latency",
+		args: []interface{}{5.0, "latency", "normal", "1s", "100ms"},
+	}, {
+		name: "latency with invalid distribution",
+		args: []interface{}{5.0, "latency", "poisson", "1s", "100ms"},
+		fail: true,
+	}, {
+		name: "reset with header condition",
+		args: []interface{}{"header:X-Chaos", 5.0, "reset"},
+	}, {
+		name: "reset with invalid condition",
+		args: []interface{}{"query:chaos", 5.0, "reset"},
+		fail: true,
+	}, {
+		name: "truncate with cookie condition",
+		args: []interface{}{"cookie:chaos=on", 5.0, "truncate", 1024.0},
+	}, {
+		name: "truncate without size",
+		args: []interface{}{5.0, "truncate"},
+		fail: true,
+	}, {
+		name: "unknown fault",
+		args: []interface{}{5.0, "explode"},
+		fail: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFaultInjection().CreateFilter(tt.args)
+			if tt.fail && err == nil {
+				t.Error("Failed to fail")
+			} else if !tt.fail && err != nil {
+				t.Errorf("Failed to create filter: %v", err)
+			}
+		})
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 4096)))
+	}))
+	defer backend.Close()
+
+	for _, tt := range []struct {
+		name       string
+		args       []interface{}
+		header     http.Header
+		wantStatus int
+		wantBody   int
+		wantErr    bool
+		minLatency time.Duration
+	}{{
+		name:       "never injected",
+		args:       []interface{}{0.0, "abort", 503.0},
+		wantStatus: http.StatusOK,
+		wantBody:   4096,
+	}, {
+		name:       "abort",
+		args:       []interface{}{100.0, "abort", 418.0},
+		wantStatus: http.StatusTeapot,
+	}, {
+		name:       "abort without matching header",
+		args:       []interface{}{"header:X-Chaos=on", 100.0, "abort", 418.0},
+		header:     http.Header{"X-Chaos": []string{"off"}},
+		wantStatus: http.StatusOK,
+		wantBody:   4096,
+	}, {
+		name:       "abort with matching header",
+		args:       []interface{}{"header:X-Chaos=on", 100.0, "abort", 418.0},
+		header:     http.Header{"X-Chaos": []string{"on"}},
+		wantStatus: http.StatusTeapot,
+	}, {
+		name:       "abort with matching cookie",
+		args:       []interface{}{"cookie:chaos", 100.0, "abort", 418.0},
+		header:     http.Header{"Cookie": []string{"chaos=1"}},
+		wantStatus: http.StatusTeapot,
+	}, {
+		name:       "latency",
+		args:       []interface{}{100.0, "latency", "uniform", "100ms", "10ms"},
+		wantStatus: http.StatusOK,
+		wantBody:   4096,
+		minLatency: 90 * time.Millisecond,
+	}, {
+		name:    "reset",
+		args:    []interface{}{100.0, "reset"},
+		wantErr: true,
+	}, {
+		name:       "truncate",
+		args:       []interface{}{100.0, "truncate", 1024.0},
+		wantStatus: http.StatusOK,
+		wantBody:   1024,
+		wantErr:    true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			fr := make(filters.Registry)
+			fr.Register(NewFaultInjection())
+			p := proxytest.New(fr, &eskip.Route{
+				Filters: []*eskip.Filter{{Name: filters.FaultInjectionName, Args: tt.args}},
+				Backend: backend.URL,
+			})
+			defer p.Close()
+
+			req, err := http.NewRequest("GET", p.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header = tt.header
+			start := time.Now()
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != tt.wantStatus {
+				t.Errorf("Failed to get status, got: %d, want: %d", rsp.StatusCode, tt.wantStatus)
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil && !tt.wantErr {
+				t.Fatal(err)
+			} else if err == nil && tt.wantErr {
+				t.Error("Failed to get error")
+			}
+
+			if tt.wantBody > 0 && len(b) != tt.wantBody {
+				t.Errorf("Failed to get body, got: %d bytes, want: %d bytes", len(b), tt.wantBody)
+			}
+
+			if d := time.Since(start); d < tt.minLatency {
+				t.Errorf("Failed to inject latency, got: %v, want at least: %v", d, tt.minLatency)
+			}
+		})
+	}
+}
@@ -26,6 +26,14 @@ const (
 
 	// BackendRatelimit is the key used in the state bag to configure backend ratelimit in proxy
 	BackendRatelimit = "backend:ratelimit"
+
+	// BackendTLSProfile is the key used in the state bag to select a named
+	// backend TLS profile in proxy
+	BackendTLSProfile = "backend:tls:profile"
+
+	// BackendQueueTime is the key used in the state bag to pass the time a request spent in
+	// scheduler queues to the proxy, which subtracts it from the backend timeout
+	BackendQueueTime = "backend:queue:time"
 )
 
 // Context object providing state and information that is unique to a request.
@@ -208,6 +216,12 @@ const (
 	RandomContentName                          = "randomContent"
 	RepeatContentName                          = "repeatContent"
 	BackendTimeoutName                         = "backendTimeout"
+	RequestBodyLimitName                       = "requestBodyLimit"
+	ResponseBodyLimitName                      = "responseBodyLimit"
+	BufferRequestName                          = "bufferRequest"
+	EarlyHintsName                             = "earlyHints"
+	ForwardClientCertName                      = "forwardClientCert"
+	BackendTLSName                             = "backendTLS"
 	LatencyName                                = "latency"
 	BandwidthName                              = "bandwidth"
 	ChunksName                                 = "chunks"
@@ -220,10 +234,12 @@ const (
 	NormalRequestLatencyName                   = "normalRequestLatency"
 	UniformResponseLatencyName                 = "uniformResponseLatency"
 	NormalResponseLatencyName                  = "normalResponseLatency"
+	FaultInjectionName                         = "faultInjection"
 	LogHeaderName                              = "logHeader"
 	TeeName                                    = "tee"
 	TeenfName                                  = "teenf"
 	TeeLoopbackName                            = "teeLoopback"
+	MirrorName                                 = "mirror"
 	SedName                                    = "sed"
 	SedDelimName                               = "sedDelim"
 	SedRequestName                             = "sedRequest"
@@ -281,6 +297,8 @@ const (
 	ApiUsageMonitoringName                     = "apiUsageMonitoring"
 	LifoName                                   = "lifo"
 	LifoGroupName                              = "lifoGroup"
+	FifoName                                   = "fifo"
+	FifoGroupName                              = "fifoGroup"
 	RfcPathName                                = "rfcPath"
 	RfcHostName                                = "rfcHost"
 	BearerInjectorName                         = "bearerinjector"
@@ -9,7 +9,8 @@
 // problem.
 //
 // The scheduler filter package has two implementations of bounded
-// queue, the lifo and lifoGroup filter. Both lifo filters will, use a
+// queue, the lifo and lifoGroup filter, and the fifo and fifoGroup
+// filters, that keep the order of the requests. Both lifo filters will, use a
 // last in first out queue to handle most requests fast and if skipper
 // is in an overrun mode, it will serve some requests fast and some
 // will timeout.  This scheduler implementation makes sure that one
@@ -33,5 +34,6 @@
 //
 //   - 502, if it can not get a request from data structure fast enough
 //   - 503, if the data structure is full and reached its boundary
+//   - 504, if the backend timeout passed while waiting in a fifo queue
 //
 package scheduler
@@ -0,0 +1,262 @@
+package scheduler
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/scheduler"
+)
+
+type (
+	fifoSpec      struct{}
+	fifoGroupSpec struct{}
+
+	fifoFilter struct {
+		config scheduler.Config
+		queue  *scheduler.FifoQueue
+	}
+
+	fifoGroupFilter struct {
+		name      string
+		hasConfig bool
+		config    scheduler.Config
+		queue     *scheduler.FifoQueue
+	}
+)
+
+func NewFifo() filters.Spec {
+	return &fifoSpec{}
+}
+
+func NewFifoGroup() filters.Spec {
+	return &fifoGroupSpec{}
+}
+
+func (*fifoSpec) Name() string { return filters.FifoName }
+
+// CreateFilter creates a fifoFilter, that will use a bounded first in
+// first out queue for handling requests. The first parameter is
+// MaxConcurrency the second MaxQueueSize and the third Timeout.
+//
+// All parameters are optional and defaults to
+// MaxConcurrency 100, MaxQueueSize 100, Timeout 10s.
+//
+// The total maximum number of requests has to be computed by adding
+// MaxConcurrency and MaxQueueSize: total max = MaxConcurrency + MaxQueueSize
+//
+// Min values are 1 for MaxConcurrency and MaxQueueSize, and 1ms for
+// Timeout. All configration that is below will be set to these min
+// values.
+//
+// The time a request spends in the queue is subtracted from the
+// backend timeout set by the backendTimeout filter, and requests,
+// whose backend timeout has already passed after being scheduled,
+// are rejected before they are forwarded.
+func (*fifoSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) > 3 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	c, err := fifoConfig(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fifoFilter{config: c}, nil
+}
+
+func (*fifoGroupSpec) Name() string { return filters.FifoGroupName }
+
+// CreateFilter creates a fifoGroupFilter, that will use a bounded
+// first in first out queue for handling requests, shared by all the
+// routes with the same group name. The first parameter is the Name,
+// the second MaxConcurrency, the third MaxQueueSize and the fourth
+// Timeout.
+//
+// The Name parameter is used to group the queue by one or multiple
+// routes. All other parameters are optional and defaults to
+// MaxConcurrency 100, MaxQueueSize 100, Timeout 10s. It is enough to
+// set them for one instance of the filter in the group. If the
+// configuration for the same Name is different, a warning will be
+// logged.
+func (*fifoGroupSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 1 || len(args) > 4 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	name, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	c, err := fifoConfig(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &fifoGroupFilter{
+		name:      name,
+		hasConfig: len(args) > 1,
+		config:    c,
+	}, nil
+}
+
+func fifoConfig(args []interface{}) (scheduler.Config, error) {
+	c := scheduler.Config{
+		MaxConcurrency: defaultMaxConcurreny,
+		MaxQueueSize:   defaultMaxQueueSize,
+		Timeout:        defaultTimeout,
+	}
+
+	if len(args) > 0 {
+		v, err := intArg(args[0])
+		if err != nil {
+			return c, err
+		}
+		if v >= 1 {
+			c.MaxConcurrency = v
+		}
+	}
+
+	if len(args) > 1 {
+		v, err := intArg(args[1])
+		if err != nil {
+			return c, err
+		}
+		if v >= 1 {
+			c.MaxQueueSize = v
+		}
+	}
+
+	if len(args) > 2 {
+		d, err := durationArg(args[2])
+		if err != nil {
+			return c, err
+		}
+		if d >= 1*time.Millisecond {
+			c.Timeout = d
+		}
+	}
+
+	return c, nil
+}
+
+// Config returns the scheduler configuration for the given filter
+func (f *fifoFilter) Config() scheduler.Config {
+	return f.config
+}
+
+// SetQueue binds the queue to the current filter context
+func (f *fifoFilter) SetQueue(q *scheduler.FifoQueue) {
+	f.queue = q
+}
+
+// GetQueue is only used in tests.
+func (f *fifoFilter) GetQueue() *scheduler.FifoQueue {
+	return f.queue
+}
+
+// Request is the filter.Filter interface implementation. Request will
+// increase the number of inflight requests and respond to the caller,
+// if the bounded queue returns an error. Status code by Error:
+//
+// - 503 if scheduler.ErrQueueFull
+// - 502 if scheduler.ErrQueueTimeout
+// - 499 if scheduler.ErrClientCanceled
+// - 504 if the backend timeout passed while waiting in the queue
+func (f *fifoFilter) Request(ctx filters.FilterContext) {
+	fifoRequest(f.GetQueue(), ctx)
+}
+
+// Response is the filter.Filter interface implementation. Response
+// will decrease the number of inflight requests.
+func (f *fifoFilter) Response(ctx filters.FilterContext) {
+	response(scheduler.FIFOKey, ctx)
+}
+
+func (f *fifoGroupFilter) Group() string {
+	return f.name
+}
+
+func (f *fifoGroupFilter) HasConfig() bool {
+	return f.hasConfig
+}
+
+// Config returns the scheduler configuration for the given filter
+func (f *fifoGroupFilter) Config() scheduler.Config {
+	return f.config
+}
+
+// SetQueue binds the queue to the current filter context
+func (f *fifoGroupFilter) SetQueue(q *scheduler.FifoQueue) {
+	f.queue = q
+}
+
+// GetQueue is only used in tests
+func (f *fifoGroupFilter) GetQueue() *scheduler.FifoQueue {
+	return f.queue
+}
+
+// Request is the filter.Filter interface implementation. It behaves
+// the same way as the Request of the fifo filter.
+func (f *fifoGroupFilter) Request(ctx filters.FilterContext) {
+	fifoRequest(f.GetQueue(), ctx)
+}
+
+// Response is the filter.Filter interface implementation. Response
+// will decrease the number of inflight requests.
+func (f *fifoGroupFilter) Response(ctx filters.FilterContext) {
+	response(scheduler.FIFOKey, ctx)
+}
+
+func fifoRequest(q *scheduler.FifoQueue, ctx filters.FilterContext) {
+	if q == nil {
+		log.Warningf("Unexpected scheduler.FifoQueue is nil for key %s", scheduler.FIFOKey)
+		return
+	}
+
+	start := time.Now()
+	done, err := q.Wait(ctx.Request().Context())
+	if err != nil {
+		switch err {
+		case scheduler.ErrQueueFull:
+			log.Debugf("Failed to get an entry on to the queue to process QueueFull: %v for host %s", err, ctx.Request().Host)
+			ctx.Serve(&http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     "Queue Full - https://opensource.zalando.com/skipper/operation/operation/#scheduler",
+			})
+		case scheduler.ErrQueueTimeout:
+			log.Debugf("Failed to get an entry on to the queue to process Timeout: %v for host %s", err, ctx.Request().Host)
+			ctx.Serve(&http.Response{
+				StatusCode: http.StatusBadGateway,
+				Status:     "Queue timeout - https://opensource.zalando.com/skipper/operation/operation/#scheduler",
+			})
+		case scheduler.ErrClientCanceled:
+			log.Debugf("Client canceled while waiting in the queue: %v for host %s", err, ctx.Request().Host)
+			ctx.Serve(&http.Response{StatusCode: 499})
+		default:
+			log.Errorf("Unknown error for route based FIFO: %v for host %s", err, ctx.Request().Host)
+			ctx.Serve(&http.Response{StatusCode: http.StatusInternalServerError})
+		}
+		return
+	}
+
+	pending, _ := ctx.StateBag()[scheduler.FIFOKey].([]func())
+	ctx.StateBag()[scheduler.FIFOKey] = append(pending, done)
+
+	queued, _ := ctx.StateBag()[filters.BackendQueueTime].(time.Duration)
+	queued += time.Since(start)
+	ctx.StateBag()[filters.BackendQueueTime] = queued
+
+	// the proxy checks the deadline, too, this is only an early exit
+	// when the backend timeout was set by a preceding filter
+	if timeout, ok := ctx.StateBag()[filters.BackendTimeout].(time.Duration); ok && queued >= timeout {
+		log.Debugf("Backend timeout passed while waiting in the queue for host %s", ctx.Request().Host)
+		ctx.Serve(&http.Response{
+			StatusCode: http.StatusGatewayTimeout,
+			Status:     "Deadline exceeded - https://opensource.zalando.com/skipper/operation/operation/#scheduler",
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/scheduler"
+)
+
+func TestNewFifo(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		args       []interface{}
+		schedFunc  func() filters.Spec
+		wantName   string
+		wantGroup  string
+		wantErr    bool
+		wantConfig scheduler.Config
+	}{{
+		name:      "fifo with valid configuration",
+		args:      []interface{}{10, 15, "5s"},
+		schedFunc: NewFifo,
+		wantName:  filters.FifoName,
+		wantConfig: scheduler.Config{
+			MaxConcurrency: 10,
+			MaxQueueSize:   15,
+			Timeout:        5 * time.Second,
+		},
+	}, {
+		name:      "fifo without configuration applies defaults",
+		schedFunc: NewFifo,
+		wantName:  filters.FifoName,
+		wantConfig: scheduler.Config{
+			MaxConcurrency: defaultMaxConcurreny,
+			MaxQueueSize:   defaultMaxQueueSize,
+			Timeout:        defaultTimeout,
+		},
+	}, {
+		name:      "fifo with partial invalid configuration applies defaults",
+		args:      []interface{}{-1, -15},
+		schedFunc: NewFifo,
+		wantName:  filters.FifoName,
+		wantConfig: scheduler.Config{
+			MaxConcurrency: defaultMaxConcurreny,
+			MaxQueueSize:   defaultMaxQueueSize,
+			Timeout:        defaultTimeout,
+		},
+	}, {
+		name:      "fifo with invalid duration",
+		args:      []interface{}{1, 1, "4a"},
+		schedFunc: NewFifo,
+		wantName:  filters.FifoName,
+		wantErr:   true,
+	}, {
+		name:      "fifo with too many args",
+		args:      []interface{}{1, 1, "4s", 1},
+		schedFunc: NewFifo,
+		wantName:  filters.FifoName,
+		wantErr:   true,
+	}, {
+		name:      "fifoGroup with valid float64 configuration",
+		args:      []interface{}{"mygroup", 10.1, 15.2, "5s"},
+		schedFunc: NewFifoGroup,
+		wantName:  filters.FifoGroupName,
+		wantGroup: "mygroup",
+		wantConfig: scheduler.Config{
+			MaxConcurrency: 10,
+			MaxQueueSize:   15,
+			Timeout:        5 * time.Second,
+		},
+	}, {
+		name:      "fifoGroup without name",
+		schedFunc: NewFifoGroup,
+		wantName:  filters.FifoGroupName,
+		wantErr:   true,
+	}, {
+		name:      "fifoGroup with invalid int type",
+		args:      []interface{}{"mygroup", "foo"},
+		schedFunc: NewFifoGroup,
+		wantName:  filters.FifoGroupName,
+		wantErr:   true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.schedFunc()
+			if s.Name() != tt.wantName {
+				t.Errorf("Failed to get name, got %s, want %s", s.Name(), tt.wantName)
+			}
+
+			f, err := s.CreateFilter(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Failed to get error on filter creation")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to create filter: %v", err)
+			}
+
+			switch ff := f.(type) {
+			case *fifoFilter:
+				if c := ff.Config(); c != tt.wantConfig {
+					t.Errorf("Failed to get Config, got: %v, want: %v", c, tt.wantConfig)
+				}
+			case *fifoGroupFilter:
+				if c := ff.Config(); c != tt.wantConfig {
+					t.Errorf("Failed to get Config, got: %v, want: %v", c, tt.wantConfig)
+				}
+
+				if ff.Group() != tt.wantGroup {
+					t.Errorf("Failed to get Group, got: %s, want: %s", ff.Group(), tt.wantGroup)
+				}
+
+				if !ff.HasConfig() {
+					t.Error("Failed to get HasConfig")
+				}
+			default:
+				t.Fatalf("Unexpected filter type: %T", f)
+			}
+		})
+	}
+}
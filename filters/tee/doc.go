@@ -21,5 +21,10 @@ It is possible to change the path of the tee request, in a similar way to the mo
 	Path("/api/v1") -> tee("https://api.example.org", "^/v1", "/v2" ) -> "http://api.example.org"
 
 In the above example, one can test how a new version of an API would behave on incoming requests.
+
+The mirror filter sends only a fraction of the requests to the shadow backend, and compares the
+shadow responses to the primary ones, reporting the mismatches as metrics and log entries:
+
+	* -> mirror("https://new-backend.example.org", 0.1, "compareHeader:Content-Type", "ignore:items.*.updated") -> "https://foo.example.org"
 */
 package tee
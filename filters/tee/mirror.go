@@ -0,0 +1,505 @@
+package tee
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/metrics"
+)
+
+const (
+	defaultMirrorTimeout = 5 * time.Second
+
+	// maxCompareBody limits how much of the primary and the shadow
+	// response bodies is kept for the comparison. Longer bodies are
+	// compared only by status and headers.
+	maxCompareBody = 1 << 20
+
+	mirrorStateKeyPrefix = "tee:mirror:"
+)
+
+type mirrorSpec struct {
+	options Options
+}
+
+type mirrorCondition struct {
+	name  string
+	value string
+}
+
+type mirror struct {
+	client         *http.Client
+	backend        string
+	host           string
+	scheme         string
+	fraction       float64
+	condition      *mirrorCondition
+	compareHeaders []string
+	ignorePaths    [][]string
+	metrics        metrics.Metrics
+	metricsPrefix  string
+	stateKey       string
+	compareDone    func() // test hook
+}
+
+type mirrorResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	truncated  bool
+	err        error
+}
+
+type captureBody struct {
+	body    io.ReadCloser
+	buf     bytes.Buffer
+	full    bool
+	once    sync.Once
+	onClose func(body []byte, truncated bool)
+}
+
+// NewMirror returns a new mirror filter Spec, whose instances send a
+// fraction of the requests to a shadow backend, and compare the shadow
+// responses to the primary ones. The status code, the selected headers
+// and the JSON bodies are compared, and the mismatches are reported as
+// metrics and log entries.
+//
+// Parameters: shadow backend url, fraction of the requests between 0 and
+// 1, and optional string arguments:
+//
+// 	"header:<name>[=<value>]" - mirror only the requests with a matching header
+// 	"compareHeader:<name>" - compare the given response header, can be repeated
+// 	"ignore:<path>" - ignore a JSON path in the body diff, e.g. "items.*.updated", can be repeated
+//
+// Name: "mirror".
+func NewMirror() filters.Spec {
+	return MirrorWithOptions(Options{NoFollow: true, Timeout: defaultMirrorTimeout})
+}
+
+// MirrorWithOptions returns a new mirror filter Spec with the given
+// options for the client sending the shadow requests.
+func MirrorWithOptions(o Options) filters.Spec {
+	return &mirrorSpec{options: o}
+}
+
+func (*mirrorSpec) Name() string { return filters.MirrorName }
+
+func (spec *mirrorSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	backend, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	u, err := url.Parse(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid backend url in %s: %s", filters.MirrorName, backend)
+	}
+
+	fraction, ok := args[1].(float64)
+	if !ok || fraction < 0 || fraction > 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	client := &http.Client{Timeout: spec.options.Timeout}
+	if spec.options.NoFollow {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	m := &mirror{
+		client:        client,
+		backend:       backend,
+		host:          u.Host,
+		scheme:        u.Scheme,
+		fraction:      fraction,
+		metrics:       metrics.Default,
+		metricsPrefix: "mirror." + metricsKeyPart(u.Host) + ".",
+	}
+
+	// the key is derived from the filter instance, because multiple
+	// instances on the same route may use the same shadow backend
+	m.stateKey = fmt.Sprintf("%s%p", mirrorStateKeyPrefix, m)
+
+	for _, a := range args[2:] {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		switch {
+		case strings.HasPrefix(s, "header:"):
+			c := &mirrorCondition{name: strings.TrimPrefix(s, "header:")}
+			if i := strings.Index(c.name, "="); i >= 0 {
+				c.name, c.value = c.name[:i], c.name[i+1:]
+			}
+
+			if c.name == "" {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			m.condition = c
+		case strings.HasPrefix(s, "compareHeader:"):
+			h := strings.TrimPrefix(s, "compareHeader:")
+			if h == "" {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			m.compareHeaders = append(m.compareHeaders, http.CanonicalHeaderKey(h))
+		case strings.HasPrefix(s, "ignore:"):
+			p := strings.TrimPrefix(s, "ignore:")
+			if p == "" {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			m.ignorePaths = append(m.ignorePaths, strings.Split(p, "."))
+		default:
+			return nil, fmt.Errorf("invalid option in %s: %s", filters.MirrorName, s)
+		}
+	}
+
+	return m, nil
+}
+
+func metricsKeyPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+
+		return '_'
+	}, s)
+}
+
+func (m *mirror) sample(r *http.Request) bool {
+	if m.condition != nil {
+		v, ok := r.Header[http.CanonicalHeaderKey(m.condition.name)]
+		if !ok {
+			return false
+		}
+
+		if m.condition.value != "" {
+			var found bool
+			for _, vi := range v {
+				if vi == m.condition.value {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+	}
+
+	/* #nosec */
+	return rand.Float64() < m.fraction
+}
+
+// Request splits the context, and sends the copy of the request to the
+// shadow backend, when the request was sampled.
+func (m *mirror) Request(ctx filters.FilterContext) {
+	if !m.sample(ctx.Request()) {
+		return
+	}
+
+	cc, err := ctx.Split()
+	if err != nil {
+		log.Errorf("mirror: failed to split the context request: %v", err)
+		return
+	}
+
+	req := m.shadowRequest(cc.Request())
+	result := make(chan *mirrorResponse, 1)
+	ctx.StateBag()[m.stateKey] = result
+	m.inc("requests")
+
+	go func() {
+		rsp, err := m.client.Do(req)
+		if err != nil {
+			result <- &mirrorResponse{err: err}
+			return
+		}
+
+		defer rsp.Body.Close()
+		body, truncated, err := readLimited(rsp.Body)
+		result <- &mirrorResponse{
+			statusCode: rsp.StatusCode,
+			header:     rsp.Header,
+			body:       body,
+			truncated:  truncated,
+			err:        err,
+		}
+	}()
+}
+
+func (m *mirror) shadowRequest(r *http.Request) *http.Request {
+	u := new(url.URL)
+	*u = *r.URL
+	u.Scheme = m.scheme
+	u.Host = m.host
+
+	for _, k := range hopHeaders {
+		r.Header.Del(k)
+	}
+
+	r.URL = u
+	r.Host = m.host
+	r.RequestURI = ""
+	return r
+}
+
+func readLimited(r io.Reader) ([]byte, bool, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxCompareBody+1))
+	if len(b) > maxCompareBody {
+		return nil, true, err
+	}
+
+	return b, false, err
+}
+
+// Response captures the primary response body while it is streamed to
+// the client, and compares it to the shadow response, once the primary
+// body was consumed.
+func (m *mirror) Response(ctx filters.FilterContext) {
+	result, ok := ctx.StateBag()[m.stateKey].(chan *mirrorResponse)
+	if !ok {
+		return
+	}
+
+	rsp := ctx.Response()
+	primary := &mirrorResponse{statusCode: rsp.StatusCode, header: rsp.Header}
+	req := ctx.Request()
+	compare := func(body []byte, truncated bool) {
+		primary.body, primary.truncated = body, truncated
+		go func() {
+			m.compare(req, primary, <-result)
+			if m.compareDone != nil {
+				m.compareDone()
+			}
+		}()
+	}
+
+	if rsp.Body == nil {
+		compare(nil, false)
+		return
+	}
+
+	rsp.Body = &captureBody{body: rsp.Body, onClose: compare}
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if !b.full {
+		if b.buf.Len()+n > maxCompareBody {
+			b.full = true
+			b.buf.Reset()
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+
+	if err == io.EOF {
+		b.done()
+	}
+
+	return n, err
+}
+
+func (b *captureBody) Close() error {
+	b.done()
+	return b.body.Close()
+}
+
+func (b *captureBody) done() {
+	b.once.Do(func() {
+		if b.full {
+			b.onClose(nil, true)
+			return
+		}
+
+		b.onClose(b.buf.Bytes(), false)
+	})
+}
+
+func (m *mirror) inc(key string) {
+	if m.metrics != nil {
+		m.metrics.IncCounter(m.metricsPrefix + key)
+	}
+}
+
+func (m *mirror) compare(req *http.Request, primary, shadow *mirrorResponse) {
+	lg := log.WithFields(log.Fields{
+		"mirror": m.backend,
+		"method": req.Method,
+		"path":   req.URL.Path,
+	})
+
+	if shadow.err != nil {
+		m.inc("errors")
+		lg.Warnf("mirror: error while mirroring request: %v", shadow.err)
+		return
+	}
+
+	var mismatch bool
+	if primary.statusCode != shadow.statusCode {
+		mismatch = true
+		m.inc("mismatch.status")
+		lg.WithFields(log.Fields{
+			"primary": primary.statusCode,
+			"shadow":  shadow.statusCode,
+		}).Info("mirror: status code mismatch")
+	}
+
+	for _, h := range m.compareHeaders {
+		p, s := primary.header.Values(h), shadow.header.Values(h)
+		if strings.Join(p, ",") != strings.Join(s, ",") {
+			mismatch = true
+			m.inc("mismatch.header")
+			lg.WithFields(log.Fields{
+				"header":  h,
+				"primary": p,
+				"shadow":  s,
+			}).Info("mirror: header mismatch")
+		}
+	}
+
+	if !primary.truncated && !shadow.truncated {
+		if diff := m.bodyDiff(primary.body, shadow.body); len(diff) > 0 {
+			mismatch = true
+			m.inc("mismatch.body")
+			lg.WithField("paths", diff).Info("mirror: body mismatch")
+		}
+	}
+
+	if !mismatch {
+		m.inc("match")
+	}
+}
+
+// bodyDiff returns the paths of the differing JSON values. When any of the
+// bodies is not JSON, the bodies are compared byte by byte, and the root
+// path is returned on difference.
+func (m *mirror) bodyDiff(primary, shadow []byte) []string {
+	var p, s interface{}
+	if json.Unmarshal(primary, &p) != nil || json.Unmarshal(shadow, &s) != nil {
+		if bytes.Equal(primary, shadow) {
+			return nil
+		}
+
+		return []string{"$"}
+	}
+
+	var diff []string
+	jsonDiff(nil, p, s, m.ignorePaths, &diff)
+	sort.Strings(diff)
+	return diff
+}
+
+func ignored(path []string, ignorePaths [][]string) bool {
+	for _, ip := range ignorePaths {
+		if len(ip) != len(path) {
+			continue
+		}
+
+		match := true
+		for i := range ip {
+			if ip[i] != "*" && ip[i] != path[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonDiff(path []string, p, s interface{}, ignorePaths [][]string, diff *[]string) {
+	if ignored(path, ignorePaths) {
+		return
+	}
+
+	switch pv := p.(type) {
+	case map[string]interface{}:
+		sv, ok := s.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]bool)
+		for k := range pv {
+			keys[k] = true
+		}
+
+		for k := range sv {
+			keys[k] = true
+		}
+
+		for k := range keys {
+			jsonDiff(append(path[:len(path):len(path)], k), pv[k], sv[k], ignorePaths, diff)
+		}
+
+		return
+	case []interface{}:
+		sv, ok := s.([]interface{})
+		if !ok {
+			break
+		}
+
+		n := len(pv)
+		if len(sv) > n {
+			n = len(sv)
+		}
+
+		for i := 0; i < n; i++ {
+			var pi, si interface{}
+			if i < len(pv) {
+				pi = pv[i]
+			}
+
+			if i < len(sv) {
+				si = sv[i]
+			}
+
+			jsonDiff(append(path[:len(path):len(path)], strconv.Itoa(i)), pi, si, ignorePaths, diff)
+		}
+
+		return
+	default:
+		if p == s {
+			return
+		}
+	}
+
+	if len(path) == 0 {
+		*diff = append(*diff, "$")
+		return
+	}
+
+	*diff = append(*diff, strings.Join(path, "."))
+}
@@ -0,0 +1,331 @@
+package tee
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/metrics/metricstest"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+type testMirrorSpec struct {
+	filter *mirror
+}
+
+func (s *testMirrorSpec) Name() string { return filters.MirrorName }
+
+func (s *testMirrorSpec) CreateFilter([]interface{}) (filters.Filter, error) {
+	return s.filter, nil
+}
+
+func TestMirrorArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		fail bool
+	}{{
+		name: "no args",
+		fail: true,
+	}, {
+		name: "backend only",
+		args: []interface{}{"https://shadow.example.org"},
+		fail: true,
+	}, {
+		name: "backend and fraction",
+		args: []interface{}{"https://shadow.example.org", 0.1},
+	}, {
+		name: "invalid backend",
+		args: []interface{}{"shadow", 0.1},
+		fail: true,
+	}, {
+		name: "invalid fraction",
+		args: []interface{}{"https://shadow.example.org", 1.5},
+		fail: true,
+	}, {
+		name: "all options",
+		args: []interface{}{"https://shadow.example.org", 0.1, "header:X-Mirror=true", "compareHeader:Content-Type", "ignore:items.*.id"},
+	}, {
+		name: "unknown option",
+		args: []interface{}{"https://shadow.example.org", 0.1, "foo:bar"},
+		fail: true,
+	}, {
+		name: "invalid option type",
+		args: []interface{}{"https://shadow.example.org", 0.1, 42.0},
+		fail: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMirror().CreateFilter(tt.args)
+			if tt.fail && err == nil {
+				t.Error("Failed to fail")
+			} else if !tt.fail && err != nil {
+				t.Errorf("Failed to create filter: %v", err)
+			}
+		})
+	}
+}
+
+func TestMirrorBodyDiff(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		primary string
+		shadow  string
+		ignore  []interface{}
+		want    []string
+	}{{
+		name:    "equal",
+		primary: `{"a": 1, "b": [1, 2]}`,
+		shadow:  `{"b": [1, 2], "a": 1}`,
+	}, {
+		name:    "different values",
+		primary: `{"a": 1, "b": {"c": "foo"}}`,
+		shadow:  `{"a": 2, "b": {"c": "bar"}}`,
+		want:    []string{"a", "b.c"},
+	}, {
+		name:    "different arrays",
+		primary: `{"items": [{"id": 1}, {"id": 2}]}`,
+		shadow:  `{"items": [{"id": 1}]}`,
+		want:    []string{"items.1"},
+	}, {
+		name:    "ignored paths",
+		primary: `{"items": [{"id": 1, "ts": 1}, {"id": 2, "ts": 2}], "now": 1}`,
+		shadow:  `{"items": [{"id": 1, "ts": 3}, {"id": 2, "ts": 4}], "now": 2}`,
+		ignore:  []interface{}{"ignore:items.*.ts", "ignore:now"},
+	}, {
+		name:    "different types",
+		primary: `{"a": [1]}`,
+		shadow:  `{"a": {"0": 1}}`,
+		want:    []string{"a"},
+	}, {
+		name:    "equal text",
+		primary: "foo",
+		shadow:  "foo",
+	}, {
+		name:    "different text",
+		primary: "foo",
+		shadow:  "bar",
+		want:    []string{"$"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewMirror().CreateFilter(append([]interface{}{"https://shadow.example.org", 1.0}, tt.ignore...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diff := f.(*mirror).bodyDiff([]byte(tt.primary), []byte(tt.shadow))
+			if !reflect.DeepEqual(diff, tt.want) {
+				t.Errorf("Failed to get the diff, got: %v, want: %v", diff, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirror(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Version", "1")
+		w.Write([]byte(`{"id": 1, "name": "foo", "ts": 1}`))
+	}))
+	defer primary.Close()
+
+	for _, tt := range []struct {
+		name         string
+		args         []interface{}
+		status       int
+		header       http.Header
+		body         string
+		wantCounters map[string]int64
+	}{{
+		name:   "match",
+		args:   []interface{}{"ignore:ts"},
+		status: http.StatusOK,
+		body:   `{"id": 1, "name": "foo", "ts": 2}`,
+		wantCounters: map[string]int64{
+			"requests": 1,
+			"match":    1,
+		},
+	}, {
+		name:   "status mismatch",
+		args:   []interface{}{"ignore:ts"},
+		status: http.StatusNotFound,
+		body:   `{"id": 1, "name": "foo", "ts": 1}`,
+		wantCounters: map[string]int64{
+			"requests":        1,
+			"mismatch.status": 1,
+		},
+	}, {
+		name:   "header mismatch",
+		args:   []interface{}{"compareHeader:X-Version"},
+		status: http.StatusOK,
+		header: http.Header{"X-Version": []string{"2"}},
+		body:   `{"id": 1, "name": "foo", "ts": 1}`,
+		wantCounters: map[string]int64{
+			"requests":        1,
+			"mismatch.header": 1,
+		},
+	}, {
+		name:   "body mismatch",
+		status: http.StatusOK,
+		body:   `{"id": 1, "name": "bar", "ts": 1}`,
+		wantCounters: map[string]int64{
+			"requests":      1,
+			"mismatch.body": 1,
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer shadow.Close()
+
+			f, err := NewMirror().CreateFilter(append([]interface{}{shadow.URL, 1.0}, tt.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := f.(*mirror)
+			mm := &metricstest.MockMetrics{}
+			m.metrics = mm
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			m.compareDone = wg.Done
+
+			p := proxytest.New(filters.Registry{filters.MirrorName: &testMirrorSpec{m}}, &eskip.Route{
+				Filters: []*eskip.Filter{{Name: filters.MirrorName}},
+				Backend: primary.URL,
+			})
+			defer p.Close()
+
+			rsp, err := http.Get(p.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			io.Copy(io.Discard, rsp.Body)
+			rsp.Body.Close()
+			wg.Wait()
+
+			mm.WithCounters(func(counters map[string]int64) {
+				got := make(map[string]int64)
+				for k, v := range counters {
+					got[k[len(m.metricsPrefix):]] = v
+				}
+
+				if !reflect.DeepEqual(got, tt.wantCounters) {
+					t.Errorf("Failed to get the counters, got: %v, want: %v", got, tt.wantCounters)
+				}
+			})
+		})
+	}
+}
+
+func TestMirrorHeaderCondition(t *testing.T) {
+	f, err := NewMirror().CreateFilter([]interface{}{"https://shadow.example.org", 1.0, "header:X-Mirror=true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := f.(*mirror)
+	r := &http.Request{Header: http.Header{}}
+	if m.sample(r) {
+		t.Error("Failed to skip request without header")
+	}
+
+	r.Header.Set("X-Mirror", "false")
+	if m.sample(r) {
+		t.Error("Failed to skip request with different header value")
+	}
+
+	r.Header.Set("X-Mirror", "true")
+	if !m.sample(r) {
+		t.Error("Failed to sample request with matching header")
+	}
+}
+
+func TestMirrorSameBackendTwice(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "1")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer primary.Close()
+
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "2")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer shadow.Close()
+
+	mm := &metricstest.MockMetrics{}
+	var wg sync.WaitGroup
+	registry := make(filters.Registry)
+	var route eskip.Route
+	for i, args := range [][]interface{}{
+		{shadow.URL, 1.0},
+		{shadow.URL, 1.0, "compareHeader:X-Version"},
+	} {
+		f, err := NewMirror().CreateFilter(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := f.(*mirror)
+		m.metrics = mm
+		m.compareDone = wg.Done
+		wg.Add(1)
+
+		name := filters.MirrorName + strconv.Itoa(i)
+		registry[name] = &testMirrorSpec{m}
+		route.Filters = append(route.Filters, &eskip.Filter{Name: name})
+	}
+
+	route.Backend = primary.URL
+	p := proxytest.New(registry, &route)
+	defer p.Close()
+
+	rsp, err := http.Get(p.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	io.Copy(io.Discard, rsp.Body)
+	rsp.Body.Close()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Failed to compare the responses of both filters")
+	}
+
+	prefix := "mirror." + metricsKeyPart(strings.TrimPrefix(shadow.URL, "http://")) + "."
+	mm.WithCounters(func(counters map[string]int64) {
+		got := make(map[string]int64)
+		for k, v := range counters {
+			got[strings.TrimPrefix(k, prefix)] = v
+		}
+
+		want := map[string]int64{"requests": 2, "match": 1, "mismatch.header": 1}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Failed to get the counters, got: %v, want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package skipper
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/metrics"
+	"github.com/zalando/skipper/predicates/listener"
+)
+
+// quicMetricsListener counts the accepted and the closed QUIC
+// connections, similar to the connection state metrics of the TCP
+// listeners.
+type quicMetricsListener struct {
+	http3.QUICEarlyListener
+	metrics metrics.Metrics
+}
+
+func (l *quicMetricsListener) Accept(ctx context.Context) (quic.EarlyConnection, error) {
+	c, err := l.QUICEarlyListener.Accept(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	l.metrics.IncCounter("lb-quic-conn-new")
+	go func() {
+		<-c.Context().Done()
+		l.metrics.IncCounter("lb-quic-conn-closed")
+	}()
+
+	return c, nil
+}
+
+// altSvcHandler advertises the HTTP/3 listener on the responses of the
+// TLS listener.
+func altSvcHandler(s *http3.Server, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && r.ProtoMajor < 3 {
+			// fails only until the HTTP/3 listener is started
+			s.SetQuicHeaders(w.Header())
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newHTTP3Server creates the HTTP/3 server of the proxy, sharing the
+// TLS configuration and the handler with the main listener.
+func (o *Options) newHTTP3Server(handler http.Handler, tlsConfig *tls.Config) *http3.Server {
+	return &http3.Server{
+		Addr:           o.http3Address(),
+		TLSConfig:      tlsConfig,
+		Handler:        handler,
+		MaxHeaderBytes: o.MaxHeaderBytes,
+		QuicConfig:     &quic.Config{MaxIdleTimeout: o.IdleTimeoutServer},
+		ConnContext: func(ctx context.Context, _ quic.Connection) context.Context {
+			return listener.NewContext(ctx, listener.DefaultName)
+		},
+	}
+}
+
+func (o *Options) http3Address() string {
+	if o.AddressHTTP3 != "" {
+		return o.AddressHTTP3
+	}
+
+	if o.Address != "" {
+		return o.Address
+	}
+
+	return ":https"
+}
+
+// listenHTTP3 creates the UDP listener of the HTTP/3 server. The
+// returned listener is served with http3.Server.ServeListener.
+func (o *Options) listenHTTP3(s *http3.Server, mtr metrics.Metrics) (http3.QUICEarlyListener, error) {
+	l, err := quic.ListenAddrEarly(s.Addr, http3.ConfigureTLSConfig(s.TLSConfig), s.QuicConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("HTTP/3 listener on %v", l.Addr())
+	if !o.EnableConnMetricsServer {
+		return l, nil
+	}
+
+	return &quicMetricsListener{QUICEarlyListener: l, metrics: mtr}, nil
+}
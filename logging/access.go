@@ -44,6 +44,9 @@ type AccessEntry struct {
 
 	// The time that the request was received.
 	RequestTime time.Time
+
+	// The metadata of the matched route, if any.
+	RouteMetadata map[string]string
 }
 
 // TODO: create individual instances from the access log and
@@ -162,6 +165,17 @@ func LogAccess(entry *AccessEntry, additional map[string]interface{}) {
 		"audit":          auditHeader,
 	}
 
+	// the subject of the verified client certificate is logged only in
+	// the structured format, to keep the columns of the text format
+	if entry.Request != nil && entry.Request.TLS != nil && len(entry.Request.TLS.VerifiedChains) > 0 {
+		logData["client-cert-subject"] = entry.Request.TLS.VerifiedChains[0][0].Subject.String()
+	}
+
+	// the route metadata is logged only in the structured format, too
+	if len(entry.RouteMetadata) > 0 {
+		logData["route-metadata"] = entry.RouteMetadata
+	}
+
 	for k, v := range additional {
 		logData[k] = v
 	}
@@ -2,6 +2,9 @@ package logging
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"net/http"
 	"testing"
 	"time"
@@ -237,6 +240,46 @@ func TestPresentAuditJSONWithCustomFormatter(t *testing.T) {
 	)
 }
 
+func TestClientCertSubjectJSON(t *testing.T) {
+	entry := testAccessEntry()
+	c := &x509.Certificate{Subject: pkix.Name{CommonName: "client", Organization: []string{"Example"}}}
+	entry.Request.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{c},
+		VerifiedChains:   [][]*x509.Certificate{{c}},
+	}
+
+	testAccessLog(
+		t,
+		entry,
+		`{"audit":"","client-cert-subject":"CN=client,O=Example","duration":42,"flow-id":"","host":"127.0.0.1","level":"info","method":"GET","msg":"","proto":"HTTP/1.1","referer":"","requested-host":"example.com","response-size":2326,"status":418,"timestamp":"10/Oct/2000:13:55:36 -0700","uri":"/apache_pb.gif","user-agent":""}`,
+		Options{AccessLogJSONEnabled: true},
+	)
+
+	testAccessLogDefault(t, entry, logOutput)
+}
+
+func TestRouteMetadataJSON(t *testing.T) {
+	entry := testAccessEntry()
+	entry.RouteMetadata = map[string]string{"owner": "team-a"}
+	testAccessLog(
+		t,
+		entry,
+		`{"audit":"","duration":42,"flow-id":"","host":"127.0.0.1","level":"info","method":"GET","msg":"","proto":"HTTP/1.1","referer":"","requested-host":"example.com","response-size":2326,"route-metadata":{"owner":"team-a"},"status":418,"timestamp":"10/Oct/2000:13:55:36 -0700","uri":"/apache_pb.gif","user-agent":""}`,
+		Options{AccessLogJSONEnabled: true},
+	)
+
+	testAccessLogDefault(t, entry, logOutput)
+}
+
+func TestUnverifiedClientCertNotLogged(t *testing.T) {
+	entry := testAccessEntry()
+	entry.Request.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client"}}},
+	}
+
+	testAccessLog(t, entry, logJSONOutput, Options{AccessLogJSONEnabled: true})
+}
+
 func TestAccessLogStripQuery(t *testing.T) {
 	entry := testAccessEntry()
 	entry.Request.RequestURI += "?foo=bar"
@@ -8,9 +8,10 @@ import (
 )
 
 type LoggingWriter struct {
-	writer http.ResponseWriter
-	bytes  int64
-	code   int
+	writer   http.ResponseWriter
+	bytes    int64
+	code     int
+	hijacked bool
 }
 
 func NewLoggingWriter(writer http.ResponseWriter) *LoggingWriter {
@@ -28,6 +29,13 @@ func (lw *LoggingWriter) WriteHeader(code int) {
 	if code == 0 {
 		code = 200
 	}
+
+	// informational responses, e.g. 103 Early Hints, are followed
+	// by the final response, except for 101 Switching Protocols
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		return
+	}
+
 	lw.code = code
 }
 
@@ -42,11 +50,18 @@ func (lw *LoggingWriter) Flush() {
 func (lw *LoggingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	hij, ok := lw.writer.(http.Hijacker)
 	if ok {
-		return hij.Hijack()
+		conn, rw, err := hij.Hijack()
+		lw.hijacked = err == nil
+		return conn, rw, err
 	}
 	return nil, nil, fmt.Errorf("could not hijack connection")
 }
 
+// Hijacked returns true when the underlying connection was hijacked.
+func (lw *LoggingWriter) Hijacked() bool {
+	return lw.hijacked
+}
+
 func (lw *LoggingWriter) GetBytes() int64 {
 	return lw.bytes
 }
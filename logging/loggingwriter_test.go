@@ -55,3 +55,17 @@ func TestFlushesPartialPayload(t *testing.T) {
 		t.Error("failed to flush underlying writer")
 	}
 }
+
+func TestInformationalStatusCodeNotStored(t *testing.T) {
+	rr := httptest.NewRecorder()
+	w := &LoggingWriter{writer: rr}
+	w.WriteHeader(http.StatusEarlyHints)
+	if w.GetCode() != 0 {
+		t.Error("failed to ignore informational status code")
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if w.GetCode() != http.StatusOK {
+		t.Error("failed to get status code")
+	}
+}
@@ -39,6 +39,10 @@ func (a *All) UpdateGauge(key string, v float64) {
 	a.prometheus.UpdateGauge(key, v)
 	a.codaHale.UpdateGauge(key, v)
 }
+func (a *All) DeleteGauge(key string) {
+	a.prometheus.DeleteGauge(key)
+	a.codaHale.DeleteGauge(key)
+}
 func (a *All) MeasureRouteLookup(start time.Time) {
 	a.prometheus.MeasureRouteLookup(start)
 	a.codaHale.MeasureRouteLookup(start)
@@ -80,6 +80,11 @@ func TestCodaHaleMeasurement(t *testing.T) {
 		t.Errorf("'TestGauge' metric should be 1. Got %f", g1.Value())
 	}
 
+	c.DeleteGauge("TestGauge")
+	if c.reg.Get("TestGauge") != nil {
+		t.Error("'TestGauge' metric should be deleted")
+	}
+
 	t1 := c.getTimer("TestMeasurement1")
 	if t1.Count() != 0 && t1.Max() != 0 {
 		t.Error("'TestMeasurement1' metric should only have zeroes")
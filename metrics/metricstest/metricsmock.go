@@ -170,6 +170,12 @@ func (m *MockMetrics) UpdateGauge(key string, value float64) {
 	})
 }
 
+func (m *MockMetrics) DeleteGauge(key string) {
+	m.WithGauges(func(g map[string]float64) {
+		delete(g, key)
+	})
+}
+
 func (m *MockMetrics) Gauge(key string) (v float64, ok bool) {
 	m.WithGauges(func(g map[string]float64) {
 		v, ok = g[key]
@@ -339,6 +339,11 @@ func (p *Prometheus) UpdateGauge(key string, v float64) {
 	p.customGaugeM.WithLabelValues(key).Set(v)
 }
 
+// DeleteGauge deletes a gauge set by UpdateGauge.
+func (p *Prometheus) DeleteGauge(key string) {
+	p.customGaugeM.DeleteLabelValues(key)
+}
+
 // MeasureRouteLookup satisfies Metrics interface.
 func (p *Prometheus) MeasureRouteLookup(start time.Time) {
 	t := p.sinceS(start)
@@ -1,6 +1,7 @@
 package net
 
 import (
+	"crypto/x509"
 	"net"
 	"net/http"
 	"strings"
@@ -62,6 +63,19 @@ func RemoteHostFromLast(r *http.Request) net.IP {
 	return parse(r.RemoteAddr)
 }
 
+// VerifiedClientCertificate returns the client certificate of a
+// request received over TLS, when the client presented a certificate,
+// and it was verified against the client CAs. It returns nil
+// otherwise, including the cases when the certificates were requested
+// but not verified.
+func VerifiedClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return r.TLS.VerifiedChains[0][0]
+}
+
 // A list of IPNets
 type IPNets []*net.IPNet
 
@@ -1,6 +1,9 @@
 package net
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"net"
 	"net/http"
 	"reflect"
@@ -146,3 +149,31 @@ func TestIPNetsDoNotContain(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifiedClientCertificate(t *testing.T) {
+	c := &x509.Certificate{Subject: pkix.Name{CommonName: "client"}}
+	for _, tt := range []struct {
+		name  string
+		state *tls.ConnectionState
+		want  *x509.Certificate
+	}{{
+		name: "no TLS",
+	}, {
+		name:  "no client certificate",
+		state: &tls.ConnectionState{},
+	}, {
+		name:  "not verified",
+		state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{c}},
+	}, {
+		name:  "verified",
+		state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{c}, VerifiedChains: [][]*x509.Certificate{{c}}},
+		want:  c,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{TLS: tt.state}
+			if got := VerifiedClientCertificate(r); got != tt.want {
+				t.Errorf("Unexpected certificate '%v'. Wanted '%v'", got, tt.want)
+			}
+		})
+	}
+}
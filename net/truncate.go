@@ -0,0 +1,87 @@
+package net
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type truncatedBody struct {
+	body       io.ReadCloser
+	remaining  int64
+	w          http.ResponseWriter
+	err        error
+	onTruncate func()
+}
+
+// ResetConnection closes the client connection of a response writer,
+// without sending the rest of the response, to make sure that the
+// client doesn't see a complete response, even when it is chunked. On
+// TCP connections, the linger is disabled, to make the client see a
+// connection reset.
+func ResetConnection(w http.ResponseWriter) error {
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return errors.New("response writer does not support hijacking")
+	}
+
+	conn, _, err := h.Hijack()
+	if err != nil {
+		return err
+	}
+
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+
+	return conn.Close()
+}
+
+// NewTruncatedBody wraps a response body, and returns at most limit
+// bytes from it. When the body is longer than the limit, it calls
+// onTruncate, when set, resets the client connection of the response
+// writer with ResetConnection, and returns err from Read.
+func NewTruncatedBody(body io.ReadCloser, limit int64, w http.ResponseWriter, err error, onTruncate func()) io.ReadCloser {
+	return &truncatedBody{
+		body:       body,
+		remaining:  limit,
+		w:          w,
+		err:        err,
+		onTruncate: onTruncate,
+	}
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// nothing to truncate when the body ends at the limit
+		var probe [1]byte
+		if n, err := b.body.Read(probe[:]); n == 0 && err == io.EOF {
+			return 0, io.EOF
+		}
+
+		if b.onTruncate != nil {
+			b.onTruncate()
+		}
+
+		if err := ResetConnection(b.w); err != nil {
+			log.Errorf("Failed to reset connection after truncating the response: %v", err)
+		}
+
+		return 0, b.err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *truncatedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,80 @@
+package net
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTruncatedBody(t *testing.T) {
+	errTruncated := errors.New("truncated")
+	for _, test := range []struct {
+		title     string
+		body      string
+		limit     int64
+		truncated bool
+	}{{
+		title: "shorter than the limit",
+		body:  "foo",
+		limit: 6,
+	}, {
+		title: "ends at the limit",
+		body:  "foobar",
+		limit: 6,
+	}, {
+		title:     "longer than the limit",
+		body:      "foobarbaz",
+		limit:     6,
+		truncated: true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			var called bool
+			done := make(chan struct{})
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				defer close(done)
+				body := NewTruncatedBody(
+					io.NopCloser(strings.NewReader(test.body)),
+					test.limit,
+					w,
+					errTruncated,
+					func() { called = true },
+				)
+
+				defer body.Close()
+				if _, err := io.Copy(w, body); err != errTruncated && test.truncated {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}))
+			defer s.Close()
+
+			rsp, err := http.Get(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			b, err := io.ReadAll(rsp.Body)
+			if test.truncated {
+				if err == nil {
+					t.Errorf("failed to reset the connection, received: %s", b)
+				}
+			} else if err != nil || string(b) != test.body {
+				t.Errorf("unexpected response: %s, %v", b, err)
+			}
+
+			<-done
+			if called != test.truncated {
+				t.Errorf("unexpected truncation callback: %t", called)
+			}
+		})
+	}
+}
+
+func TestResetConnectionNotHijackable(t *testing.T) {
+	if err := ResetConnection(httptest.NewRecorder()); err == nil {
+		t.Error("failed to fail")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Package clientcert implements predicates to match routes based on the
+identity of the TLS client certificate of a request.
+
+The predicates match only the requests received over TLS with a client
+certificate, that was verified against the configured client CAs, e.g.
+when Skipper runs with -tls-client-ca. The certificates requested but
+not verified, e.g. with -tls-client-auth=request, are never matched.
+
+Examples:
+
+	// match the clients with a certificate issued for the payment
+	// organizational unit
+	payment: ClientCertSubject(/OU=payment(,|$)/) -> "https://payment.example.org";
+
+	// match a SPIFFE identity or a DNS name of the client certificate
+	orders: ClientCertSAN("spiffe://example.org/orders", "orders.example.org") -> "https://orders.example.org";
+*/
+package clientcert
+
+import (
+	"net/http"
+	"regexp"
+
+	snet "github.com/zalando/skipper/net"
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+type (
+	subjectSpec struct{}
+	sanSpec     struct{}
+
+	subjectPredicate struct {
+		exp *regexp.Regexp
+	}
+
+	sanPredicate struct {
+		names []string
+	}
+)
+
+// NewSubject creates a predicate specification, whose instances match
+// the subject of the client certificate, in the RFC 2253 format, e.g.
+// "CN=client.example.org,OU=payment,O=Example", against a regular
+// expression.
+//
+// Eskip example:
+//
+// 	ClientCertSubject(/^CN=client[.]example[.]org,/) -> "https://www.example.org";
+//
+func NewSubject() routing.PredicateSpec { return &subjectSpec{} }
+
+// NewSAN creates a predicate specification, whose instances match when
+// any of the subject alternative names of the client certificate, DNS
+// names, email addresses, IP addresses or URIs, equals any of the
+// arguments.
+//
+// Eskip example:
+//
+// 	ClientCertSAN("spiffe://example.org/orders") -> "https://www.example.org";
+//
+func NewSAN() routing.PredicateSpec { return &sanSpec{} }
+
+func (*subjectSpec) Name() string { return predicates.ClientCertSubjectName }
+
+func (*subjectSpec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) != 1 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	exp, err := regexp.Compile(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &subjectPredicate{exp: exp}, nil
+}
+
+func (p *subjectPredicate) Match(r *http.Request) bool {
+	c := snet.VerifiedClientCertificate(r)
+	return c != nil && p.exp.MatchString(c.Subject.String())
+}
+
+func (*sanSpec) Name() string { return predicates.ClientCertSANName }
+
+func (*sanSpec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	p := &sanPredicate{}
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok || s == "" {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		p.names = append(p.names, s)
+	}
+
+	return p, nil
+}
+
+func (p *sanPredicate) has(name string) bool {
+	for _, n := range p.names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *sanPredicate) Match(r *http.Request) bool {
+	c := snet.VerifiedClientCertificate(r)
+	if c == nil {
+		return false
+	}
+
+	for _, name := range c.DNSNames {
+		if p.has(name) {
+			return true
+		}
+	}
+
+	for _, name := range c.EmailAddresses {
+		if p.has(name) {
+			return true
+		}
+	}
+
+	for _, ip := range c.IPAddresses {
+		if p.has(ip.String()) {
+			return true
+		}
+	}
+
+	for _, uri := range c.URIs {
+		if p.has(uri.String()) {
+			return true
+		}
+	}
+
+	return false
+}
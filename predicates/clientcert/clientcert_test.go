@@ -0,0 +1,170 @@
+package clientcert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/zalando/skipper/routing"
+)
+
+func testCertificate() *x509.Certificate {
+	spiffe, _ := url.Parse("spiffe://example.org/orders")
+	return &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "client.example.org",
+			Organization:       []string{"Example"},
+			OrganizationalUnit: []string{"payment"},
+		},
+		DNSNames:       []string{"orders.example.org"},
+		EmailAddresses: []string{"orders@example.org"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs:           []*url.URL{spiffe},
+	}
+}
+
+func testRequest(state *tls.ConnectionState) *http.Request {
+	r, _ := http.NewRequest("GET", "https://www.example.org", nil)
+	r.TLS = state
+	return r
+}
+
+func verified() *tls.ConnectionState {
+	c := testCertificate()
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{c},
+		VerifiedChains:   [][]*x509.Certificate{{c}},
+	}
+}
+
+func unverified() *tls.ConnectionState {
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{testCertificate()}}
+}
+
+func TestCreate(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		spec  routing.PredicateSpec
+		args  []interface{}
+		fail  bool
+	}{{
+		title: "subject, no args",
+		spec:  NewSubject(),
+		fail:  true,
+	}, {
+		title: "subject, too many args",
+		spec:  NewSubject(),
+		args:  []interface{}{"a", "b"},
+		fail:  true,
+	}, {
+		title: "subject, invalid regexp",
+		spec:  NewSubject(),
+		args:  []interface{}{`\`},
+		fail:  true,
+	}, {
+		title: "subject",
+		spec:  NewSubject(),
+		args:  []interface{}{"^CN=client"},
+	}, {
+		title: "SAN, no args",
+		spec:  NewSAN(),
+		fail:  true,
+	}, {
+		title: "SAN, invalid arg",
+		spec:  NewSAN(),
+		args:  []interface{}{"a", float64(1)},
+		fail:  true,
+	}, {
+		title: "SAN",
+		spec:  NewSAN(),
+		args:  []interface{}{"a", "b"},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			_, err := test.spec.Create(test.args)
+			if test.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !test.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		spec     routing.PredicateSpec
+		args     []interface{}
+		state    *tls.ConnectionState
+		expected bool
+	}{{
+		title: "subject, no TLS",
+		spec:  NewSubject(),
+		args:  []interface{}{"CN=client"},
+	}, {
+		title: "subject, not verified",
+		spec:  NewSubject(),
+		args:  []interface{}{"CN=client"},
+		state: unverified(),
+	}, {
+		title:    "subject, matching",
+		spec:     NewSubject(),
+		args:     []interface{}{"^CN=client[.]example[.]org,OU=payment(,|$)"},
+		state:    verified(),
+		expected: true,
+	}, {
+		title: "subject, not matching",
+		spec:  NewSubject(),
+		args:  []interface{}{"OU=shipping"},
+		state: verified(),
+	}, {
+		title: "SAN, not verified",
+		spec:  NewSAN(),
+		args:  []interface{}{"orders.example.org"},
+		state: unverified(),
+	}, {
+		title:    "SAN, DNS name",
+		spec:     NewSAN(),
+		args:     []interface{}{"foo", "orders.example.org"},
+		state:    verified(),
+		expected: true,
+	}, {
+		title:    "SAN, email",
+		spec:     NewSAN(),
+		args:     []interface{}{"orders@example.org"},
+		state:    verified(),
+		expected: true,
+	}, {
+		title:    "SAN, IP",
+		spec:     NewSAN(),
+		args:     []interface{}{"10.0.0.1"},
+		state:    verified(),
+		expected: true,
+	}, {
+		title:    "SAN, URI",
+		spec:     NewSAN(),
+		args:     []interface{}{"spiffe://example.org/orders"},
+		state:    verified(),
+		expected: true,
+	}, {
+		title: "SAN, not matching",
+		spec:  NewSAN(),
+		args:  []interface{}{"spiffe://example.org/payment", "client.example.org"},
+		state: verified(),
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			p, err := test.spec.Create(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if m := p.Match(testRequest(test.state)); m != test.expected {
+				t.Errorf("invalid match result, expected: %t, got: %t", test.expected, m)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Package listener implements a predicate to match routes based on the
+name of the listener, that accepted the request.
+
+Skipper can accept requests on multiple listeners, e.g. on a public and
+on an internal port. The main proxy listener is called "default", while
+the additional listeners have the names set in their configuration. The
+routes without the Listener predicate match the requests from any of the
+listeners.
+
+Examples:
+
+	// only match the requests received on the internal listener
+	internal: Listener("internal") -> "http://internal.example.org";
+
+	// match the requests received on the main or on the public listener
+	public: Listener("default", "public") -> "http://www.example.org";
+*/
+package listener
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+// DefaultName is the name of the main proxy listener.
+const DefaultName = "default"
+
+type contextKey struct{}
+
+type (
+	spec struct{}
+
+	predicate struct {
+		names []string
+	}
+)
+
+// NewContext returns a context storing the name of the listener. It
+// is used as the base context of the requests received by the listener.
+func NewContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// FromContext returns the name of the listener stored in the context.
+func FromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(contextKey{}).(string)
+	return name, ok
+}
+
+// New creates a predicate specification, whose instances match the
+// requests received by the listeners with the names passed in as the
+// arguments.
+//
+// Eskip example:
+//
+// 	Listener("internal") -> "https://internal.example.org";
+//
+func New() routing.PredicateSpec { return &spec{} }
+
+func (*spec) Name() string { return predicates.ListenerName }
+
+func (*spec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	p := &predicate{}
+	for _, a := range args {
+		name, ok := a.(string)
+		if !ok || name == "" {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		p.names = append(p.names, name)
+	}
+
+	return p, nil
+}
+
+func (p *predicate) Match(r *http.Request) bool {
+	name, ok := FromContext(r.Context())
+	if !ok {
+		return false
+	}
+
+	for _, n := range p.names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
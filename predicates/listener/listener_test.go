@@ -0,0 +1,91 @@
+package listener
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestListenerArgs(t *testing.T) {
+	for _, test := range []struct {
+		title string
+		args  []interface{}
+		fail  bool
+	}{{
+		title: "no args",
+		fail:  true,
+	}, {
+		title: "invalid type",
+		args:  []interface{}{float64(1)},
+		fail:  true,
+	}, {
+		title: "empty name",
+		args:  []interface{}{""},
+		fail:  true,
+	}, {
+		title: "one name",
+		args:  []interface{}{"internal"},
+	}, {
+		title: "multiple names",
+		args:  []interface{}{"default", "internal"},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			_, err := New().Create(test.args)
+			if test.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !test.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestListenerMatch(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		args     []interface{}
+		listener string
+		noName   bool
+		expected bool
+	}{{
+		title:    "no listener name in the context",
+		args:     []interface{}{DefaultName},
+		noName:   true,
+		expected: false,
+	}, {
+		title:    "matching",
+		args:     []interface{}{"internal"},
+		listener: "internal",
+		expected: true,
+	}, {
+		title:    "not matching",
+		args:     []interface{}{"internal"},
+		listener: DefaultName,
+		expected: false,
+	}, {
+		title:    "matching one of multiple",
+		args:     []interface{}{DefaultName, "public"},
+		listener: "public",
+		expected: true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			p, err := New().Create(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := http.NewRequest("GET", "http://www.example.org", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.noName {
+				r = r.WithContext(NewContext(context.Background(), test.listener))
+			}
+
+			if m := p.Match(r); m != test.expected {
+				t.Errorf("invalid match result, expected: %t, got: %t", test.expected, m)
+			}
+		})
+	}
+}
@@ -42,4 +42,7 @@ const (
 	ClientIPName              = "ClientIP"
 	TeeName                   = "Tee"
 	TrafficName               = "Traffic"
+	ListenerName              = "Listener"
+	ClientCertSubjectName     = "ClientCertSubject"
+	ClientCertSANName         = "ClientCertSAN"
 )
@@ -20,17 +20,30 @@ type shutdown struct {
 // NewShutdown provides a predicate spec to create predicates
 // that evaluate to true if Skipper is shutting down
 func NewShutdown() routing.PredicateSpec {
-	s, _ := newShutdown()
+	s, _ := newShutdown(nil)
 	return s
 }
 
-func newShutdown() (routing.PredicateSpec, chan os.Signal) {
+// NewShutdownDraining provides a predicate spec to create predicates
+// that evaluate to true if Skipper is shutting down, or when the
+// draining channel is closed
+func NewShutdownDraining(draining <-chan struct{}) routing.PredicateSpec {
+	s, _ := newShutdown(draining)
+	return s
+}
+
+func newShutdown(draining <-chan struct{}) (routing.PredicateSpec, chan os.Signal) {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGTERM)
 	s := &shutdown{}
 	go func() {
-		<-sigs
-		log.Infof("Got shutdown signal for %s predicates", s.Name())
+		select {
+		case <-sigs:
+			log.Infof("Got shutdown signal for %s predicates", s.Name())
+		case <-draining:
+			log.Infof("Draining started for %s predicates", s.Name())
+		}
+
 		atomic.StoreInt32(&s.inShutdown, 1)
 	}()
 	return s, sigs
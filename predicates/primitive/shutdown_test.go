@@ -8,7 +8,7 @@ import (
 )
 
 func TestShutdown(t *testing.T) {
-	s, sigs := newShutdown()
+	s, sigs := newShutdown(nil)
 	p, err := s.Create([]interface{}{})
 	if err != nil {
 		t.Fatal(err)
@@ -26,3 +26,24 @@ func TestShutdown(t *testing.T) {
 		t.Error("expected shutdown")
 	}
 }
+
+func TestShutdownDraining(t *testing.T) {
+	draining := make(chan struct{})
+	s := NewShutdownDraining(draining)
+	p, err := s.Create([]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("GET", "https://www.example.org", nil)
+
+	if p.Match(req) {
+		t.Error("unexpected shutdown")
+	}
+
+	close(draining)
+	time.Sleep(100 * time.Millisecond)
+
+	if !p.Match(req) {
+		t.Error("expected shutdown")
+	}
+}
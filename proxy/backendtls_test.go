@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testClientCertificate(t *testing.T, cn string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestBackendTLSProfiles(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	backend.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	backend.StartTLS()
+	defer backend.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(backend.Certificate())
+
+	profile := func(cn string) *tls.Config {
+		c := testClientCertificate(t, cn)
+		return &tls.Config{
+			RootCAs: roots,
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &c, nil
+			},
+		}
+	}
+
+	doc := fmt.Sprintf(`
+		a: Path("/a") -> backendTLS("a") -> "%[1]s";
+		b: Path("/b") -> backendTLS("b") -> "%[1]s";
+		none: Path("/none") -> "%[1]s";
+		unknown: Path("/unknown") -> backendTLS("unknown") -> "%[1]s";
+	`, backend.URL)
+
+	tp, err := newTestProxyWithParams(doc, Params{
+		BackendTLSProfiles: map[string]*tls.Config{
+			"a": profile("client-a"),
+			"b": profile("client-b"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	for _, test := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/a", status: http.StatusOK, body: "client-a"},
+		{path: "/b", status: http.StatusOK, body: "client-b"},
+		{path: "/none", status: http.StatusInternalServerError},
+		{path: "/unknown", status: http.StatusBadGateway},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			rsp, err := http.Get(ps.URL + test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rsp.Body.Close()
+
+			if rsp.StatusCode != test.status {
+				t.Fatalf("invalid status, expected: %d, got: %d", test.status, rsp.StatusCode)
+			}
+
+			if test.body == "" {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != test.body {
+				t.Errorf("invalid client certificate, expected: %s, got: %s", test.body, b)
+			}
+		})
+	}
+}
@@ -63,6 +63,10 @@ const (
 	// DefaultExpectContinueTimeout, the default timeout to expect
 	// a response for a 100 Continue request
 	DefaultExpectContinueTimeout = 30 * time.Second
+
+	// DefaultUpgradeDrainTimeout, the default time the upgraded
+	// connections are kept open after draining started
+	DefaultUpgradeDrainTimeout = 30 * time.Second
 )
 
 // Flags control the behavior of the proxy.
@@ -157,6 +161,16 @@ type Params struct {
 	// and the response messages during web socket upgrades.
 	ExperimentalUpgradeAudit bool
 
+	// Draining, when closed, signals that the instance started
+	// draining. The upgraded connections are closed after
+	// UpgradeDrainTimeout.
+	Draining <-chan struct{}
+
+	// UpgradeDrainTimeout sets the time the upgraded connections are
+	// kept open after draining started. Defaults to
+	// DefaultUpgradeDrainTimeout.
+	UpgradeDrainTimeout time.Duration
+
 	// When set, no access log is printed.
 	AccessLogDisabled bool
 
@@ -228,6 +242,12 @@ type Params struct {
 	// Client TLS to connect to Backends
 	ClientTLS *tls.Config
 
+	// BackendTLSProfiles contains named TLS configurations for the
+	// backend connections, that the routes can select with the
+	// backendTLS filter. The proxy keeps a separate connection pool for
+	// each profile.
+	BackendTLSProfiles map[string]*tls.Config
+
 	// OpenTracing contains parameters related to OpenTracing instrumentation. For default values
 	// check OpenTracingParams
 	OpenTracing *OpenTracingParams
@@ -262,6 +282,10 @@ var (
 		code:             http.StatusServiceUnavailable,
 		additionalHeader: http.Header{"X-Circuit-Open": []string{"true"}},
 	}
+	errDeadlineExceeded = &proxyError{
+		err:  errors.New("deadline exceeded in scheduler queue"),
+		code: http.StatusGatewayTimeout,
+	}
 
 	disabledAccessLog = al.AccessLogFilter{Enable: false, Prefixes: nil}
 	enabledAccessLog  = al.AccessLogFilter{Enable: true, Prefixes: nil}
@@ -316,6 +340,7 @@ type Proxy struct {
 	defaultHTTPStatus        int
 	routing                  *routing.Routing
 	roundTripper             http.RoundTripper
+	tlsProfiles              map[string]*tlsProfile
 	priorityRoutes           []PriorityRoute
 	flags                    Flags
 	metrics                  metrics.Metrics
@@ -329,10 +354,18 @@ type Proxy struct {
 	upgradeAuditLogOut       io.Writer
 	upgradeAuditLogErr       io.Writer
 	auditLogHook             chan struct{}
+	upgradesDrained          chan struct{}
 	clientTLS                *tls.Config
 	hostname                 string
 }
 
+// tlsProfile is a named backend TLS configuration, with its own
+// connection pool.
+type tlsProfile struct {
+	roundTripper http.RoundTripper
+	tlsConfig    *tls.Config
+}
+
 // proxyError is used to wrap errors during proxying and to indicate
 // the required status code for the response sent from the main
 // ServeHTTP method. Alternatively, it can indicate that the request
@@ -499,6 +532,11 @@ func mapRequest(ctx *context, requestContext stdlibcontext.Context, removeHopHea
 	}
 
 	rr.ContentLength = r.ContentLength
+	if body != nil {
+		// replayable bodies, e.g. buffered by the bufferRequest filter
+		rr.GetBody = r.GetBody
+	}
+
 	if removeHopHeaders {
 		rr.Header = cloneHeaderExcluding(r.Header, hopHeaders)
 	} else {
@@ -614,20 +652,47 @@ func WithParams(p Params) *Proxy {
 		}
 	}
 
-	tr := &http.Transport{
-		DialContext: newSkipperDialer(net.Dialer{
-			Timeout:   p.Timeout,
-			KeepAlive: p.KeepAlive,
-			DualStack: p.DualStack,
-		}).DialContext,
-		TLSHandshakeTimeout:   p.TLSHandshakeTimeout,
-		ResponseHeaderTimeout: p.ResponseHeaderTimeout,
-		ExpectContinueTimeout: p.ExpectContinueTimeout,
-		MaxIdleConns:          p.MaxIdleConns,
-		MaxIdleConnsPerHost:   p.IdleConnectionsPerHost,
-		IdleConnTimeout:       p.CloseIdleConnsPeriod,
-		DisableKeepAlives:     p.DisableHTTPKeepalives,
-		Proxy:                 proxyFromHeader,
+	newTransport := func(tlsConfig *tls.Config) *http.Transport {
+		tr := &http.Transport{
+			DialContext: newSkipperDialer(net.Dialer{
+				Timeout:   p.Timeout,
+				KeepAlive: p.KeepAlive,
+				DualStack: p.DualStack,
+			}).DialContext,
+			TLSHandshakeTimeout:   p.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: p.ResponseHeaderTimeout,
+			ExpectContinueTimeout: p.ExpectContinueTimeout,
+			MaxIdleConns:          p.MaxIdleConns,
+			MaxIdleConnsPerHost:   p.IdleConnectionsPerHost,
+			IdleConnTimeout:       p.CloseIdleConnsPeriod,
+			DisableKeepAlives:     p.DisableHTTPKeepalives,
+			Proxy:                 proxyFromHeader,
+			TLSClientConfig:       tlsConfig,
+		}
+
+		if p.Flags.Insecure() {
+			if tr.TLSClientConfig == nil {
+				/* #nosec */
+				tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			} else {
+				/* #nosec */
+				tr.TLSClientConfig.InsecureSkipVerify = true
+			}
+		}
+
+		return tr
+	}
+
+	tr := newTransport(p.ClientTLS)
+	transports := []*http.Transport{tr}
+	tlsProfiles := make(map[string]*tlsProfile)
+	for name, c := range p.BackendTLSProfiles {
+		ptr := newTransport(c.Clone())
+		transports = append(transports, ptr)
+		tlsProfiles[name] = &tlsProfile{
+			roundTripper: p.CustomHttpRoundTripperWrap(ptr),
+			tlsConfig:    ptr.TLSClientConfig,
+		}
 	}
 
 	quit := make(chan struct{})
@@ -639,7 +704,9 @@ func WithParams(p Params) *Proxy {
 			for {
 				select {
 				case <-time.After(p.CloseIdleConnsPeriod):
-					tr.CloseIdleConnections()
+					for _, t := range transports {
+						t.CloseIdleConnections()
+					}
 				case <-quit:
 					return
 				}
@@ -647,18 +714,26 @@ func WithParams(p Params) *Proxy {
 		}()
 	}
 
-	if p.ClientTLS != nil {
-		tr.TLSClientConfig = p.ClientTLS
-	}
-
-	if p.Flags.Insecure() {
-		if tr.TLSClientConfig == nil {
-			/* #nosec */
-			tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		} else {
-			/* #nosec */
-			tr.TLSClientConfig.InsecureSkipVerify = true
+	// closed, when the upgraded connections need to be closed
+	upgradesDrained := make(chan struct{})
+	if p.Draining != nil {
+		if p.UpgradeDrainTimeout <= 0 {
+			p.UpgradeDrainTimeout = DefaultUpgradeDrainTimeout
 		}
+
+		go func() {
+			select {
+			case <-p.Draining:
+			case <-quit:
+				return
+			}
+
+			select {
+			case <-time.After(p.UpgradeDrainTimeout):
+				close(upgradesDrained)
+			case <-quit:
+			}
+		}()
 	}
 
 	m := metrics.Default
@@ -683,6 +758,7 @@ func WithParams(p Params) *Proxy {
 	return &Proxy{
 		routing:                  p.Routing,
 		roundTripper:             p.CustomHttpRoundTripperWrap(tr),
+		tlsProfiles:              tlsProfiles,
 		priorityRoutes:           p.PriorityRoutes,
 		flags:                    p.Flags,
 		metrics:                  m,
@@ -700,6 +776,7 @@ func WithParams(p Params) *Proxy {
 		accessLogDisabled:        p.AccessLogDisabled,
 		upgradeAuditLogOut:       os.Stdout,
 		upgradeAuditLogErr:       os.Stderr,
+		upgradesDrained:          upgradesDrained,
 		clientTLS:                tr.TLSClientConfig,
 		hostname:                 hostname,
 	}
@@ -852,15 +929,23 @@ func (p *Proxy) makeUpgradeRequest(ctx *context, req *http.Request) error {
 
 	reverseProxy := httputil.NewSingleHostReverseProxy(backendURL)
 	reverseProxy.FlushInterval = p.flushInterval
+	tlsClientConfig := p.clientTLS
+	if profile, err := p.getTLSProfile(ctx); err != nil {
+		return err
+	} else if profile != nil {
+		tlsClientConfig = profile.tlsConfig
+	}
+
 	upgradeProxy := upgradeProxy{
 		backendAddr:     backendURL,
 		reverseProxy:    reverseProxy,
 		insecure:        p.flags.Insecure(),
-		tlsClientConfig: p.clientTLS,
+		tlsClientConfig: tlsClientConfig,
 		useAuditLog:     p.experimentalUpgradeAudit,
 		auditLogOut:     p.upgradeAuditLogOut,
 		auditLogErr:     p.upgradeAuditLogErr,
 		auditLogHook:    p.auditLogHook,
+		drained:         p.upgradesDrained,
 	}
 
 	upgradeProxy.serveHTTP(ctx.responseWriter, req)
@@ -914,6 +999,10 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 		setTag(ctx.proxySpan, SkipperRouteIDTag, ctx.route.Id).
 		setTag(ctx.proxySpan, SkipperRouteTag, ctx.route.String()).
 		setTag(ctx.proxySpan, HTTPUrlTag, u.String())
+	for k, v := range ctx.route.Metadata {
+		p.tracing.setTag(ctx.proxySpan, SkipperRouteMetadataTagPrefix+k, v)
+	}
+
 	p.setCommonSpanInfo(u, req, ctx.proxySpan)
 
 	carrier := ot.HTTPHeadersCarrier(req.Header)
@@ -946,6 +1035,13 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 
 		ctx.proxySpan.LogKV("event", "error", "message", err.Error())
 
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			p.log.Errorf("Request body exceeds the limit of %d bytes, roundtrip to %s aborted", maxBytesErr.Limit, ctx.route.Backend)
+			p.tracing.setTag(ctx.proxySpan, HTTPStatusCodeTag, uint16(http.StatusRequestEntityTooLarge))
+			return nil, &proxyError{err: err, code: http.StatusRequestEntityTooLarge}
+		}
+
 		if perr, ok := err.(*proxyError); ok {
 			p.log.Errorf("Failed to do backend roundtrip to %s: %v", ctx.route.Backend, perr)
 			//p.lb.AddHealthcheck(ctx.route.Backend)
@@ -996,10 +1092,35 @@ func (p *Proxy) getRoundTripper(ctx *context, req *http.Request) (http.RoundTrip
 
 		return rt, nil
 	default:
+		profile, err := p.getTLSProfile(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if profile != nil {
+			return profile.roundTripper, nil
+		}
+
 		return p.roundTripper, nil
 	}
 }
 
+// getTLSProfile returns the backend TLS profile selected by the
+// backendTLS filter, or nil, when the route doesn't select one.
+func (p *Proxy) getTLSProfile(ctx *context) (*tlsProfile, error) {
+	name, ok := ctx.StateBag()[filters.BackendTLSProfile].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	profile, ok := p.tlsProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend TLS profile: %s", name)
+	}
+
+	return profile, nil
+}
+
 func (p *Proxy) rejectBackend(ctx *context, req *http.Request) (*http.Response, bool) {
 	limit, ok := ctx.StateBag()[filters.BackendRatelimit].(*ratelimitfilters.BackendRatelimit)
 	if ok {
@@ -1055,6 +1176,11 @@ func (p *Proxy) do(ctx *context) error {
 		for _, done := range pendingLIFO {
 			done()
 		}
+
+		pendingFIFO, _ := ctx.StateBag()[scheduler.FIFOKey].([]func())
+		for _, done := range pendingFIFO {
+			done()
+		}
 	}()
 
 	// proxy global setting
@@ -1112,6 +1238,17 @@ func (p *Proxy) do(ctx *context) error {
 		ctx.setResponse(&http.Response{Header: make(http.Header)}, p.flags.PreserveOriginal())
 	} else {
 
+		backendTimeout, hasBackendTimeout := ctx.StateBag()[filters.BackendTimeout].(time.Duration)
+		if hasBackendTimeout {
+			// time spent in scheduler queues counts against the backend timeout
+			if queued, ok := ctx.StateBag()[filters.BackendQueueTime].(time.Duration); ok {
+				backendTimeout -= queued
+				if backendTimeout <= 0 {
+					return errDeadlineExceeded
+				}
+			}
+		}
+
 		done, allow := p.checkBreaker(ctx)
 		if !allow {
 			tracing.LogKV("circuit_breaker", "open", ctx.request.Context())
@@ -1119,8 +1256,8 @@ func (p *Proxy) do(ctx *context) error {
 		}
 
 		backendContext := ctx.request.Context()
-		if timeout, ok := ctx.StateBag()[filters.BackendTimeout]; ok {
-			backendContext, ctx.cancelBackendContext = stdlibcontext.WithTimeout(backendContext, timeout.(time.Duration))
+		if hasBackendTimeout {
+			backendContext, ctx.cancelBackendContext = stdlibcontext.WithTimeout(backendContext, backendTimeout)
 		}
 
 		backendStart := time.Now()
@@ -1140,6 +1277,16 @@ func (p *Proxy) do(ctx *context) error {
 
 				tracing.LogKV("retry", ctx.route.Id, ctx.Request().Context())
 
+				if ctx.request.GetBody != nil {
+					body, err := ctx.request.GetBody()
+					if err != nil {
+						p.log.Errorf("Failed to get request body for retry: %v", err)
+						return perr
+					}
+
+					ctx.request.Body = body
+				}
+
 				perr = nil
 				var perr2 *proxyError
 				rsp, perr2 = p.makeBackendRequest(ctx, backendContext)
@@ -1174,7 +1321,7 @@ func (p *Proxy) do(ctx *context) error {
 }
 
 func retryable(req *http.Request) bool {
-	return req != nil && (req.Body == nil || req.Body == http.NoBody)
+	return req != nil && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
 }
 
 func (p *Proxy) serveResponse(ctx *context) {
@@ -1419,13 +1566,17 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				Duration:     time.Since(ctx.startServe),
 			}
 
+			if ctx.route != nil {
+				entry.RouteMetadata = ctx.route.Metadata
+			}
+
 			additionalData, _ := ctx.stateBag[al.AccessLogAdditionalDataKey].(map[string]interface{})
 
 			logging.LogAccess(entry, additionalData)
 		}
 
 		// This flush is required in I/O error
-		if !ctx.successfulUpgrade {
+		if !ctx.successfulUpgrade && !lw.Hijacked() {
 			lw.Flush()
 		}
 	}()
@@ -1454,9 +1605,14 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err := p.do(ctx)
 
-	if err != nil {
+	switch {
+	case lw.Hijacked() && !ctx.successfulUpgrade:
+		// the connection was taken over by a filter, e.g. to inject
+		// a connection reset, there is nothing to respond
+		p.log.Debugf("connection hijacked by a filter on route %s", ctx.route.Id)
+	case err != nil:
 		p.errorResponse(ctx, err)
-	} else {
+	default:
 		p.serveResponse(ctx)
 	}
 
@@ -325,6 +325,12 @@ func TestRetryable(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create request with body: %v", err)
 	}
+	// incoming requests don't have GetBody, only buffered ones
+	reqWithBody.GetBody = nil
+	reqWithReplayableBody, err := http.NewRequest("GET", "http://www.zalando.de", bytes.NewBufferString("hello"))
+	if err != nil {
+		t.Fatalf("Failed to create request with replayable body: %v", err)
+	}
 
 	for _, tt := range []struct {
 		name string
@@ -350,6 +356,11 @@ func TestRetryable(t *testing.T) {
 			name: "test request with body",
 			req:  reqWithBody,
 			want: false,
+		},
+		{
+			name: "test request with replayable body",
+			req:  reqWithReplayableBody,
+			want: true,
 		}} {
 		t.Run(tt.name, func(t *testing.T) {
 			got := retryable(tt.req)
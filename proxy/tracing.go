@@ -21,6 +21,10 @@ const (
 	SkipperRouteIDTag     = "skipper.route_id"
 	SpanKindTag           = "span.kind"
 
+	// SkipperRouteMetadataTagPrefix is the prefix of the tags set from
+	// the route metadata, e.g. skipper.route.metadata.owner
+	SkipperRouteMetadataTagPrefix = "skipper.route.metadata."
+
 	ClientRequestCanceled = "canceled"
 	SpanKindClient        = "client"
 	SpanKindServer        = "server"
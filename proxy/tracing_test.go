@@ -236,7 +236,7 @@ func TestTracingProxySpan(t *testing.T) {
 	}))
 	defer s.Close()
 
-	doc := fmt.Sprintf(`hello: Path("/hello") -> setPath("/bye") -> setQuery("void") -> "%s"`, s.URL)
+	doc := fmt.Sprintf(`hello [owner="team-a"]: Path("/hello") -> setPath("/bye") -> setQuery("void") -> "%s"`, s.URL)
 	tracer := mocktracer.New()
 
 	t.Setenv("HOSTNAME", "proxy.tracing.test")
@@ -273,7 +273,8 @@ func TestTracingProxySpan(t *testing.T) {
 
 	verifyTag(t, span, SpanKindTag, SpanKindClient)
 	verifyTag(t, span, SkipperRouteIDTag, "hello")
-	verifyTag(t, span, SkipperRouteTag, strings.TrimPrefix(doc, "hello: "))
+	verifyTag(t, span, SkipperRouteTag, strings.TrimPrefix(doc, `hello [owner="team-a"]: `))
+	verifyTag(t, span, SkipperRouteMetadataTagPrefix+"owner", "team-a")
 	verifyTag(t, span, ComponentTag, "skipper")
 	verifyTag(t, span, HTTPUrlTag, "http://"+backendAddr+"/bye") // proxy removes query
 	verifyTag(t, span, HTTPMethodTag, "GET")
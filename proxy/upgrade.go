@@ -46,6 +46,7 @@ type upgradeProxy struct {
 	auditLogOut     io.Writer
 	auditLogErr     io.Writer
 	auditLogHook    chan struct{}
+	drained         <-chan struct{}
 }
 
 // TODO: add user here
@@ -162,10 +163,15 @@ func (p *upgradeProxy) serveHTTP(w http.ResponseWriter, req *http.Request) {
 
 	log.Debugf("Successfully upgraded to protocol %s by user request", getUpgradeRequest(req))
 
-	// Wait for either copyAsync to complete.
+	// Wait for either copyAsync to complete, or for the end of the
+	// draining grace period.
 	// Return from this method closes both request and backend connections via defer
 	// and thus unblocks the second copyAsync.
-	<-done
+	select {
+	case <-done:
+	case <-p.drained:
+		log.Debugf("Closing upgraded connection %s, draining", getUpgradeRequest(req))
+	}
 
 	if p.useAuditLog {
 		select {
@@ -438,3 +438,66 @@ func TestAuditLogging(t *testing.T) {
 		}
 	}))
 }
+
+func TestUpgradeDraining(t *testing.T) {
+	wss := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(ws, ws)
+	}))
+	defer wss.Close()
+
+	tl := loggingtest.New()
+	rt := routing.New(routing.Options{
+		DataClients: []routing.DataClient{
+			testdataclient.New([]*eskip.Route{{Backend: wss.URL}}),
+		},
+		Log: tl,
+	})
+	defer rt.Close()
+
+	if err := tl.WaitFor("route settings applied", 120*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	draining := make(chan struct{})
+	p := WithParams(Params{
+		Routing:             rt,
+		ExperimentalUpgrade: true,
+		Draining:            draining,
+		UpgradeDrainTimeout: 300 * time.Millisecond,
+	})
+	defer p.Close()
+
+	ps := httptest.NewServer(p)
+	defer ps.Close()
+
+	wsc, err := websocket.Dial(strings.Replace(ps.URL, "http:", "ws:", 1), "", "http://[::1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wsc.Close()
+
+	echo := func() error {
+		if _, err := wsc.Write([]byte("foo")); err != nil {
+			return err
+		}
+
+		receive := make([]byte, 3)
+		_, err := io.ReadFull(wsc, receive)
+		return err
+	}
+
+	if err := echo(); err != nil {
+		t.Fatal(err)
+	}
+
+	close(draining)
+	if err := echo(); err != nil {
+		t.Fatalf("upgraded connection closed before the drain timeout: %v", err)
+	}
+
+	time.Sleep(600 * time.Millisecond)
+	wsc.SetReadDeadline(time.Now().Add(time.Second))
+	if err := echo(); err == nil {
+		t.Fatal("failed to close the upgraded connection after the drain timeout")
+	}
+}
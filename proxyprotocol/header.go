@@ -0,0 +1,377 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxV1HeaderLength = 107
+	v2HeaderLength    = 16
+
+	v2CommandLocal = 0x0
+	v2CommandProxy = 0x1
+
+	v2FamilyUnspec = 0x0
+	v2FamilyInet   = 0x1
+	v2FamilyInet6  = 0x2
+	v2FamilyUnix   = 0x3
+
+	v2TransportStream = 0x1
+	v2TransportDgram  = 0x2
+)
+
+// TLV types, as defined by the PROXY protocol v2 specification.
+const (
+	TypeALPN      byte = 0x01
+	TypeAuthority byte = 0x02
+	TypeCRC32C    byte = 0x03
+	TypeNoop      byte = 0x04
+	TypeUniqueID  byte = 0x05
+	TypeSSL       byte = 0x20
+	TypeNetNS     byte = 0x30
+
+	// sub-types of the SSL TLV
+	TypeSSLVersion byte = 0x21
+	TypeSSLCN      byte = 0x22
+	TypeSSLCipher  byte = 0x23
+	TypeSSLSigAlg  byte = 0x24
+	TypeSSLKeyAlg  byte = 0x25
+)
+
+// Flags of the client field in the SSL TLV.
+const (
+	SSLClientSSL      byte = 0x01
+	SSLClientCertConn byte = 0x02
+	SSLClientCertSess byte = 0x04
+)
+
+var (
+	v1Signature = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	// ErrInvalidHeader is returned, when the connection starts with a
+	// PROXY protocol signature, but the header is invalid.
+	ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+)
+
+// TLV is a type-length-value field of a PROXY protocol v2 header.
+type TLV struct {
+	Type  byte
+	Value []byte
+}
+
+// Header contains the information received in a PROXY protocol header.
+type Header struct {
+
+	// Version is 1 or 2.
+	Version int
+
+	// Local is true, when the connection was established by the proxy
+	// itself, e.g. for health checking. In this case, the source and
+	// the destination are not set.
+	Local bool
+
+	// Source is the address of the original client.
+	Source net.Addr
+
+	// Destination is the original destination address.
+	Destination net.Addr
+
+	// TLVs contains the additional fields of the v2 headers.
+	TLVs []TLV
+}
+
+// SSL contains the information of the SSL TLV, that is sent by the
+// proxies terminating TLS.
+type SSL struct {
+
+	// Client is a bitmask of the SSLClient* flags.
+	Client byte
+
+	// Verify is 0 when the client presented a certificate that was
+	// successfully verified.
+	Verify uint32
+
+	// TLVs contains the sub-fields, e.g. TypeSSLVersion or
+	// TypeSSLCN.
+	TLVs []TLV
+}
+
+func parseTLVs(b []byte) ([]TLV, error) {
+	var tlvs []TLV
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, ErrInvalidHeader
+		}
+
+		l := int(binary.BigEndian.Uint16(b[1:3]))
+		if len(b) < 3+l {
+			return nil, ErrInvalidHeader
+		}
+
+		tlvs = append(tlvs, TLV{Type: b[0], Value: b[3 : 3+l]})
+		b = b[3+l:]
+	}
+
+	return tlvs, nil
+}
+
+func findTLV(tlvs []TLV, t byte) ([]byte, bool) {
+	for _, tlv := range tlvs {
+		if tlv.Type == t {
+			return tlv.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// TLV returns the value of the first TLV with the given type.
+func (h *Header) TLV(t byte) ([]byte, bool) {
+	return findTLV(h.TLVs, t)
+}
+
+// ALPN returns the application protocol negotiated by the proxy.
+func (h *Header) ALPN() string {
+	v, _ := h.TLV(TypeALPN)
+	return string(v)
+}
+
+// Authority returns the host name sent by the client, e.g. with SNI.
+func (h *Header) Authority() string {
+	v, _ := h.TLV(TypeAuthority)
+	return string(v)
+}
+
+// UniqueID returns the connection ID assigned by the proxy.
+func (h *Header) UniqueID() []byte {
+	v, _ := h.TLV(TypeUniqueID)
+	return v
+}
+
+// SSL returns the SSL information, when the proxy sent it.
+func (h *Header) SSL() (*SSL, bool) {
+	v, ok := h.TLV(TypeSSL)
+	if !ok || len(v) < 5 {
+		return nil, false
+	}
+
+	tlvs, err := parseTLVs(v[5:])
+	if err != nil {
+		return nil, false
+	}
+
+	return &SSL{
+		Client: v[0],
+		Verify: binary.BigEndian.Uint32(v[1:5]),
+		TLVs:   tlvs,
+	}, true
+}
+
+// TLV returns the value of the first sub-field with the given type.
+func (s *SSL) TLV(t byte) ([]byte, bool) {
+	return findTLV(s.TLVs, t)
+}
+
+// Version returns the TLS version used by the client.
+func (s *SSL) Version() string {
+	v, _ := s.TLV(TypeSSLVersion)
+	return string(v)
+}
+
+// CommonName returns the common name of the client certificate.
+func (s *SSL) CommonName() string {
+	v, _ := s.TLV(TypeSSLCN)
+	return string(v)
+}
+
+// Cipher returns the cipher used by the connection.
+func (s *SSL) Cipher() string {
+	v, _ := s.TLV(TypeSSLCipher)
+	return string(v)
+}
+
+// ClientCertVerified tells whether the client presented a certificate,
+// that the proxy verified successfully.
+func (s *SSL) ClientCertVerified() bool {
+	return s.Client&(SSLClientCertConn|SSLClientCertSess) != 0 && s.Verify == 0
+}
+
+// readHeader reads the PROXY protocol header from the reader. When the
+// input doesn't start with a PROXY protocol signature, it returns nil,
+// without consuming any input.
+func readHeader(r *bufio.Reader) (*Header, error) {
+	if b, err := r.Peek(len(v1Signature)); err == nil && bytes.Equal(b, v1Signature) {
+		return readV1(r)
+	}
+
+	if b, err := r.Peek(len(v2Signature)); err == nil && bytes.Equal(b, v2Signature) {
+		return readV2(r)
+	}
+
+	// a connection closed by the client before sending anything is not
+	// an error of the header
+	if _, err := r.Peek(1); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func readV1(r *bufio.Reader) (*Header, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+
+		if len(line) >= maxV1HeaderLength {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, ErrInvalidHeader
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	h := &Header{Version: 1}
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		h.Local = true
+		return h, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrInvalidHeader
+	}
+
+	src, err := parseV1Addr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+
+	dst, err := parseV1Addr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+
+	h.Source, h.Destination = src, dst
+	return h, nil
+}
+
+func parseV1Addr(protocol, ip, port string) (*net.TCPAddr, error) {
+	a := net.ParseIP(ip)
+	if a == nil || (protocol == "TCP4") != (a.To4() != nil) {
+		return nil, ErrInvalidHeader
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 0 || p > 65535 {
+		return nil, ErrInvalidHeader
+	}
+
+	return &net.TCPAddr{IP: a, Port: p}, nil
+}
+
+func readV2(r *bufio.Reader) (*Header, error) {
+	var fixed [v2HeaderLength]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, err
+	}
+
+	if fixed[12]>>4 != 2 {
+		return nil, ErrInvalidHeader
+	}
+
+	command := fixed[12] & 0xf
+	family, transport := fixed[13]>>4, fixed[13]&0xf
+	payload := make([]byte, binary.BigEndian.Uint16(fixed[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	h := &Header{Version: 2}
+	switch command {
+	case v2CommandLocal:
+		h.Local = true
+		return h, nil
+	case v2CommandProxy:
+	default:
+		return nil, ErrInvalidHeader
+	}
+
+	var addrLength int
+	switch family {
+	case v2FamilyUnspec:
+		h.Local = true
+	case v2FamilyInet:
+		addrLength = 12
+	case v2FamilyInet6:
+		addrLength = 36
+	case v2FamilyUnix:
+		addrLength = 216
+	default:
+		return nil, ErrInvalidHeader
+	}
+
+	if len(payload) < addrLength {
+		return nil, ErrInvalidHeader
+	}
+
+	addr := payload[:addrLength]
+	switch family {
+	case v2FamilyInet, v2FamilyInet6:
+		ipLength := (addrLength - 4) / 2
+		srcIP, dstIP := net.IP(addr[:ipLength]), net.IP(addr[ipLength:2*ipLength])
+		srcPort := int(binary.BigEndian.Uint16(addr[2*ipLength:]))
+		dstPort := int(binary.BigEndian.Uint16(addr[2*ipLength+2:]))
+		switch transport {
+		case v2TransportStream:
+			h.Source = &net.TCPAddr{IP: srcIP, Port: srcPort}
+			h.Destination = &net.TCPAddr{IP: dstIP, Port: dstPort}
+		case v2TransportDgram:
+			h.Source = &net.UDPAddr{IP: srcIP, Port: srcPort}
+			h.Destination = &net.UDPAddr{IP: dstIP, Port: dstPort}
+		default:
+			return nil, ErrInvalidHeader
+		}
+	case v2FamilyUnix:
+		network := "unix"
+		if transport == v2TransportDgram {
+			network = "unixgram"
+		}
+
+		h.Source = &net.UnixAddr{Net: network, Name: string(bytes.TrimRight(addr[:108], "\x00"))}
+		h.Destination = &net.UnixAddr{Net: network, Name: string(bytes.TrimRight(addr[108:], "\x00"))}
+	}
+
+	tlvs, err := parseTLVs(payload[addrLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	h.TLVs = tlvs
+	return h, nil
+}
+
+func (h *Header) String() string {
+	if h.Local {
+		return fmt.Sprintf("PROXY v%d LOCAL", h.Version)
+	}
+
+	return fmt.Sprintf("PROXY v%d %v -> %v", h.Version, h.Source, h.Destination)
+}
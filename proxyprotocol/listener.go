@@ -0,0 +1,201 @@
+/*
+Package proxyprotocol implements a listener, that accepts connections
+starting with a PROXY protocol v1 or v2 header, as sent by TCP load
+balancers, e.g. HAProxy or AWS NLB.
+
+See: https://www.haproxy.org/download/2.4/doc/proxy-protocol.txt
+
+The connections of the listener return the original client address
+from RemoteAddr(), and therefore it reaches http.Request.RemoteAddr.
+The header, including the TLV fields of the v2 headers, e.g. the TLS
+information, can be accessed from the request context, when the
+ConnContext function is set in the http.Server:
+
+	srv := &http.Server{ConnContext: proxyprotocol.ConnContext}
+
+	// in a filter:
+	if h, ok := proxyprotocol.HeaderFromContext(ctx.Request().Context()); ok {
+		if ssl, ok := h.SSL(); ok {
+			log.Println(ssl.Version())
+		}
+	}
+
+The header is read, when the connection is used the first time, and not
+in Accept(), so that slow clients don't block accepting connections.
+*/
+package proxyprotocol
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	snet "github.com/zalando/skipper/net"
+)
+
+const defaultHeaderTimeout = 10 * time.Second
+
+type contextKey struct{}
+
+// Options are used to initialize the PROXY protocol listener.
+type Options struct {
+
+	// TrustedCIDRs contains the networks of the load balancers, that
+	// are allowed to send PROXY protocol headers. The connections from
+	// other addresses are accepted without parsing a header. When
+	// empty, no address is trusted, and no header is parsed.
+	TrustedCIDRs snet.IPNets
+
+	// HeaderTimeout sets the maximum duration for receiving the
+	// header. Defaults to 10s.
+	HeaderTimeout time.Duration
+}
+
+type listener struct {
+	net.Listener
+	options Options
+}
+
+// Conn wraps the accepted connections, and reads the PROXY protocol
+// header before the first read.
+type Conn struct {
+	net.Conn
+	options Options
+	reader  *bufio.Reader
+	once    sync.Once
+	header  *Header
+	err     error
+}
+
+// NewListener wraps a listener, to accept connections with PROXY
+// protocol headers.
+func NewListener(l net.Listener, o Options) net.Listener {
+	if o.HeaderTimeout <= 0 {
+		o.HeaderTimeout = defaultHeaderTimeout
+	}
+
+	return &listener{Listener: l, options: o}
+}
+
+// Listen creates a TCP listener accepting connections with PROXY
+// protocol headers.
+func Listen(network, address string, o Options) (net.Listener, error) {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewListener(l, o), nil
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		Conn:    c,
+		options: l.options,
+		reader:  bufio.NewReader(c),
+	}, nil
+}
+
+func (c *Conn) trusted() bool {
+	addr, ok := c.Conn.RemoteAddr().(*net.TCPAddr)
+	return ok && c.options.TrustedCIDRs.Contain(addr.IP)
+}
+
+func (c *Conn) readHeader() {
+	c.once.Do(func() {
+		if !c.trusted() {
+			return
+		}
+
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.options.HeaderTimeout)); err != nil {
+			c.err = err
+			return
+		}
+
+		c.header, c.err = readHeader(c.reader)
+		if c.err != nil {
+			log.Errorf("Failed to read PROXY protocol header from %v: %v", c.Conn.RemoteAddr(), c.err)
+			return
+		}
+
+		// resetting the deadline, the server sets its own deadlines
+		// after the first read
+		c.err = c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+// Header returns the received PROXY protocol header. It returns nil, when
+// the connection didn't start with a header.
+func (c *Conn) Header() (*Header, error) {
+	c.readHeader()
+	return c.header, c.err
+}
+
+func (c *Conn) Read(p []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(p)
+}
+
+// RemoteAddr returns the address of the original client, when it was
+// received in the header, otherwise the address of the peer.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.header != nil && !c.header.Local && c.header.Source != nil {
+		return c.header.Source
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the original destination address, when it was
+// received in the header, otherwise the local address.
+func (c *Conn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.header != nil && !c.header.Local && c.header.Destination != nil {
+		return c.header.Destination
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+// ConnContext can be used as the ConnContext function of http.Server.
+// It stores the connection in the context, to make the PROXY protocol
+// header available for the requests. It doesn't read the header, because
+// it is called by the server in the accept loop.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+
+	pc, ok := c.(*Conn)
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, contextKey{}, pc)
+}
+
+// HeaderFromContext returns the PROXY protocol header of the connection
+// stored by ConnContext, when the connection received one.
+func HeaderFromContext(ctx context.Context) (*Header, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Conn)
+	if !ok {
+		return nil, false
+	}
+
+	h, err := c.Header()
+	return h, err == nil && h != nil
+}
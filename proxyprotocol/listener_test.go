@@ -0,0 +1,303 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	snet "github.com/zalando/skipper/net"
+)
+
+func v2Header(command, family byte, addr []byte, tlvs ...TLV) []byte {
+	var payload bytes.Buffer
+	payload.Write(addr)
+	for _, tlv := range tlvs {
+		payload.WriteByte(tlv.Type)
+		binary.Write(&payload, binary.BigEndian, uint16(len(tlv.Value)))
+		payload.Write(tlv.Value)
+	}
+
+	var b bytes.Buffer
+	b.Write(v2Signature)
+	b.WriteByte(0x20 | command)
+	b.WriteByte(family)
+	binary.Write(&b, binary.BigEndian, uint16(payload.Len()))
+	b.Write(payload.Bytes())
+	return b.Bytes()
+}
+
+func inet4Addr(src, dst string, srcPort, dstPort uint16) []byte {
+	var b bytes.Buffer
+	b.Write(net.ParseIP(src).To4())
+	b.Write(net.ParseIP(dst).To4())
+	binary.Write(&b, binary.BigEndian, srcPort)
+	binary.Write(&b, binary.BigEndian, dstPort)
+	return b.Bytes()
+}
+
+func sslTLV(client byte, verify uint32, tlvs ...TLV) TLV {
+	var b bytes.Buffer
+	b.WriteByte(client)
+	binary.Write(&b, binary.BigEndian, verify)
+	for _, tlv := range tlvs {
+		b.WriteByte(tlv.Type)
+		binary.Write(&b, binary.BigEndian, uint16(len(tlv.Value)))
+		b.Write(tlv.Value)
+	}
+
+	return TLV{Type: TypeSSL, Value: b.Bytes()}
+}
+
+func TestReadHeader(t *testing.T) {
+	for _, test := range []struct {
+		title       string
+		input       []byte
+		expected    string
+		noHeader    bool
+		fail        bool
+		remaining   string
+		checkHeader func(*testing.T, *Header)
+	}{{
+		title:     "no header",
+		input:     []byte("GET / HTTP/1.1\r\n\r\n"),
+		noHeader:  true,
+		remaining: "GET / HTTP/1.1\r\n\r\n",
+	}, {
+		title:    "empty",
+		noHeader: true,
+	}, {
+		title:     "v1 TCP4",
+		input:     []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nGET /"),
+		expected:  "PROXY v1 192.0.2.1:56324 -> 198.51.100.1:443",
+		remaining: "GET /",
+	}, {
+		title:     "v1 TCP6",
+		input:     []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"),
+		expected:  "PROXY v1 [2001:db8::1]:56324 -> [2001:db8::2]:443",
+		remaining: "",
+	}, {
+		title:    "v1 unknown",
+		input:    []byte("PROXY UNKNOWN\r\n"),
+		expected: "PROXY v1 LOCAL",
+	}, {
+		title: "v1 address family mismatch",
+		input: []byte("PROXY TCP4 2001:db8::1 2001:db8::2 56324 443\r\n"),
+		fail:  true,
+	}, {
+		title: "v1 invalid port",
+		input: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 563240 443\r\n"),
+		fail:  true,
+	}, {
+		title: "v1 too long",
+		input: []byte("PROXY TCP4 " + strings.Repeat("1", 120) + "\r\n"),
+		fail:  true,
+	}, {
+		title: "v1 missing CR",
+		input: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\n"),
+		fail:  true,
+	}, {
+		title:     "v2 local",
+		input:     append(v2Header(v2CommandLocal, 0, nil), "GET /"...),
+		expected:  "PROXY v2 LOCAL",
+		remaining: "GET /",
+	}, {
+		title: "v2 TCP4 with TLVs",
+		input: append(v2Header(
+			v2CommandProxy,
+			v2FamilyInet<<4|v2TransportStream,
+			inet4Addr("192.0.2.1", "198.51.100.1", 56324, 443),
+			TLV{Type: TypeALPN, Value: []byte("h2")},
+			TLV{Type: TypeAuthority, Value: []byte("www.example.org")},
+			sslTLV(
+				SSLClientSSL|SSLClientCertConn,
+				0,
+				TLV{Type: TypeSSLVersion, Value: []byte("TLSv1.3")},
+				TLV{Type: TypeSSLCN, Value: []byte("client.example.org")},
+			),
+		), "GET /"...),
+		expected:  "PROXY v2 192.0.2.1:56324 -> 198.51.100.1:443",
+		remaining: "GET /",
+		checkHeader: func(t *testing.T, h *Header) {
+			if h.ALPN() != "h2" || h.Authority() != "www.example.org" {
+				t.Errorf("invalid TLVs: %s, %s", h.ALPN(), h.Authority())
+			}
+
+			ssl, ok := h.SSL()
+			if !ok {
+				t.Fatal("failed to get SSL TLV")
+			}
+
+			if ssl.Version() != "TLSv1.3" || ssl.CommonName() != "client.example.org" || !ssl.ClientCertVerified() {
+				t.Errorf("invalid SSL TLV: %v", ssl)
+			}
+		},
+	}, {
+		title: "v2 invalid version",
+		input: func() []byte {
+			b := v2Header(v2CommandLocal, 0, nil)
+			b[12] = 0x10
+			return b
+		}(),
+		fail: true,
+	}, {
+		title: "v2 address too short",
+		input: v2Header(v2CommandProxy, v2FamilyInet6<<4|v2TransportStream, inet4Addr("192.0.2.1", "198.51.100.1", 1, 2)),
+		fail:  true,
+	}, {
+		title: "v2 invalid TLV",
+		input: append(
+			v2Header(v2CommandProxy, v2FamilyInet<<4|v2TransportStream, inet4Addr("192.0.2.1", "198.51.100.1", 1, 2)),
+			0,
+		),
+		fail: true,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			input := test.input
+			if test.title == "v2 invalid TLV" {
+				// the length of the payload needs to include the truncated TLV
+				input[15] += 1
+			}
+
+			r := bufio.NewReader(bytes.NewReader(input))
+			h, err := readHeader(r)
+			if test.fail {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.noHeader {
+				if h != nil {
+					t.Fatalf("unexpected header: %v", h)
+				}
+			} else if h.String() != test.expected {
+				t.Fatalf("invalid header, expected: %s, got: %s", test.expected, h.String())
+			}
+
+			if test.checkHeader != nil {
+				test.checkHeader(t, h)
+			}
+
+			remaining, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(remaining) != test.remaining {
+				t.Errorf("invalid remaining input, expected: %q, got: %q", test.remaining, string(remaining))
+			}
+		})
+	}
+}
+
+func TestListener(t *testing.T) {
+	for _, test := range []struct {
+		title              string
+		trusted            []string
+		header             string
+		expectedStatus     int
+		expectedRemoteAddr string
+		expectedHeader     bool
+	}{{
+		title:              "trusted, with header",
+		trusted:            []string{"127.0.0.1/32"},
+		header:             "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		expectedStatus:     http.StatusOK,
+		expectedRemoteAddr: "192.0.2.1:56324",
+		expectedHeader:     true,
+	}, {
+		title:              "trusted CIDR, with header",
+		trusted:            []string{"127.0.0.0/8"},
+		header:             "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		expectedStatus:     http.StatusOK,
+		expectedRemoteAddr: "192.0.2.1:56324",
+		expectedHeader:     true,
+	}, {
+		title:              "trusted, without header",
+		trusted:            []string{"127.0.0.1/32"},
+		expectedStatus:     http.StatusOK,
+		expectedRemoteAddr: "127.0.0.1",
+	}, {
+		title:          "untrusted, with header",
+		trusted:        []string{"192.0.2.0/24"},
+		header:         "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		title:          "no trusted CIDRs, with header",
+		header:         "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		title:              "untrusted, without header",
+		trusted:            []string{"192.0.2.0/24"},
+		expectedStatus:     http.StatusOK,
+		expectedRemoteAddr: "127.0.0.1",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			cidrs, err := snet.ParseCIDRs(test.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			l, err := Listen("tcp", "127.0.0.1:0", Options{TrustedCIDRs: cidrs, HeaderTimeout: time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			remoteAddr := make(chan string, 1)
+			hasHeader := make(chan bool, 1)
+			srv := &http.Server{
+				ConnContext: ConnContext,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					remoteAddr <- r.RemoteAddr
+					_, ok := HeaderFromContext(r.Context())
+					hasHeader <- ok
+				}),
+			}
+
+			go srv.Serve(l)
+			defer srv.Close()
+
+			conn, err := net.Dial("tcp", l.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer conn.Close()
+			fmt.Fprintf(conn, "%sGET / HTTP/1.1\r\nHost: www.example.org\r\nConnection: close\r\n\r\n", test.header)
+			rsp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp.Body.Close()
+			if rsp.StatusCode != test.expectedStatus {
+				t.Fatalf("invalid status, expected: %d, got: %d", test.expectedStatus, rsp.StatusCode)
+			}
+
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if ra := <-remoteAddr; !strings.HasPrefix(ra, test.expectedRemoteAddr) {
+				t.Errorf("invalid remote address, expected: %s, got: %s", test.expectedRemoteAddr, ra)
+			}
+
+			if h := <-hasHeader; h != test.expectedHeader {
+				t.Errorf("invalid header in the context, expected: %t, got: %t", test.expectedHeader, h)
+			}
+		})
+	}
+}
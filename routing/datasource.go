@@ -35,6 +35,25 @@ func (it incomingType) String() string {
 
 type routeDefs map[string]*eskip.Route
 
+// routeDefError is returned when a route definition cannot be
+// processed, identifying the failing filter or predicate, if any.
+type routeDefError struct {
+	filter    string
+	predicate string
+	err       error
+}
+
+func (e *routeDefError) Error() string { return e.err.Error() }
+func (e *routeDefError) Unwrap() error { return e.err }
+
+func filterError(name string, err error) error {
+	return &routeDefError{filter: name, err: err}
+}
+
+func predicateError(name string, err error) error {
+	return &routeDefError{predicate: name, err: err}
+}
+
 type incomingData struct {
 	typ            incomingType
 	client         DataClient
@@ -134,12 +153,21 @@ func applyIncoming(defs routeDefs, d *incomingData) routeDefs {
 	return defs
 }
 
+// merged route definitions from all the data clients, and the data
+// client of each route by route id
+type mergedDefs struct {
+	routes  []*eskip.Route
+	clients map[string]DataClient
+}
+
 // merges the route definitions from multiple data clients by route id
-func mergeDefs(defsByClient map[DataClient]routeDefs) []*eskip.Route {
+func mergeDefs(defsByClient map[DataClient]routeDefs) *mergedDefs {
 	mergeByID := make(routeDefs)
-	for _, defs := range defsByClient {
+	clients := make(map[string]DataClient)
+	for c, defs := range defsByClient {
 		for id, def := range defs {
 			mergeByID[id] = def
+			clients[id] = c
 		}
 	}
 
@@ -148,7 +176,11 @@ func mergeDefs(defsByClient map[DataClient]routeDefs) []*eskip.Route {
 		all = append(all, def)
 	}
 
-	return all
+	// keeping the order stable between the updates allows reusing the
+	// unchanged parts of the matcher
+	sort.Slice(all, func(i, j int) bool { return all[i].Id < all[j].Id })
+
+	return &mergedDefs{routes: all, clients: clients}
 }
 
 // receives the initial set of the route definitiosn and their
@@ -157,9 +189,9 @@ func mergeDefs(defsByClient map[DataClient]routeDefs) []*eskip.Route {
 //
 // The active set of routes from last successful update are used until the
 // next successful update.
-func receiveRouteDefs(o Options, quit <-chan struct{}) <-chan []*eskip.Route {
+func receiveRouteDefs(o Options, quit <-chan struct{}) <-chan *mergedDefs {
 	in := make(chan *incomingData)
-	out := make(chan []*eskip.Route)
+	out := make(chan *mergedDefs)
 	defsByClient := make(map[DataClient]routeDefs)
 
 	for _, c := range o.DataClients {
@@ -232,7 +264,7 @@ func createFilters(fr filters.Registry, defs []*eskip.Filter, cpm map[string]Pre
 	for i, def := range defs {
 		f, err := createFilter(fr, def, cpm)
 		if err != nil {
-			return nil, err
+			return nil, filterError(def.Name, err)
 		}
 		fs = append(fs, &RouteFilter{f, def.Name, i})
 	}
@@ -254,19 +286,19 @@ func isTreePredicate(name string) bool {
 
 func getFreeStringArgs(count int, p *eskip.Predicate) ([]string, error) {
 	if len(p.Args) != count {
-		return nil, fmt.Errorf(
+		return nil, predicateError(p.Name, fmt.Errorf(
 			"invalid length of predicate args in %s, %d instead of %d",
 			p.Name,
 			len(p.Args),
 			count,
-		)
+		))
 	}
 
 	var a []string
 	for i := range p.Args {
 		s, ok := p.Args[i].(string)
 		if !ok {
-			return nil, fmt.Errorf("expected argument of type string, %s", p.Name)
+			return nil, predicateError(p.Name, fmt.Errorf("expected argument of type string, %s", p.Name))
 		}
 
 		a = append(a, s)
@@ -362,7 +394,7 @@ func processPredicates(cpm map[string]PredicateSpec, defs []*eskip.Predicate) ([
 		if def.Name == "Weight" {
 			var err error
 			if weight, err = parseWeightPredicateArgs(def.Args); err != nil {
-				return nil, 0, err
+				return nil, 0, predicateError(def.Name, err)
 			}
 
 			continue
@@ -374,12 +406,12 @@ func processPredicates(cpm map[string]PredicateSpec, defs []*eskip.Predicate) ([
 
 		spec, ok := cpm[def.Name]
 		if !ok {
-			return nil, 0, fmt.Errorf("predicate not found: '%s'", def.Name)
+			return nil, 0, predicateError(def.Name, fmt.Errorf("predicate not found: '%s'", def.Name))
 		}
 
 		cp, err := spec.Create(def.Args)
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, predicateError(def.Name, err)
 		}
 
 		cps = append(cps, cp)
@@ -431,13 +463,13 @@ func processTreePredicates(r *Route, predicateList []*eskip.Predicate) error {
 		case predicates.PathName:
 			path, err := processPathOrSubTree(p)
 			if err != nil {
-				return err
+				return predicateError(p.Name, err)
 			}
 			r.path = path
 		case predicates.PathSubtreeName:
 			pst, err := processPathOrSubTree(p)
 			if err != nil {
-				return err
+				return predicateError(p.Name, err)
 			}
 			r.pathSubtree = pst
 		}
@@ -486,18 +518,42 @@ func mapPredicates(cps []PredicateSpec) map[string]PredicateSpec {
 	return cpm
 }
 
-// processes a set of route definitions for the routing table
-func processRouteDefs(o Options, fr filters.Registry, defs []*eskip.Route) (routes []*Route, invalidDefs []*eskip.Route) {
+// processes a set of route definitions for the routing table, and
+// returns the errors of the invalid routes by route id
+func processRouteDefs(o Options, fr filters.Registry, defs []*eskip.Route) (routes []*Route, invalidDefs []*eskip.Route, errs map[string]error) {
+	routes, invalidDefs, errs, _ = processChangedRouteDefs(o, fr, defs, nil)
+	return
+}
+
+// processes a set of route definitions for the routing table, reusing
+// the routes of the cache whose definitions didn't change, and returns
+// the cache for the next update
+func processChangedRouteDefs(o Options, fr filters.Registry, defs []*eskip.Route, cache *routeCache) (
+	routes []*Route,
+	invalidDefs []*eskip.Route,
+	errs map[string]error,
+	next *routeCache,
+) {
 	cpm := mapPredicates(o.Predicates)
+	errs = make(map[string]error)
+	next = newRouteCache(len(defs))
 	for _, def := range defs {
-		route, err := processRouteDef(cpm, fr, def)
-		if err == nil {
-			routes = append(routes, route)
-		} else {
-			invalidDefs = append(invalidDefs, def)
-			o.Log.Errorf("failed to process route (%v): %v", def.Id, err)
+		route, ok := cache.get(def)
+		if !ok {
+			var err error
+			route, err = processRouteDef(cpm, fr, def)
+			if err != nil {
+				invalidDefs = append(invalidDefs, def)
+				errs[def.Id] = err
+				o.Log.Errorf("failed to process route (%v): %v", def.Id, err)
+				continue
+			}
 		}
+
+		routes = append(routes, route)
+		next.set(def, route)
 	}
+
 	return
 }
 
@@ -506,6 +562,7 @@ type routeTable struct {
 	validRoutes   []*eskip.Route
 	invalidRoutes []*eskip.Route
 	created       time.Time
+	generation    *Generation
 }
 
 // receives the next version of the routing table on the output channel,
@@ -513,27 +570,52 @@ type routeTable struct {
 func receiveRouteMatcher(o Options, out chan<- *routeTable, quit <-chan struct{}) {
 	updates := receiveRouteDefs(o, quit)
 	var (
-		rt           *routeTable
-		outRelay     chan<- *routeTable
-		updatesRelay <-chan []*eskip.Route
+		rt             *routeTable
+		outRelay       chan<- *routeTable
+		updatesRelay   <-chan *mergedDefs
+		gs             generationState
+		cache          *routeCache
+		prev           *matcher
+		loggedWarnings map[string]bool
 	)
 	updatesRelay = updates
 	for {
 		select {
-		case defs := <-updatesRelay:
+		case merged := <-updatesRelay:
 			o.Log.Info("route settings received")
 
+			defs := merged.routes
+
 			for i := range o.PreProcessors {
 				defs = o.PreProcessors[i].Do(defs)
 			}
 
-			routes, invalidRoutes := processRouteDefs(o, o.FilterRegistry, defs)
+			var (
+				routes        []*Route
+				invalidRoutes []*eskip.Route
+				invalidErrs   map[string]error
+			)
+
+			routes, invalidRoutes, invalidErrs, cache = processChangedRouteDefs(o, o.FilterRegistry, defs, cache)
+
+			routes = postProcess(o.PostProcessors, routes)
+			m, errs := updateMatcher(prev, routes, o.MatchingOptions)
+			prev = m
 
-			for i := range o.PostProcessors {
-				routes = o.PostProcessors[i].Do(routes)
+			// the warnings are logged only once, and not repeated on
+			// every update
+			var warnings []string
+			for _, c := range m.priorityConflicts {
+				warnings = append(warnings, c.String())
+			}
+
+			if o.LintRoutes {
+				for _, issue := range lintMatcher(m, routes) {
+					warnings = append(warnings, fmt.Sprintf("route lint: %v", issue))
+				}
 			}
 
-			m, errs := newMatcher(routes, o.MatchingOptions)
+			loggedWarnings = logNewWarnings(o.Log, loggedWarnings, warnings)
 
 			invalidRouteIds := make(map[string]struct{})
 			validRoutes := []*eskip.Route{}
@@ -541,6 +623,7 @@ func receiveRouteMatcher(o Options, out chan<- *routeTable, quit <-chan struct{}
 			for _, err := range errs {
 				o.Log.Error(err)
 				invalidRouteIds[err.ID] = struct{}{}
+				invalidErrs[err.ID] = err
 			}
 
 			for _, r := range routes {
@@ -555,11 +638,13 @@ func receiveRouteMatcher(o Options, out chan<- *routeTable, quit <-chan struct{}
 				return validRoutes[i].Id < validRoutes[j].Id
 			})
 
+			created := time.Now().UTC()
 			rt = &routeTable{
 				m:             m,
 				validRoutes:   validRoutes,
 				invalidRoutes: invalidRoutes,
-				created:       time.Now().UTC(),
+				created:       created,
+				generation:    gs.next(created, validRoutes, invalidRoutes, merged.clients, invalidErrs),
 			}
 			updatesRelay = nil
 			outRelay = out
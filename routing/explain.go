@@ -0,0 +1,249 @@
+package routing
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dimfeld/httppath"
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/predicates"
+)
+
+// Candidate is a route that was tried while explaining the matching of
+// a request.
+type Candidate struct {
+
+	// Id of the tried route.
+	Id string `json:"id"`
+
+	// RejectedBy is the predicate that rejected the request, with the
+	// arguments of the route definition. It is nil for the matching
+	// route.
+	RejectedBy *eskip.Predicate `json:"rejectedBy,omitempty"`
+}
+
+// Explanation describes how a request was matched against the current
+// routing table.
+type Explanation struct {
+
+	// Route is the matching route or nil if no route matched.
+	Route *Route `json:"-"`
+
+	// RouteId is the id of the matching route or empty if no route
+	// matched.
+	RouteId string `json:"route,omitempty"`
+
+	// Params contains the wildcard parameters of the matching route.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Candidates contains the routes in the order they were tried,
+	// including the matching route as the last one.
+	Candidates []Candidate `json:"candidates"`
+}
+
+// ExplainRequest describes a synthetic request accepted by the explain
+// endpoint.
+type ExplainRequest struct {
+	Method     string              `json:"method"`
+	Host       string              `json:"host"`
+	Path       string              `json:"path"`
+	Query      map[string][]string `json:"query"`
+	Headers    map[string][]string `json:"headers"`
+	Cookies    map[string]string   `json:"cookies"`
+	RemoteAddr string              `json:"remoteAddr"`
+}
+
+// explainRequestMatcher is used instead of the leafRequestMatcher when
+// explaining a request. It records every tried leaf, so that the
+// regular lookup doesn't need to pay for it.
+type explainRequestMatcher struct {
+	r          *http.Request
+	path       string
+	exactPath  string
+	candidates []Candidate
+}
+
+func (m *explainRequestMatcher) Match(value interface{}) (bool, interface{}) {
+	v, ok := value.(*pathMatcher)
+	if !ok {
+		return false, nil
+	}
+
+	l := m.matchLeaves(v.leaves)
+	return l != nil, l
+}
+
+func (m *explainRequestMatcher) matchLeaves(leaves leafMatchers) *leafMatcher {
+	for _, l := range leaves {
+		p := explainLeaf(l, m.r, m.path, m.exactPath)
+		m.candidates = append(m.candidates, Candidate{Id: l.route.Id, RejectedBy: p})
+		if p == nil {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func newPredicate(name string, args ...interface{}) *eskip.Predicate {
+	return &eskip.Predicate{Name: name, Args: args}
+}
+
+// custom predicate definitions of a route, in the same order as the
+// predicate instances created by processPredicates
+func customPredicateDefs(r *Route) []*eskip.Predicate {
+	var defs []*eskip.Predicate
+	for _, p := range r.Route.Predicates {
+		if p.Name == "Weight" || isTreePredicate(p.Name) {
+			continue
+		}
+
+		defs = append(defs, p)
+	}
+
+	return defs
+}
+
+// explainLeaf checks the conditions of a leaf with checkLeaf, and
+// returns the predicate of the first one rejecting the request, or nil
+// when the request matches.
+func explainLeaf(l *leafMatcher, req *http.Request, path, exactPath string) *eskip.Predicate {
+	r := checkLeaf(l, req, path, exactPath)
+	switch r.condition {
+	case noCondition:
+		return nil
+	case pathCondition:
+		return newPredicate(predicates.PathName, l.exactPath)
+	case methodCondition:
+		return newPredicate(predicates.MethodName, l.method)
+	case hostCondition:
+		return newPredicate(predicates.HostName, l.hostRxs[r.index].String())
+	case pathRegexpCondition:
+		return newPredicate(predicates.PathRegexpName, l.pathRxs[r.index].String())
+	case headerCondition:
+		return newPredicate(predicates.HeaderName, r.header, l.headersExact[r.header])
+	case headerRegexpCondition:
+		return newPredicate(predicates.HeaderRegexpName, r.header, l.headersRegexp[r.header][r.index].String())
+	}
+
+	if defs := customPredicateDefs(l.route); len(defs) == len(l.predicates) {
+		return defs[r.index]
+	}
+
+	// the predicates were not created from the route definition, e.g. by
+	// a route post-processor
+	return newPredicate("<unknown>")
+}
+
+// explain matches a request the same way as match, but it records all
+// the candidate routes that were tried.
+func (m *matcher) explain(r *http.Request) *Explanation {
+	path := httppath.Clean(r.URL.Path)
+	exact := path
+	if m.matchingOptions.ignoreTrailingSlash() {
+		path = trimTrailingSlash(path)
+	}
+	erm := &explainRequestMatcher{r: r, path: path, exactPath: exact}
+
+	e := &Explanation{}
+	paths, rootLeaves := m.lookupTrees(r.Host)
+	params, l := matchPathTree(paths, path, erm)
+	if l == nil {
+		l = erm.matchLeaves(rootLeaves)
+		params = nil
+	}
+
+	e.Candidates = erm.candidates
+	if e.Candidates == nil {
+		e.Candidates = []Candidate{}
+	}
+
+	if l != nil {
+		e.Route = l.route
+		e.RouteId = l.route.Id
+		e.Params = params
+	}
+
+	return e
+}
+
+// Explain matches a request in the current routing tree, and returns
+// the matching route together with all the routes that were tried,
+// and the predicates that rejected them. It is meant for debugging,
+// and it is slower than Route.
+func (r *Routing) Explain(req *http.Request) *Explanation {
+	rt := r.routeTable.Load().(*routeTable)
+	return rt.m.explain(req)
+}
+
+// NewRequest creates the synthetic request to be explained.
+func (er *ExplainRequest) NewRequest() (*http.Request, error) {
+	method := er.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := er.Path
+	if path == "" {
+		path = "/"
+	}
+
+	u := &url.URL{Path: path, RawQuery: url.Values(er.Query).Encode()}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Host = er.Host
+	for k, vs := range er.Headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	for name, value := range er.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+
+	req.RemoteAddr = er.RemoteAddr
+	if req.RemoteAddr != "" {
+		if _, _, err := net.SplitHostPort(req.RemoteAddr); err != nil {
+			req.RemoteAddr = net.JoinHostPort(strings.Trim(req.RemoteAddr, "[]"), "0")
+		}
+	}
+
+	return req, nil
+}
+
+// ExplainHandler returns the handler of the explain endpoint. It
+// accepts POST requests with a JSON encoded ExplainRequest in the
+// body, and responds with the JSON encoded Explanation.
+func (r *Routing) ExplainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var er ExplainRequest
+		if err := json.NewDecoder(req.Body).Decode(&er); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sreq, err := er.NewRequest()
+		if err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.Explain(sreq)); err != nil {
+			r.log.Errorf("Failed to encode route explanation: %v", err)
+		}
+	})
+}
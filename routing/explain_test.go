@@ -0,0 +1,222 @@
+package routing_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/predicates/cookie"
+	"github.com/zalando/skipper/predicates/source"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
+)
+
+const explainRoutes = `
+	method: Path("/foo") && Method("POST") -> <shunt>;
+	header: Path("/foo") && Header("X-Foo", "bar") -> <shunt>;
+	cookie: Path("/foo") && Cookie("c", "v") -> <shunt>;
+	foo: Path("/foo") -> <shunt>;
+	param: Path("/bar/:id") && Source("10.0.0.0/8") -> <shunt>;
+	host: Host("^example.org$") -> <shunt>;
+	headers: Path("/headers") && Header("X-C", "c") && Header("X-A", "a") && Header("X-B", "b")
+		&& HeaderRegexp("X-E", /e/) && HeaderRegexp("X-D", /d/) -> <shunt>;
+`
+
+func newExplainRouting(t *testing.T) *testRouting {
+	dc, err := testdataclient.NewDoc(explainRoutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := newTestRoutingWithPredicates([]routing.PredicateSpec{cookie.New(), source.New()}, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tr
+}
+
+func rejections(e *routing.Explanation) map[string]string {
+	m := make(map[string]string)
+	for _, c := range e.Candidates {
+		if c.RejectedBy == nil {
+			m[c.Id] = ""
+			continue
+		}
+
+		m[c.Id] = c.RejectedBy.String()
+	}
+
+	return m
+}
+
+func TestExplain(t *testing.T) {
+	tr := newExplainRouting(t)
+	defer tr.close()
+
+	for _, test := range []struct {
+		title      string
+		request    routing.ExplainRequest
+		route      string
+		params     map[string]string
+		rejections map[string]string
+	}{{
+		title:   "path tree, candidates rejected by different predicates",
+		request: routing.ExplainRequest{Path: "/foo", Host: "www.example.org"},
+		route:   "foo",
+		rejections: map[string]string{
+			"method": `Method("POST")`,
+			"header": `Header("X-Foo", "bar")`,
+			"cookie": `Cookie("c", "v")`,
+			"foo":    "",
+		},
+	}, {
+		title: "path tree, cookie",
+		request: routing.ExplainRequest{
+			Path:    "/foo",
+			Cookies: map[string]string{"c": "v"},
+			Headers: map[string][]string{"X-Foo": {"baz"}},
+		},
+		route: "cookie",
+	}, {
+		title:      "path tree, wildcard and source",
+		request:    routing.ExplainRequest{Path: "/bar/42", RemoteAddr: "10.0.0.1"},
+		route:      "param",
+		params:     map[string]string{"id": "42"},
+		rejections: map[string]string{"param": ""},
+	}, {
+		title:   "falls back to the root leaves",
+		request: routing.ExplainRequest{Path: "/bar/42", Host: "example.org", RemoteAddr: "192.168.0.1:1234"},
+		route:   "host",
+		rejections: map[string]string{
+			"param": `Source("10.0.0.0/8")`,
+			"host":  "",
+		},
+	}, {
+		title:   "header conditions in the order of the names",
+		request: routing.ExplainRequest{Path: "/headers", Headers: map[string][]string{"X-B": {"b"}, "X-C": {"c"}}},
+		rejections: map[string]string{
+			"headers": `Header("X-A", "a")`,
+			"host":    `Host("^example.org$")`,
+		},
+	}, {
+		title: "header regexp conditions in the order of the names",
+		request: routing.ExplainRequest{
+			Path:    "/headers",
+			Headers: map[string][]string{"X-A": {"a"}, "X-B": {"b"}, "X-C": {"c"}},
+		},
+		rejections: map[string]string{
+			"headers": `HeaderRegexp("X-D", "d")`,
+			"host":    `Host("^example.org$")`,
+		},
+	}, {
+		title:      "no match",
+		request:    routing.ExplainRequest{Path: "/baz", Host: "www.example.org"},
+		rejections: map[string]string{"host": `Host("^example.org$")`},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			req, err := test.request.NewRequest()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := tr.routing.Explain(req)
+			if e.RouteId != test.route {
+				t.Fatalf("invalid route, expected: %q, got: %q", test.route, e.RouteId)
+			}
+
+			// the explanation must not differ from the regular lookup
+			if r, _ := tr.routing.Route(req); r != e.Route {
+				t.Fatalf("explanation differs from the lookup: %v", r)
+			}
+
+			for k, v := range test.params {
+				if e.Params[k] != v {
+					t.Errorf("invalid param %s, expected: %s, got: %s", k, v, e.Params[k])
+				}
+			}
+
+			if test.route != "" {
+				last := e.Candidates[len(e.Candidates)-1]
+				if last.Id != test.route || last.RejectedBy != nil {
+					t.Errorf("the matching route is expected as the last candidate, got: %v", last)
+				}
+			}
+
+			if test.rejections == nil {
+				return
+			}
+
+			// the header conditions are checked in a fixed order
+			for i := 0; i < 32; i++ {
+				if again := rejections(tr.routing.Explain(req)); !reflect.DeepEqual(again, rejections(e)) {
+					t.Fatalf("explanation is not deterministic: %v, %v", rejections(e), again)
+				}
+			}
+
+			got := rejections(e)
+			if len(got) != len(test.rejections) || len(e.Candidates) != len(test.rejections) {
+				t.Fatalf("invalid candidates, expected: %v, got: %v", test.rejections, got)
+			}
+
+			for id, p := range test.rejections {
+				if got[id] != p {
+					t.Errorf("invalid rejection of %s, expected: %q, got: %q", id, p, got[id])
+				}
+			}
+		})
+	}
+}
+
+func TestExplainHandler(t *testing.T) {
+	tr := newExplainRouting(t)
+	defer tr.close()
+
+	h := tr.routing.ExplainHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/routes/explain", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("invalid status, expected: %d, got: %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/routes/explain", strings.NewReader("{")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid status, expected: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/routes/explain", strings.NewReader(
+		`{"method": "POST", "path": "/foo", "query": {"q": ["1"]}}`,
+	)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("invalid status, expected: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	var e struct {
+		Route      string `json:"route"`
+		Candidates []struct {
+			Id         string `json:"id"`
+			RejectedBy *struct {
+				Name string `json:"name"`
+			} `json:"rejectedBy"`
+		} `json:"candidates"`
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Route != "method" {
+		t.Errorf("invalid route, expected: method, got: %s", e.Route)
+	}
+
+	last := e.Candidates[len(e.Candidates)-1]
+	if last.Id != "method" || last.RejectedBy != nil {
+		t.Errorf("invalid last candidate: %v", last)
+	}
+}
@@ -0,0 +1,234 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+)
+
+// DefaultHistorySize is the number of the route table generations kept
+// by default.
+const DefaultHistorySize = 32
+
+// RouteChange identifies a route that was changed in a generation of
+// the route table, and the data client that it came from.
+type RouteChange struct {
+	Id         string `json:"id"`
+	DataClient string `json:"dataClient,omitempty"`
+}
+
+// InvalidRoute identifies a route that could not be processed, and the
+// reason.
+type InvalidRoute struct {
+	Id         string `json:"id"`
+	DataClient string `json:"dataClient,omitempty"`
+
+	// Filter or Predicate is the name of the filter or predicate that
+	// could not be created, if the error was caused by one.
+	Filter    string `json:"filter,omitempty"`
+	Predicate string `json:"predicate,omitempty"`
+
+	Error string `json:"error"`
+
+	client DataClient
+}
+
+// Generation records the changes of the route table, compared to the
+// previous generation.
+type Generation struct {
+
+	// Generation is the sequence number of the route table, starting
+	// from 1.
+	Generation int `json:"generation"`
+
+	// Created is the time when the route table was created. Its UNIX
+	// timestamp is the same as the X-Timestamp header of the /routes
+	// endpoint.
+	Created time.Time `json:"created"`
+
+	// Timestamp is the UNIX timestamp of Created.
+	Timestamp int64 `json:"timestamp"`
+
+	// Count is the number of the valid routes.
+	Count int `json:"count"`
+
+	Added   []RouteChange `json:"added,omitempty"`
+	Updated []RouteChange `json:"updated,omitempty"`
+	Deleted []RouteChange `json:"deleted,omitempty"`
+
+	// Invalid contains all the routes that could not be processed in
+	// this generation, not only the changed ones.
+	Invalid []InvalidRoute `json:"invalid,omitempty"`
+}
+
+type historyRoute struct {
+	def    *eskip.Route
+	client string
+}
+
+// generationState is the state of the last generation used to calculate
+// the changes of the next one.
+type generationState struct {
+	number int
+	routes map[string]historyRoute
+}
+
+type history struct {
+	mu          sync.Mutex
+	size        int
+	generations []*Generation
+}
+
+func dataClientName(c DataClient) string {
+	if c == nil {
+		return ""
+	}
+
+	if s, ok := c.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprintf("%T", c)
+}
+
+func sortChanges(c []RouteChange) {
+	sort.Slice(c, func(i, j int) bool { return c[i].Id < c[j].Id })
+}
+
+// next calculates the changes of the route table, and updates the state
+// to the current generation.
+func (s *generationState) next(
+	created time.Time,
+	valid, invalid []*eskip.Route,
+	clients map[string]DataClient,
+	errs map[string]error,
+) *Generation {
+	s.number++
+	g := &Generation{
+		Generation: s.number,
+		Created:    created,
+		Timestamp:  created.Unix(),
+		Count:      len(valid),
+	}
+
+	current := make(map[string]historyRoute, len(valid)+len(invalid))
+	for _, defs := range [][]*eskip.Route{valid, invalid} {
+		for _, def := range defs {
+			current[def.Id] = historyRoute{def: def, client: dataClientName(clients[def.Id])}
+		}
+	}
+
+	for id, r := range current {
+		c := RouteChange{Id: id, DataClient: r.client}
+		if p, ok := s.routes[id]; !ok {
+			g.Added = append(g.Added, c)
+		} else if !eskip.Eq(p.def, r.def) || !eqMetadata(p.def.Metadata, r.def.Metadata) {
+			g.Updated = append(g.Updated, c)
+		}
+	}
+
+	for id, r := range s.routes {
+		if _, ok := current[id]; !ok {
+			g.Deleted = append(g.Deleted, RouteChange{Id: id, DataClient: r.client})
+		}
+	}
+
+	for _, def := range invalid {
+		ir := InvalidRoute{Id: def.Id, DataClient: current[def.Id].client, client: clients[def.Id]}
+		if err, ok := errs[def.Id]; ok {
+			ir.Error = err.Error()
+			var rerr *routeDefError
+			if errors.As(err, &rerr) {
+				ir.Filter = rerr.filter
+				ir.Predicate = rerr.predicate
+			}
+		}
+
+		g.Invalid = append(g.Invalid, ir)
+	}
+
+	sortChanges(g.Added)
+	sortChanges(g.Updated)
+	sortChanges(g.Deleted)
+	sort.Slice(g.Invalid, func(i, j int) bool { return g.Invalid[i].Id < g.Invalid[j].Id })
+
+	s.routes = current
+	return g
+}
+
+func newHistory(size int) *history {
+	if size == 0 {
+		size = DefaultHistorySize
+	}
+
+	return &history{size: size}
+}
+
+func (h *history) add(g *Generation) {
+	if g == nil || h.size < 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.generations = append(h.generations, g)
+	if len(h.generations) > h.size {
+		h.generations = append(h.generations[:0], h.generations[len(h.generations)-h.size:]...)
+	}
+}
+
+// since returns the kept generations with a sequence number greater
+// than n, in ascending order.
+func (h *history) since(n int) []*Generation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	gs := []*Generation{}
+	for _, g := range h.generations {
+		if g.Generation > n {
+			gs = append(gs, g)
+		}
+	}
+
+	return gs
+}
+
+// History returns the kept generations of the route table, in the order
+// of their creation. The number of the kept generations is set by
+// Options.HistorySize.
+func (r *Routing) History() []*Generation {
+	return r.history.since(0)
+}
+
+// HistoryHandler returns the handler serving the history of the route
+// table as JSON. The optional since query parameter filters out the
+// generations up to and including the given sequence number.
+func (r *Routing) HistoryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var since int
+		if s := req.URL.Query().Get("since"); s != "" {
+			var err error
+			if since, err = strconv.Atoi(s); err != nil || since < 0 {
+				http.Error(w, "invalid since", http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.history.since(since)); err != nil {
+			r.log.Errorf("Failed to encode route history: %v", err)
+		}
+	})
+}
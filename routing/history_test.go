@@ -0,0 +1,240 @@
+package routing_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/logging/loggingtest"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
+)
+
+const testDataClientName = "*testdataclient.Client"
+
+func newHistoryRouting(t *testing.T, size int, doc string) (*testRouting, *testdataclient.Client) {
+	dc, err := testdataclient.NewDoc(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tl := loggingtest.New()
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		DataClients:    []routing.DataClient{dc},
+		PollTimeout:    pollTimeout,
+		HistorySize:    size,
+		Log:            tl,
+	})
+
+	tr := &testRouting{tl, rt}
+	if err := tr.waitForRouteSetting(); err != nil {
+		tr.close()
+		t.Fatal(err)
+	}
+
+	return tr, dc
+}
+
+func changes(ids ...string) []routing.RouteChange {
+	var c []routing.RouteChange
+	for _, id := range ids {
+		c = append(c, routing.RouteChange{Id: id, DataClient: testDataClientName})
+	}
+
+	return c
+}
+
+func TestHistory(t *testing.T) {
+	tr, dc := newHistoryRouting(t, 0, `
+		r1: Path("/r1") -> <shunt>;
+		r2: Path("/r2") -> <shunt>;
+		r3: Path("/r3") -> unknownFilter() -> <shunt>;
+	`)
+	defer tr.close()
+
+	h := tr.routing.History()
+	if len(h) != 1 {
+		t.Fatalf("invalid number of generations, expected: 1, got: %d", len(h))
+	}
+
+	g := h[0]
+	if g.Generation != 1 || g.Count != 2 || g.Timestamp != g.Created.Unix() {
+		t.Errorf("invalid generation: %+v", g)
+	}
+
+	if !reflect.DeepEqual(g.Added, changes("r1", "r2", "r3")) || g.Updated != nil || g.Deleted != nil {
+		t.Errorf("invalid changes: %+v", g)
+	}
+
+	if len(g.Invalid) != 1 ||
+		g.Invalid[0].Id != "r3" ||
+		g.Invalid[0].DataClient != testDataClientName ||
+		!strings.Contains(g.Invalid[0].Error, "unknownFilter") {
+		t.Errorf("invalid routes not reported: %+v", g.Invalid)
+	}
+
+	tr.log.Reset()
+	if err := dc.UpdateDoc(`
+		r1: Path("/r1") -> status(418) -> <shunt>;
+		r4: Path("/r4") -> <shunt>;
+	`, []string{"r2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.waitForRouteSetting(); err != nil {
+		t.Fatal(err)
+	}
+
+	h = tr.routing.History()
+	if len(h) != 2 {
+		t.Fatalf("invalid number of generations, expected: 2, got: %d", len(h))
+	}
+
+	g = h[1]
+	if g.Generation != 2 || g.Count != 2 {
+		t.Errorf("invalid generation: %+v", g)
+	}
+
+	if !reflect.DeepEqual(g.Added, changes("r4")) ||
+		!reflect.DeepEqual(g.Updated, changes("r1")) ||
+		!reflect.DeepEqual(g.Deleted, changes("r2")) {
+		t.Errorf("invalid changes: %+v", g)
+	}
+
+	if len(g.Invalid) != 1 || g.Invalid[0].Id != "r3" {
+		t.Errorf("invalid routes not reported: %+v", g.Invalid)
+	}
+
+	// eskip.Eq ignores the metadata
+	tr.log.Reset()
+	if err := dc.UpdateDoc(`r4 [owner="team-foo"]: Path("/r4") -> <shunt>;`, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.waitForRouteSetting(); err != nil {
+		t.Fatal(err)
+	}
+
+	h = tr.routing.History()
+	if len(h) != 3 {
+		t.Fatalf("invalid number of generations, expected: 3, got: %d", len(h))
+	}
+
+	if g = h[2]; g.Added != nil || !reflect.DeepEqual(g.Updated, changes("r4")) || g.Deleted != nil {
+		t.Errorf("metadata change not reported: %+v", g)
+	}
+}
+
+func TestHistorySize(t *testing.T) {
+	for _, test := range []struct {
+		size     int
+		expected []int
+	}{
+		{size: 0, expected: []int{1, 2, 3}},
+		{size: 2, expected: []int{2, 3}},
+		{size: -1, expected: nil},
+	} {
+		tr, dc := newHistoryRouting(t, test.size, `r: * -> <shunt>`)
+		for _, doc := range []string{`r1: * -> <shunt>`, `r2: * -> <shunt>`} {
+			tr.log.Reset()
+			if err := dc.UpdateDoc(doc, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tr.waitForRouteSetting(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var got []int
+		for _, g := range tr.routing.History() {
+			got = append(got, g.Generation)
+		}
+
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("invalid generations for size %d, expected: %v, got: %v", test.size, test.expected, got)
+		}
+
+		tr.close()
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	tr, dc := newHistoryRouting(t, 0, `r1: * -> <shunt>`)
+	defer tr.close()
+
+	tr.log.Reset()
+	if err := dc.UpdateDoc(`r2: Path("/r2") -> <shunt>`, []string{"r1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.waitForRouteSetting(); err != nil {
+		t.Fatal(err)
+	}
+
+	h := tr.routing.HistoryHandler()
+	for _, test := range []struct {
+		title    string
+		method   string
+		query    string
+		status   int
+		expected []int
+	}{{
+		title:    "all",
+		method:   "GET",
+		status:   http.StatusOK,
+		expected: []int{1, 2},
+	}, {
+		title:    "since",
+		method:   "GET",
+		query:    "?since=1",
+		status:   http.StatusOK,
+		expected: []int{2},
+	}, {
+		title:    "none since the last",
+		method:   "GET",
+		query:    "?since=2",
+		status:   http.StatusOK,
+		expected: []int{},
+	}, {
+		title:  "invalid since",
+		method: "GET",
+		query:  "?since=foo",
+		status: http.StatusBadRequest,
+	}, {
+		title:  "invalid method",
+		method: "POST",
+		status: http.StatusMethodNotAllowed,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(test.method, "/routes/history"+test.query, nil))
+			if w.Code != test.status {
+				t.Fatalf("invalid status, expected: %d, got: %d", test.status, w.Code)
+			}
+
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var gs []routing.Generation
+			if err := json.NewDecoder(w.Body).Decode(&gs); err != nil {
+				t.Fatal(err)
+			}
+
+			got := []int{}
+			for _, g := range gs {
+				got = append(got, g.Generation)
+			}
+
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("invalid generations, expected: %v, got: %v", test.expected, got)
+			}
+		})
+	}
+}
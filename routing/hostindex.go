@@ -0,0 +1,307 @@
+package routing
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zalando/skipper/pathmux"
+)
+
+// hostMatcher contains the path tree and the root leaves of the routes
+// of a single exact hostname, and the routes without an exact hostname.
+type hostMatcher struct {
+	paths      *pathmux.Tree
+	rootLeaves leafMatchers
+
+	// the routes of the host, used to detect the changes
+	entries []*leafEntry
+}
+
+// leafEntry stores where a leaf was added in the generic path tree, and
+// the position of its route in the definitions.
+type leafEntry struct {
+	leaf  *leafMatcher
+	paths []string
+	hosts []string
+	index int
+}
+
+const (
+	hostRxPortSuffix = "(:[0-9]+)?"
+	hostRxDotSuffix  = "[.]?"
+)
+
+// splits a regular expression at the top level alternations, returning
+// false if the parentheses are not balanced.
+func splitAlternatives(rx string) ([]string, bool) {
+	var (
+		alts  []string
+		depth int
+		start int
+	)
+
+	for i := 0; i < len(rx); i++ {
+		switch rx[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, false
+			}
+		case '|':
+			if depth == 0 {
+				alts = append(alts, rx[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, false
+	}
+
+	return append(alts, rx[start:]), true
+}
+
+// unescapes a hostname in a regular expression, where the dots are
+// escaped either as [.] or \., and returns false when the expression
+// contains anything else than a literal hostname.
+func literalHost(rx string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(rx); i++ {
+		c := rx[i]
+		switch {
+		case strings.HasPrefix(rx[i:], "[.]"):
+			b.WriteByte('.')
+			i += 2
+		case strings.HasPrefix(rx[i:], `\.`):
+			b.WriteByte('.')
+			i++
+		case c == '-' || c == '_' ||
+			c >= '0' && c <= '9' ||
+			c >= 'a' && c <= 'z' ||
+			c >= 'A' && c <= 'Z':
+			b.WriteByte(c)
+		default:
+			return "", false
+		}
+	}
+
+	h := b.String()
+	if h == "" || h[len(h)-1] == '.' {
+		return "", false
+	}
+
+	return h, true
+}
+
+// exactHosts returns the hostnames matched by a host regular expression,
+// when the expression matches only a fixed set of hostnames, optionally
+// followed by a dot and a port, like the ones generated for the
+// Kubernetes Ingresses and RouteGroups:
+//
+//	^(example[.]org[.]?(:[0-9]+)?|www[.]example[.]org[.]?(:[0-9]+)?)$
+//
+// The returned hostnames are normalized the same way as the request
+// hosts in the lookup.
+func exactHosts(rx string) ([]string, bool) {
+	if !strings.HasPrefix(rx, "^") || !strings.HasSuffix(rx, "$") || strings.HasSuffix(rx, `\$`) {
+		return nil, false
+	}
+
+	// the alternatives are accepted only inside a group wrapping the
+	// whole expression. At the top level, ^ binds only the first, and $
+	// only the last alternative, e.g. ^foo[.]org|bar[.]org$ matches
+	// www.bar.org, too.
+	rx = rx[1 : len(rx)-1]
+	alts, ok := splitAlternatives(rx)
+	if !ok || len(alts) != 1 {
+		return nil, false
+	}
+
+	if strings.HasPrefix(rx, "(") && strings.HasSuffix(rx, ")") {
+		if inner := rx[1 : len(rx)-1]; !strings.HasPrefix(inner, "?") {
+			if groupAlts, ok := splitAlternatives(inner); ok {
+				alts = groupAlts
+			}
+		}
+	}
+
+	hosts := make([]string, 0, len(alts))
+	for _, a := range alts {
+		a = strings.TrimSuffix(a, hostRxPortSuffix)
+		a = strings.TrimSuffix(a, hostRxDotSuffix)
+		h, ok := literalHost(a)
+		if !ok {
+			return nil, false
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	return hosts, true
+}
+
+// normalizeHost removes the port and the trailing dot from a request
+// host, without allocation.
+func normalizeHost(h string) string {
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i] >= '0' && h[i] <= '9' {
+			continue
+		}
+
+		if h[i] == ':' && i < len(h)-1 {
+			h = h[:i]
+		}
+
+		break
+	}
+
+	return strings.TrimSuffix(h, ".")
+}
+
+// routeHosts returns the exact hostnames of a route, if any. When a route
+// has multiple host conditions, it can match only the hosts of the
+// first exact one, while the rest of the conditions are still checked
+// by the leaf.
+func routeHosts(r *Route) []string {
+	for _, rx := range r.HostRegexps {
+		if hosts, ok := exactHosts(rx); ok {
+			return hosts
+		}
+	}
+
+	return nil
+}
+
+// newHostIndex creates a separate path tree for every exact hostname,
+// containing the routes of the hostname, and all the routes that don't
+// have an exact hostname. The leaves are added in the same order as to
+// the generic tree, and the paths that could not be added to the
+// generic tree are skipped, so that the lookup returns the same route
+// as the generic tree would.
+//
+// The host trees of the previous matcher are reused when neither the
+// routes of the host, nor the routes without an exact hostname have
+// changed. Besides the index, it returns the routes without an exact
+// hostname and the failed paths, to be compared in the next update.
+func newHostIndex(prev *matcher, entries []*leafEntry, rootLeaves leafMatchers, failedPaths map[string]bool) (
+	map[string]*hostMatcher,
+	[]*leafEntry,
+	map[string]bool,
+) {
+	byHost := make(map[string][]*leafEntry)
+	var generic []*leafEntry
+	for _, e := range entries {
+		if e.hosts == nil {
+			generic = append(generic, e)
+			continue
+		}
+
+		for _, h := range e.hosts {
+			byHost[h] = append(byHost[h], e)
+		}
+	}
+
+	if len(byHost) == 0 {
+		return nil, generic, failedPaths
+	}
+
+	canReuse := prev != nil &&
+		eqEntries(prev.genericEntries, generic) &&
+		eqFailedPaths(prev.failedPaths, failedPaths)
+
+	index := make(map[string]*hostMatcher, len(byHost))
+	for h, hostEntries := range byHost {
+		if canReuse {
+			if hm, ok := prev.hosts[h]; ok && eqEntries(hm.entries, hostEntries) {
+				index[h] = hm
+				continue
+			}
+		}
+
+		index[h] = newHostMatcher(hostEntries, generic, rootLeaves, failedPaths)
+	}
+
+	return index, generic, failedPaths
+}
+
+func newHostMatcher(hostEntries, generic []*leafEntry, rootLeaves leafMatchers, failedPaths map[string]bool) *hostMatcher {
+	pms := make(map[string]*pathMatcher)
+	inHost := make(map[*leafMatcher]bool, len(hostEntries))
+	for _, e := range mergeEntries(hostEntries, generic) {
+		inHost[e.leaf] = true
+		for _, p := range e.paths {
+			if !failedPaths[p] {
+				addLeafToPath(pms, p, e.leaf)
+			}
+		}
+	}
+
+	tree := &pathmux.Tree{}
+	for p, pm := range pms {
+		sort.Stable(pm.leaves)
+
+		// cannot fail, because the same paths were accepted by
+		// the generic tree
+		tree.Add(p, pm)
+	}
+
+	var hostRootLeaves leafMatchers
+	for _, l := range rootLeaves {
+		if inHost[l] {
+			hostRootLeaves = append(hostRootLeaves, l)
+		}
+	}
+
+	return &hostMatcher{paths: tree, rootLeaves: hostRootLeaves, entries: hostEntries}
+}
+
+// checks if two lists of leaf entries contain the same leaves in the
+// same order relative to all the routes
+func eqEntries(a, b []*leafEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].leaf != b[i].leaf || a[i].index != b[i].index {
+			return false
+		}
+	}
+
+	return true
+}
+
+func eqFailedPaths(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for p := range a {
+		if !b[p] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// merges two lists of leaf entries keeping the original order of the
+// routes. Both lists need to be in the original order.
+func mergeEntries(a, b []*leafEntry) []*leafEntry {
+	m := make([]*leafEntry, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0].index < b[0].index {
+			m, a = append(m, a[0]), a[1:]
+		} else {
+			m, b = append(m, b[0]), b[1:]
+		}
+	}
+
+	m = append(m, a...)
+	return append(m, b...)
+}
@@ -0,0 +1,266 @@
+package routing
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExactHosts(t *testing.T) {
+	for _, test := range []struct {
+		rx    string
+		hosts []string
+	}{
+		{rx: "^example[.]org$", hosts: []string{"example.org"}},
+		{rx: `^example\.org$`, hosts: []string{"example.org"}},
+		{rx: "^example[.]org[.]?(:[0-9]+)?$", hosts: []string{"example.org"}},
+		{rx: "^(example[.]org[.]?(:[0-9]+)?)$", hosts: []string{"example.org"}},
+		{
+			rx:    "^(example[.]org[.]?(:[0-9]+)?|www[.]example[.]org[.]?(:[0-9]+)?)$",
+			hosts: []string{"example.org", "www.example.org"},
+		},
+		{rx: "^my-app_1[.]example[.]org$", hosts: []string{"my-app_1.example.org"}},
+		{rx: "example[.]org"},
+		{rx: "^example[.]org"},
+		{rx: "example[.]org$"},
+		{rx: "^example.org$"},
+		{rx: "^.*[.]example[.]org$"},
+		{rx: "^(www|api)[.]example[.]org$"},
+		{rx: "^example[.]org:8080$"},
+		{rx: "^example[.]org[.]$"},
+		{rx: "^(example[.]org$"},
+		{rx: "^(?i)example[.]org$"},
+		{rx: `^example[.]org\$`},
+		{rx: "^$"},
+		{rx: "^(example[.]org|)$"},
+		{rx: "^foo[.]org|bar[.]org$"},
+		{rx: "^(foo[.]org)|(bar[.]org)$"},
+	} {
+		t.Run(test.rx, func(t *testing.T) {
+			hosts, ok := exactHosts(test.rx)
+			if ok != (test.hosts != nil) || !reflect.DeepEqual(hosts, test.hosts) {
+				t.Errorf("invalid hosts, expected: %v, got: %v, %v", test.hosts, hosts, ok)
+			}
+		})
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	for host, expected := range map[string]string{
+		"":                  "",
+		"example.org":       "example.org",
+		"example.org.":      "example.org",
+		"example.org:8080":  "example.org",
+		"example.org.:8080": "example.org",
+		"example.org:":      "example.org:",
+		"127.0.0.1:9090":    "127.0.0.1",
+		"42":                "42",
+	} {
+		if h := normalizeHost(host); h != expected {
+			t.Errorf("invalid normalized host for %q, expected: %q, got: %q", host, expected, h)
+		}
+	}
+}
+
+func hostIndexRoutes(hostCount int) string {
+	var doc strings.Builder
+	for i := 0; i < hostCount; i++ {
+		host := fmt.Sprintf("host%d[.]example[.]org", i)
+		kubeHost := fmt.Sprintf("^(%s[.]?(:[0-9]+)?|www[.]%s[.]?(:[0-9]+)?)$", host, host)
+		fmt.Fprintf(&doc, `host%d_root: Host("%s") -> "https://host%d.root";`, i, kubeHost, i)
+		fmt.Fprintf(&doc, `host%d_api: Host("%s") && PathSubtree("/api") -> "https://host%d.api";`, i, kubeHost, i)
+		fmt.Fprintf(&doc, `host%d_item: Host("%s") && Path("/items/:id") -> "https://host%d.item";`, i, kubeHost, i)
+		fmt.Fprintf(&doc, `host%d_post: Host("^%s$") && Method("POST") && Path("/items/:id") -> "https://host%d.post";`, i, host, i)
+		fmt.Fprintf(&doc, `host%d_multi: Host("^%s$") && Host("^.*$") && Path("/multi") -> "https://host%d.multi";`, i, host, i)
+	}
+
+	doc.WriteString(`
+		generic_root: * -> "https://generic.root";
+		generic_health: Path("/healthz") -> "https://generic.health";
+		generic_item: Path("/items/:id") && Header("X-Generic", "true") -> "https://generic.item";
+		generic_api: PathSubtree("/api") && Header("X-Generic", "true") -> "https://generic.api";
+		regexp_host: Host("[.]example[.]org$") && Path("/items/:id") && Method("PUT") -> "https://regexp.item";
+		regexp_root: Host("^www[.]") -> "https://regexp.root";
+		regexp_multi: Host("^(host1|host2)[.]example[.]org$") && Path("/multi") -> "https://regexp.multi";
+	`)
+
+	return doc.String()
+}
+
+func hostIndexRequests(hostCount int) []*http.Request {
+	var hosts []string
+	for i := 0; i < hostCount+2; i++ {
+		h := fmt.Sprintf("host%d.example.org", i)
+		hosts = append(hosts, h, "www."+h, h+".", h+":9090", h+".:443", h+":", strings.ToUpper(h))
+	}
+
+	hosts = append(hosts, "", "example.org", "www.example.org")
+
+	paths := []string{"/", "/healthz", "/api", "/api/", "/api/foo/bar", "/items/42", "/items", "/multi", "/other"}
+	headers := []http.Header{{}, {"X-Generic": []string{"true"}}}
+	methods := []string{"GET", "POST", "PUT"}
+
+	var requests []*http.Request
+	for _, h := range hosts {
+		for _, p := range paths {
+			for _, hd := range headers {
+				for _, m := range methods {
+					requests = append(requests, &http.Request{
+						Method: m,
+						Host:   h,
+						URL:    &url.URL{Path: p},
+						Header: hd,
+					})
+				}
+			}
+		}
+	}
+
+	return requests
+}
+
+func TestHostIndexMatchesGenericTree(t *testing.T) {
+	const hostCount = 12
+	for _, o := range []MatchingOptions{MatchingOptionsNone, IgnoreTrailingSlash} {
+		t.Run(fmt.Sprintf("options=%d", o), func(t *testing.T) {
+			m, err := docToMatcherOpts(hostIndexRoutes(hostCount), o)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the exact hosts and their www variants
+			if len(m.hosts) != 2*hostCount {
+				t.Fatalf("invalid number of indexed hosts, expected: %d, got: %d", 2*hostCount, len(m.hosts))
+			}
+
+			generic := &matcher{paths: m.paths, rootLeaves: m.rootLeaves, matchingOptions: o}
+			for _, r := range hostIndexRequests(hostCount) {
+				indexedRoute, indexedParams := m.match(r)
+				genericRoute, genericParams := generic.match(r)
+				if indexedRoute != genericRoute || !reflect.DeepEqual(indexedParams, genericParams) {
+					t.Errorf(
+						"different match for %s %s%s %v, indexed: %v %v, generic: %v %v",
+						r.Method, r.Host, r.URL.Path, r.Header,
+						routeID(indexedRoute), indexedParams, routeID(genericRoute), genericParams,
+					)
+				}
+			}
+		})
+	}
+}
+
+func routeID(r *Route) string {
+	if r == nil {
+		return "<nil>"
+	}
+
+	return r.Id
+}
+
+func TestHostIndexMatchesGenericTreeRandomOrder(t *testing.T) {
+	const hostCount = 6
+	routes, err := docToRoutes(hostIndexRoutes(hostCount))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rnd := rand.New(rand.NewSource(42))
+	requests := hostIndexRequests(hostCount)
+	for i := 0; i < 12; i++ {
+		rnd.Shuffle(len(routes), func(i, j int) { routes[i], routes[j] = routes[j], routes[i] })
+		m, err := newTestMatcher(routes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		generic := &matcher{paths: m.paths, rootLeaves: m.rootLeaves}
+		for _, r := range requests {
+			indexedRoute, _ := m.match(r)
+			genericRoute, _ := generic.match(r)
+			if indexedRoute != genericRoute {
+				t.Errorf(
+					"different match for %s %s%s, indexed: %s, generic: %s",
+					r.Method, r.Host, r.URL.Path, routeID(indexedRoute), routeID(genericRoute),
+				)
+			}
+		}
+	}
+}
+
+func TestHostIndexSkipsConflictingPaths(t *testing.T) {
+	m, errs := newMatcher(mustDocToRoutes(t, `
+		a: Host("^a[.]example[.]org$") && Path("/foo/:id") -> "https://a.foo";
+		b: Path("/foo/*id") -> "https://b.foo";
+	`), MatchingOptionsNone)
+
+	generic := &matcher{paths: m.paths, rootLeaves: m.rootLeaves}
+	r := &http.Request{Method: "GET", Host: "a.example.org", URL: &url.URL{Path: "/foo/bar"}}
+	indexedRoute, _ := m.match(r)
+	genericRoute, _ := generic.match(r)
+	if indexedRoute != genericRoute {
+		t.Errorf("different match, indexed: %s, generic: %s, errors: %v", routeID(indexedRoute), routeID(genericRoute), errs)
+	}
+}
+
+func TestHostIndexTopLevelAlternation(t *testing.T) {
+	m, err := docToMatcher(`
+		alt: Host("^foo[.]org|bar[.]org$") && Path("/x") -> "https://alt";
+		www: Host("^www[.]bar[.]org$") && Path("/y") -> "https://www";
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &http.Request{Method: "GET", Host: "www.bar.org", URL: &url.URL{Path: "/x"}}
+	if route, _ := m.match(r); route == nil || route.Id != "alt" {
+		t.Errorf("failed to match the top level alternation, got: %s", routeID(route))
+	}
+}
+
+func mustDocToRoutes(t testing.TB, doc string) []*Route {
+	routes, err := docToRoutes(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return routes
+}
+
+func benchmarkHostIndex(b *testing.B, indexed bool) {
+	const hostCount = 2000
+	m, err := docToMatcher(hostIndexRoutes(hostCount))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if !indexed {
+		m = &matcher{paths: m.paths, rootLeaves: m.rootLeaves}
+	}
+
+	var requests []*http.Request
+	for i := 0; i < hostCount; i++ {
+		requests = append(requests, &http.Request{
+			Method: "GET",
+			Host:   fmt.Sprintf("host%d.example.org", i),
+			URL:    &url.URL{Path: "/items/42"},
+			Header: http.Header{},
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := requests[i%len(requests)]
+		if route, _ := m.match(r); route == nil || route.Backend != "https://"+strings.Split(r.Host, ".")[0]+".item" {
+			b.Fatalf("failed to match %s", r.Host)
+		}
+	}
+}
+
+func BenchmarkHostIndex(b *testing.B) {
+	b.Run("indexed", func(b *testing.B) { benchmarkHostIndex(b, true) })
+	b.Run("generic", func(b *testing.B) { benchmarkHostIndex(b, false) })
+}
@@ -0,0 +1,71 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// InvalidRoutesMetricsPrefix is the prefix of the gauges reporting the
+// number of the invalid routes per data client.
+const InvalidRoutesMetricsPrefix = "routes.invalid."
+
+// InvalidRouteReporter can be implemented by the data clients that
+// need to know about their routes that could not be processed, e.g. to
+// notify the owners of the route definitions.
+type InvalidRouteReporter interface {
+
+	// ReportInvalidRoutes is called every time a new route table is
+	// applied, with all the invalid routes of the data client. It
+	// is called from the routing update loop, so it must not block.
+	ReportInvalidRoutes([]InvalidRoute)
+}
+
+// reportInvalid updates the invalid route gauges, and notifies the data
+// clients implementing InvalidRouteReporter.
+func (r *Routing) reportInvalid(g *Generation) {
+	byClient := make(map[DataClient][]InvalidRoute)
+	for _, ir := range g.Invalid {
+		if ir.client != nil {
+			byClient[ir.client] = append(byClient[ir.client], ir)
+		}
+	}
+
+	for _, c := range r.dataClients {
+		ir := byClient[c]
+		if r.metrics != nil {
+			r.metrics.UpdateGauge(InvalidRoutesMetricsPrefix+dataClientName(c), float64(len(ir)))
+		}
+
+		if rr, ok := c.(InvalidRouteReporter); ok {
+			rr.ReportInvalidRoutes(ir)
+		}
+	}
+}
+
+// InvalidRoutes returns the routes of the current route table that
+// could not be processed, together with the reason.
+func (r *Routing) InvalidRoutes() []InvalidRoute {
+	rt := r.routeTable.Load().(*routeTable)
+	if rt.generation == nil || rt.generation.Invalid == nil {
+		return []InvalidRoute{}
+	}
+
+	return rt.generation.Invalid
+}
+
+// InvalidRoutesHandler returns the handler serving the invalid routes
+// of the current route table as JSON.
+func (r *Routing) InvalidRoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.InvalidRoutes()); err != nil {
+			r.log.Errorf("Failed to encode invalid routes: %v", err)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package routing_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/logging/loggingtest"
+	"github.com/zalando/skipper/metrics/metricstest"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
+)
+
+type reportingDataClient struct {
+	*testdataclient.Client
+	mu       sync.Mutex
+	reported [][]routing.InvalidRoute
+}
+
+func (c *reportingDataClient) ReportInvalidRoutes(ir []routing.InvalidRoute) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reported = append(c.reported, ir)
+}
+
+func (c *reportingDataClient) last() []routing.InvalidRoute {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.reported) == 0 {
+		return nil
+	}
+
+	return c.reported[len(c.reported)-1]
+}
+
+func invalidIds(ir []routing.InvalidRoute) []string {
+	var ids []string
+	for _, r := range ir {
+		ids = append(ids, r.Id)
+	}
+
+	return ids
+}
+
+func TestInvalidRoutes(t *testing.T) {
+	defs, err := eskip.Parse(`
+		valid: * -> <shunt>;
+		filter: * -> setPath() -> <shunt>;
+		unknownFilter: * -> foo() -> <shunt>;
+		unknownPredicate: Foo() -> <shunt>;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not accepted by the parser
+	defs = append(defs, &eskip.Route{
+		Id:          "predicate",
+		Predicates:  []*eskip.Predicate{{Name: "Method", Args: []interface{}{42.0}}},
+		BackendType: eskip.ShuntBackend,
+	}, &eskip.Route{
+		Id:          "weight",
+		Predicates:  []*eskip.Predicate{{Name: "Weight", Args: []interface{}{"foo"}}},
+		BackendType: eskip.ShuntBackend,
+	})
+
+	dc1 := testdataclient.New(defs)
+	rdc := &reportingDataClient{Client: dc1}
+	dc2, err := testdataclient.NewDoc(`backend: * -> "invalid backend"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &metricstest.MockMetrics{}
+	tl := loggingtest.New()
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		DataClients:    []routing.DataClient{rdc, dc2},
+		PollTimeout:    pollTimeout,
+		Metrics:        m,
+		Log:            tl,
+	})
+
+	tr := &testRouting{tl, rt}
+	defer tr.close()
+	if err := tr.waitForNRouteSettings(2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]routing.InvalidRoute{
+		"filter":           {Filter: "setPath"},
+		"unknownFilter":    {Filter: "foo"},
+		"predicate":        {Predicate: "Method"},
+		"unknownPredicate": {Predicate: "Foo"},
+		"weight":           {Predicate: "Weight"},
+		"backend":          {},
+	}
+
+	invalid := rt.InvalidRoutes()
+	if len(invalid) != len(expected) {
+		t.Fatalf("invalid number of invalid routes, expected: %d, got: %d: %v", len(expected), len(invalid), invalidIds(invalid))
+	}
+
+	for _, ir := range invalid {
+		e, ok := expected[ir.Id]
+		if !ok {
+			t.Errorf("unexpected invalid route: %s", ir.Id)
+			continue
+		}
+
+		if ir.Filter != e.Filter || ir.Predicate != e.Predicate || ir.Error == "" {
+			t.Errorf("invalid report for %s: %+v", ir.Id, ir)
+		}
+
+		if ir.DataClient != testDataClientName && ir.DataClient != "*routing_test.reportingDataClient" {
+			t.Errorf("invalid data client for %s: %s", ir.Id, ir.DataClient)
+		}
+	}
+
+	if v, ok := m.Gauge(routing.InvalidRoutesMetricsPrefix + "*routing_test.reportingDataClient"); !ok || v != 5 {
+		t.Errorf("invalid gauge of the reporting client, expected: 5, got: %v", v)
+	}
+
+	if v, ok := m.Gauge(routing.InvalidRoutesMetricsPrefix + testDataClientName); !ok || v != 1 {
+		t.Errorf("invalid gauge of the test client, expected: 1, got: %v", v)
+	}
+
+	ids := invalidIds(rdc.last())
+	if !reflect.DeepEqual(ids, []string{"filter", "predicate", "unknownFilter", "unknownPredicate", "weight"}) {
+		t.Errorf("invalid routes not reported to the data client: %v", ids)
+	}
+
+	tr.log.Reset()
+	dc1.Update(nil, []string{"filter", "unknownFilter", "predicate", "unknownPredicate", "weight"})
+	if err := tr.waitForRouteSetting(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := m.Gauge(routing.InvalidRoutesMetricsPrefix + "*routing_test.reportingDataClient"); v != 0 {
+		t.Errorf("invalid gauge of the reporting client, expected: 0, got: %v", v)
+	}
+
+	if r := rdc.last(); len(r) != 0 {
+		t.Errorf("unexpected invalid routes reported: %v", invalidIds(r))
+	}
+
+	w := httptest.NewRecorder()
+	rt.InvalidRoutesHandler().ServeHTTP(w, httptest.NewRequest("GET", "/routes/invalid", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("invalid status, expected: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	var got []routing.InvalidRoute
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].Id != "backend" || got[0].DataClient != testDataClientName {
+		t.Errorf("invalid response: %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	rt.InvalidRoutesHandler().ServeHTTP(w, httptest.NewRequest("POST", "/routes/invalid", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("invalid status, expected: %d, got: %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
@@ -0,0 +1,429 @@
+package routing
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/predicates"
+)
+
+// LintType tells the kind of a problem found by the route linter.
+type LintType int
+
+const (
+	// LintUnreachable is reported for the routes that can never be
+	// matched, because a route with the same or broader conditions always
+	// takes precedence over them.
+	LintUnreachable LintType = iota
+
+	// LintDuplicate is reported for the routes with identical matching
+	// conditions.
+	LintDuplicate
+
+	// LintAmbiguous is reported for the routes in the same position of the
+	// routing tree, with equal priority and weight, that can match the same
+	// requests. Their order is unspecified.
+	LintAmbiguous
+)
+
+func (t LintType) String() string {
+	switch t {
+	case LintUnreachable:
+		return "unreachable"
+	case LintDuplicate:
+		return "duplicate"
+	case LintAmbiguous:
+		return "ambiguous"
+	default:
+		return "unknown"
+	}
+}
+
+// LintIssue is a problem found by the route linter.
+type LintIssue struct {
+	Type LintType
+
+	// Path is the path tree entry where the problem was found. It is
+	// empty for the routes without a Path or PathSubtree predicate.
+	Path string
+
+	// Routes contains the ids of the affected routes.
+	Routes []string
+
+	// ShadowedBy is set for the unreachable routes, and contains the id of
+	// the route that takes precedence.
+	ShadowedBy string
+}
+
+func (i LintIssue) String() string {
+	path := i.Path
+	if path == "" {
+		path = "<root>"
+	}
+
+	routes := strings.Join(i.Routes, ", ")
+	switch i.Type {
+	case LintUnreachable:
+		return fmt.Sprintf("%s: route %s at path %s is shadowed by %s", i.Type, routes, path, i.ShadowedBy)
+	case LintDuplicate:
+		return fmt.Sprintf("%s: routes with identical conditions at path %s: %s", i.Type, path, routes)
+	default:
+		return fmt.Sprintf(
+			"%s: routes with equal priority and weight overlap at path %s, their order is unspecified: %s",
+			i.Type, path, routes,
+		)
+	}
+}
+
+// placeholder for the custom predicates during static analysis. The
+// linter compares the predicate definitions instead of evaluating them.
+type lintPredicate struct{}
+
+func (lintPredicate) Match(*http.Request) bool { return false }
+
+// processes a route definition without the filters and the predicate
+// specs, keeping only what's relevant for the matching order
+func lintRoute(def *eskip.Route) (*Route, error) {
+	def, err := mergeLegacyNonTreePredicates(def)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ps     []Predicate
+		weight int
+	)
+
+	for _, p := range def.Predicates {
+		switch {
+		case p.Name == predicates.WeightName:
+			if weight, err = parseWeightPredicateArgs(p.Args); err != nil {
+				return nil, predicateError(p.Name, err)
+			}
+		case isTreePredicate(p.Name):
+		default:
+			ps = append(ps, lintPredicate{})
+		}
+	}
+
+	r := &Route{Route: *def, Predicates: ps, weight: weight}
+	if err := processTreePredicates(r, def.Predicates); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Lint analyzes a set of route definitions statically, and reports the
+// routes that can never be matched, the routes with duplicate matching
+// conditions, and the routes whose order is unspecified.
+//
+// The predicates are not evaluated, the custom predicates are compared
+// by their name and arguments. Only the routes in the same position of
+// the path tree are compared. The invalid routes are ignored.
+func Lint(defs []*eskip.Route, o MatchingOptions) []LintIssue {
+	var routes []*Route
+	for _, def := range defs {
+		if r, err := lintRoute(def); err == nil {
+			routes = append(routes, r)
+		}
+	}
+
+	m, _ := newMatcher(routes, o)
+	return lintMatcher(m, routes)
+}
+
+// the key identifies the routes with the same paths and conditions
+type lintLeaf struct {
+	leaf       *leafMatcher
+	hosts      []string
+	paths      []string
+	conditions []string
+	key        string
+	random     bool
+}
+
+// the matching conditions of a route, excluding the path tree
+// predicates and the weight
+func lintConditions(l *leafMatcher) ([]string, bool) {
+	var (
+		c      []string
+		random bool
+	)
+
+	if l.method != "" {
+		c = append(c, "Method="+l.method)
+	}
+
+	for _, rx := range l.hostRxs {
+		c = append(c, "Host="+rx.String())
+	}
+
+	for _, rx := range l.pathRxs {
+		c = append(c, "PathRegexp="+rx.String())
+	}
+
+	for k, v := range l.headersExact {
+		c = append(c, "Header="+k+"="+v)
+	}
+
+	for k, rxs := range l.headersRegexp {
+		for _, rx := range rxs {
+			c = append(c, "HeaderRegexp="+k+"="+rx.String())
+		}
+	}
+
+	for _, p := range l.route.Route.Predicates {
+		if isTreePredicate(p.Name) || p.Name == predicates.WeightName {
+			continue
+		}
+
+		if p.Name == predicates.TrafficName {
+			random = true
+		}
+
+		c = append(c, fmt.Sprintf("%s%v", p.Name, p.Args))
+	}
+
+	sort.Strings(c)
+	return c, random
+}
+
+func containsConditions(outer, inner []string) bool {
+	set := make(map[string]bool, len(outer))
+	for _, c := range outer {
+		set[c] = true
+	}
+
+	for _, c := range inner {
+		if !set[c] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tells whether two routes can never match the same request, based on
+// their method, exact headers and exact hosts
+func disjoint(left, right *lintLeaf) bool {
+	if left.leaf.method != "" && right.leaf.method != "" && left.leaf.method != right.leaf.method {
+		return true
+	}
+
+	for k, v := range left.leaf.headersExact {
+		if rv, ok := right.leaf.headersExact[k]; ok && rv != v {
+			return true
+		}
+	}
+
+	return !hostsOverlap(left.hosts, right.hosts)
+}
+
+func lintLeaves(m *matcher, routes []*Route) ([]*lintLeaf, map[string][]*lintLeaf) {
+	var all []*lintLeaf
+	byPath := make(map[string][]*lintLeaf)
+	for _, r := range routes {
+		e, ok := m.leaves[r]
+		if !ok || m.failedPaths[firstPath(e.paths)] {
+			continue
+		}
+
+		conditions, random := lintConditions(e.leaf)
+		l := &lintLeaf{
+			leaf:       e.leaf,
+			hosts:      e.hosts,
+			paths:      e.paths,
+			conditions: conditions,
+			key:        strings.Join(append(append([]string{}, e.paths...), conditions...), "\n"),
+			random:     random,
+		}
+
+		all = append(all, l)
+		if e.paths == nil {
+			byPath[""] = append(byPath[""], l)
+			continue
+		}
+
+		for _, p := range e.paths {
+			byPath[p] = append(byPath[p], l)
+		}
+	}
+
+	for _, ls := range byPath {
+		sort.SliceStable(ls, func(i, j int) bool { return precedes(ls[i], ls[j]) })
+	}
+
+	return all, byPath
+}
+
+// tells whether a route is always evaluated before another one in the
+// same position of the path tree
+func precedes(left, right *lintLeaf) bool {
+	return leafMatchers{left.leaf, right.leaf}.Less(0, 1)
+}
+
+func firstPath(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	return paths[0]
+}
+
+func lintIDs(ls []*lintLeaf) []string {
+	ids := make([]string, len(ls))
+	for i, l := range ls {
+		ids[i] = l.leaf.route.Id
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func lintDuplicates(path string, ls []*lintLeaf) []LintIssue {
+	var (
+		issues []LintIssue
+		keys   []string
+	)
+
+	groups := make(map[string][]*lintLeaf)
+	for _, l := range ls {
+		if _, ok := groups[l.key]; !ok {
+			keys = append(keys, l.key)
+		}
+
+		groups[l.key] = append(groups[l.key], l)
+	}
+
+	for _, k := range keys {
+		if len(groups[k]) > 1 {
+			issues = append(issues, LintIssue{Type: LintDuplicate, Path: path, Routes: lintIDs(groups[k])})
+		}
+	}
+
+	return issues
+}
+
+func lintAmbiguous(path string, ls []*lintLeaf) []LintIssue {
+	var issues []LintIssue
+	for i := 0; i < len(ls); {
+		j := i + 1
+		for j < len(ls) && !precedes(ls[i], ls[j]) && !precedes(ls[j], ls[i]) {
+			j++
+		}
+
+		group := ls[i:j]
+		i = j
+
+		var overlapping []*lintLeaf
+		for k, l := range group {
+			for n, other := range group {
+				if k != n && l.key != other.key && !disjoint(l, other) {
+					overlapping = append(overlapping, l)
+					break
+				}
+			}
+		}
+
+		if len(overlapping) > 0 {
+			issues = append(issues, LintIssue{Type: LintAmbiguous, Path: path, Routes: lintIDs(overlapping)})
+		}
+	}
+
+	return issues
+}
+
+// returns the route that always takes precedence over a route at a
+// path, if any
+func shadowingRoute(ls []*lintLeaf, l *lintLeaf) *lintLeaf {
+	for _, other := range ls {
+		if other == l {
+			return nil
+		}
+
+		if other.random || other.key == l.key || !precedes(other, l) {
+			continue
+		}
+
+		if containsConditions(l.conditions, other.conditions) {
+			return other
+		}
+	}
+
+	return nil
+}
+
+// a route is unreachable when it is shadowed at every path where it is
+// added to the path tree
+func lintUnreachable(all []*lintLeaf, byPath map[string][]*lintLeaf) []LintIssue {
+	var issues []LintIssue
+	for _, l := range all {
+		paths := l.paths
+		if paths == nil {
+			paths = []string{""}
+		}
+
+		var first *lintLeaf
+		for i, p := range paths {
+			s := shadowingRoute(byPath[p], l)
+			if s == nil {
+				first = nil
+				break
+			}
+
+			if i == 0 {
+				first = s
+			}
+		}
+
+		if first != nil {
+			issues = append(issues, LintIssue{
+				Type:       LintUnreachable,
+				Path:       paths[0],
+				Routes:     []string{l.leaf.route.Id},
+				ShadowedBy: first.leaf.route.Id,
+			})
+		}
+	}
+
+	return issues
+}
+
+// analyzes the routes of a matcher. The path subtree routes are added
+// to multiple paths, these issues are reported only once.
+func lintMatcher(m *matcher, routes []*Route) []LintIssue {
+	all, byPath := lintLeaves(m, routes)
+
+	var paths []string
+	for p := range byPath {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+
+	var issues []LintIssue
+	for _, p := range paths {
+		issues = append(issues, lintDuplicates(p, byPath[p])...)
+	}
+
+	issues = append(issues, lintUnreachable(all, byPath)...)
+	for _, p := range paths {
+		issues = append(issues, lintAmbiguous(p, byPath[p])...)
+	}
+
+	var unique []LintIssue
+	found := make(map[string]bool)
+	for _, i := range issues {
+		key := fmt.Sprint(i.Type, i.Routes)
+		if !found[key] {
+			found[key] = true
+			unique = append(unique, i)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,167 @@
+package routing_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/logging/loggingtest"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
+)
+
+func TestLint(t *testing.T) {
+	for _, test := range []struct {
+		title  string
+		doc    string
+		issues []routing.LintIssue
+	}{{
+		title: "no issues",
+		doc: `
+			a: Path("/foo") -> <shunt>;
+			b: Path("/foo") && Method("GET") -> <shunt>;
+			c: Path("/bar") -> <shunt>;
+			d: * -> <shunt>;
+		`,
+	}, {
+		title: "duplicate matchers",
+		doc: `
+			a: Path("/foo") && Method("GET") -> "https://a.example.org";
+			b: Method("GET") && Path("/foo") -> "https://b.example.org";
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintDuplicate, Path: "/foo", Routes: []string{"a", "b"}}},
+	}, {
+		title: "duplicate legacy matchers",
+		doc: `
+			a: Path("/foo") && Header("X-Foo", "bar") -> <shunt>;
+			b: Path("/foo") && Header("x-foo", "bar") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintDuplicate, Path: "/foo", Routes: []string{"a", "b"}}},
+	}, {
+		title: "duplicate path subtree reported once",
+		doc: `
+			a: PathSubtree("/foo") -> <shunt>;
+			b: PathSubtree("/foo") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintDuplicate, Path: "/foo", Routes: []string{"a", "b"}}},
+	}, {
+		title: "unreachable by priority",
+		doc: `
+			a [priority=1]: Path("/foo") -> <shunt>;
+			b: Path("/foo") && Method("GET") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintUnreachable, Path: "/foo", Routes: []string{"b"}, ShadowedBy: "a"}},
+	}, {
+		title: "unreachable by weight",
+		doc: `
+			a: Path("/foo") && Weight(3) -> <shunt>;
+			b: Path("/foo") && Method("GET") && Header("X-Foo", "bar") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintUnreachable, Path: "/foo", Routes: []string{"b"}, ShadowedBy: "a"}},
+	}, {
+		title: "unreachable custom predicate",
+		doc: `
+			a [priority=1]: Cookie("foo", "bar") -> <shunt>;
+			b: Cookie("foo", "bar") && Method("GET") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintUnreachable, Routes: []string{"b"}, ShadowedBy: "a"}},
+	}, {
+		title: "shadowed by random predicate is reachable",
+		doc: `
+			a [priority=1]: Path("/foo") && Traffic(.5) -> <shunt>;
+			b: Path("/foo") && Traffic(.5) && Method("GET") -> <shunt>;
+		`,
+	}, {
+		title: "path subtree shadowed only at the base path is reachable",
+		doc: `
+			a [priority=1]: Path("/foo") -> <shunt>;
+			b: PathSubtree("/foo") -> <shunt>;
+		`,
+	}, {
+		title: "path subtree shadowed at every path",
+		doc: `
+			a [priority=1]: PathSubtree("/foo") -> <shunt>;
+			b: PathSubtree("/foo") && Method("GET") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintUnreachable, Path: "/foo", Routes: []string{"b"}, ShadowedBy: "a"}},
+	}, {
+		title: "path and path subtree with the same conditions",
+		doc: `
+			a: Path("/foo") -> <shunt>;
+			b: PathSubtree("/foo") -> <shunt>;
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintAmbiguous, Path: "/foo", Routes: []string{"a", "b"}}},
+	}, {
+		title: "ambiguous overlap",
+		doc: `
+			a: Path("/foo") && Method("GET") -> "https://a.example.org";
+			b: Path("/foo") && Header("X-Foo", "bar") -> "https://b.example.org";
+		`,
+		issues: []routing.LintIssue{{Type: routing.LintAmbiguous, Path: "/foo", Routes: []string{"a", "b"}}},
+	}, {
+		title: "disjoint routes with equal weight",
+		doc: `
+			a: Path("/foo") && Method("GET") -> <shunt>;
+			b: Path("/foo") && Method("POST") -> <shunt>;
+			c: Path("/bar") && Host("^c[.]example[.]org$") -> <shunt>;
+			d: Path("/bar") && Host("^d[.]example[.]org$") -> <shunt>;
+			e: Path("/baz") && Header("X-Foo", "bar") && Header("X-Bar", "baz") -> <shunt>;
+			f: Path("/baz") && Header("X-Foo", "qux") && Header("X-Bar", "baz") -> <shunt>;
+		`,
+	}, {
+		title: "invalid routes ignored",
+		doc: `
+			a: Path("/foo") && Weight("invalid") -> <shunt>;
+			b: Path("/foo") && Weight("invalid") -> <shunt>;
+		`,
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			routes, err := eskip.Parse(test.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			issues := routing.Lint(routes, routing.MatchingOptionsNone)
+			if !reflect.DeepEqual(issues, test.issues) {
+				t.Errorf("invalid lint issues, expected: %v, got: %v", test.issues, issues)
+			}
+		})
+	}
+}
+
+func TestLintOnUpdate(t *testing.T) {
+	dc, err := testdataclient.NewDoc(`
+		a [priority=1]: Path("/foo") -> <shunt>;
+		b: Path("/foo") && Method("GET") -> <shunt>;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tl := loggingtest.New()
+	defer tl.Close()
+
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		DataClients:    []routing.DataClient{dc},
+		PollTimeout:    pollTimeout,
+		LintRoutes:     true,
+		Log:            tl,
+	})
+	defer rt.Close()
+
+	if err := tl.WaitFor("unreachable: route b at path /foo is shadowed by a", 120*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	tl.Reset()
+	if err := dc.UpdateDoc(`c: Path("/foo") && Method("GET") -> <shunt>;`, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tl.WaitFor("duplicate: routes with identical conditions at path /foo: b, c", 120*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -34,10 +34,13 @@ type leafMatcher struct {
 	exactPath            string
 	method               string
 	weight               int
+	priority             int
 	hostRxs              []*regexp.Regexp
 	pathRxs              []*regexp.Regexp
 	headersExact         map[string]string
 	headersRegexp        map[string][]*regexp.Regexp
+	headerKeys           []string // sorted keys of headersExact
+	headerRegexpKeys     []string // sorted keys of headersRegexp
 	predicates           []Predicate
 	route                *Route
 }
@@ -60,10 +63,17 @@ func leafWeight(l *leafMatcher) int {
 	return w
 }
 
-// Sorting of leaf matchers:
-func (ls leafMatchers) Len() int           { return len(ls) }
-func (ls leafMatchers) Swap(i, j int)      { ls[i], ls[j] = ls[j], ls[i] }
-func (ls leafMatchers) Less(i, j int) bool { return leafWeight(ls[i]) > leafWeight(ls[j]) }
+// Sorting of leaf matchers, the explicit route priority takes precedence
+// over the weight:
+func (ls leafMatchers) Len() int      { return len(ls) }
+func (ls leafMatchers) Swap(i, j int) { ls[i], ls[j] = ls[j], ls[i] }
+func (ls leafMatchers) Less(i, j int) bool {
+	if ls[i].priority != ls[j].priority {
+		return ls[i].priority > ls[j].priority
+	}
+
+	return leafWeight(ls[i]) > leafWeight(ls[j])
+}
 
 type pathMatcher struct {
 	leaves leafMatchers
@@ -73,7 +83,15 @@ type pathMatcher struct {
 type matcher struct {
 	paths           *pathmux.Tree
 	rootLeaves      leafMatchers
+	hosts           map[string]*hostMatcher
 	matchingOptions MatchingOptions
+
+	// used to reuse the unchanged parts when creating the next matcher
+	leaves         map[*Route]*leafEntry
+	genericEntries []*leafEntry
+	failedPaths    map[string]bool
+
+	priorityConflicts []priorityConflict
 }
 
 // An error created if a route definition cannot be processed.
@@ -135,6 +153,16 @@ func canonicalizeHeaderRegexps(hrx map[string][]*regexp.Regexp) map[string][]*re
 	return chrx
 }
 
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
 // extracts the expected wildcard param names and returns them in reverse order
 func extractWildcardParamNames(r *Route) []string {
 	path := r.path
@@ -234,18 +262,23 @@ func newLeaf(r *Route, rxs map[string]*regexp.Regexp) (*leafMatcher, error) {
 		allHeaderRxs[k] = headerRxs
 	}
 
+	headersExact := canonicalizeHeaders(r.Headers)
+	headersRegexp := canonicalizeHeaderRegexps(allHeaderRxs)
 	return &leafMatcher{
 		wildcardParamNames:   extractWildcardParamNames(r),
 		hasFreeWildcardParam: hasFreeWildcardParam(r),
 
-		weight:        r.weight,
-		method:        r.Method,
-		hostRxs:       hostRxs,
-		pathRxs:       pathRxs,
-		headersExact:  canonicalizeHeaders(r.Headers),
-		headersRegexp: canonicalizeHeaderRegexps(allHeaderRxs),
-		predicates:    r.Predicates,
-		route:         r}, nil
+		weight:           r.weight,
+		priority:         r.Priority,
+		method:           r.Method,
+		hostRxs:          hostRxs,
+		pathRxs:          pathRxs,
+		headersExact:     headersExact,
+		headersRegexp:    headersRegexp,
+		headerKeys:       sortedKeys(headersExact),
+		headerRegexpKeys: sortedKeys(headersRegexp),
+		predicates:       r.Predicates,
+		route:            r}, nil
 }
 
 func trimTrailingSlash(path string) string {
@@ -257,9 +290,10 @@ func trimTrailingSlash(path string) string {
 }
 
 // add each path matcher to the path tree. If a matcher is a subtree, add it with the
-// additional paths.
-func addTreeMatchers(pathTree *pathmux.Tree, matchers map[string]*pathMatcher) []*definitionError {
+// additional paths. Returns the paths that could not be added.
+func addTreeMatchers(pathTree *pathmux.Tree, matchers map[string]*pathMatcher) ([]*definitionError, map[string]bool) {
 	var errors []*definitionError
+	failed := make(map[string]bool)
 	for p, m := range matchers {
 
 		// sort leaves during construction time, based on their priority
@@ -267,10 +301,11 @@ func addTreeMatchers(pathTree *pathmux.Tree, matchers map[string]*pathMatcher) [
 
 		if err := pathTree.Add(p, m); err != nil {
 			errors = append(errors, &definitionError{Index: -1, Original: err})
+			failed[p] = true
 		}
 	}
 
-	return errors
+	return errors, failed
 }
 
 func addLeafToPath(pms map[string]*pathMatcher, path string, l *leafMatcher) {
@@ -283,19 +318,19 @@ func addLeafToPath(pms map[string]*pathMatcher, path string, l *leafMatcher) {
 	pm.leaves = append(pm.leaves, l)
 }
 
-func addSubtreeLeafsToPath(pms map[string]*pathMatcher, path string, l *leafMatcher, o MatchingOptions) {
+// returns the tree paths of a path subtree route
+func subtreePaths(path string, o MatchingOptions) []string {
 	basePath := freeWildcardRx.ReplaceAllLiteralString(path, "")
 	basePath = strings.TrimSuffix(basePath, "/")
 	if basePath == "" {
-		addLeafToPath(pms, "/", l)
-		addLeafToPath(pms, "/**", l)
-	} else {
-		addLeafToPath(pms, basePath, l)
-		addLeafToPath(pms, basePath+"/**", l)
-		if !o.ignoreTrailingSlash() {
-			addLeafToPath(pms, basePath+"/", l)
-		}
+		return []string{"/", "/**"}
 	}
+
+	if o.ignoreTrailingSlash() {
+		return []string{basePath, basePath + "/**"}
+	}
+
+	return []string{basePath, basePath + "/**", basePath + "/"}
 }
 
 // constructs a matcher based on the provided definitions.
@@ -310,54 +345,110 @@ func addSubtreeLeafsToPath(pms map[string]*pathMatcher, path string, l *leafMatc
 // on the rest of the conditions so that most strict route
 // definition matches first.
 func newMatcher(rs []*Route, o MatchingOptions) (*matcher, []*definitionError) {
+	return updateMatcher(nil, rs, o)
+}
+
+// constructs a matcher based on the provided definitions, reusing the
+// leaf matchers of the routes that were already contained by the
+// previous matcher, and the host trees whose routes didn't change.
+// The previous matcher can be nil.
+func updateMatcher(prev *matcher, rs []*Route, o MatchingOptions) (*matcher, []*definitionError) {
 	var (
 		errors     []*definitionError
 		rootLeaves leafMatchers
+		entries    []*leafEntry
 	)
 
 	pathMatchers := make(map[string]*pathMatcher)
 	compiledRxs := make(map[string]*regexp.Regexp)
+	leaves := make(map[*Route]*leafEntry, len(rs))
 
 	for i, r := range rs {
-		l, err := newLeaf(r, compiledRxs)
-		if err != nil {
-			errors = append(errors, &definitionError{r.Id, i, err})
-			continue
-		}
-
-		path, err := normalizePath(r)
+		e, err := prev.leafEntry(r, compiledRxs, o)
 		if err != nil {
 			errors = append(errors, &definitionError{r.Id, i, err})
 			continue
 		}
 
-		if r.pathSubtree != "" {
-			addSubtreeLeafsToPath(pathMatchers, path, l, o)
+		leaves[r] = e
+		e = &leafEntry{leaf: e.leaf, paths: e.paths, hosts: e.hosts, index: i}
+		entries = append(entries, e)
+		if e.paths == nil {
+			rootLeaves = append(rootLeaves, e.leaf)
 			continue
 		}
 
-		if r.path == "" {
-			rootLeaves = append(rootLeaves, l)
-			continue
+		for _, p := range e.paths {
+			addLeafToPath(pathMatchers, p, e.leaf)
 		}
-
-		if o.ignoreTrailingSlash() {
-			path = trimTrailingSlash(path)
-		}
-		addLeafToPath(pathMatchers, path, l)
 	}
 
 	pathTree := &pathmux.Tree{}
-	errors = append(errors, addTreeMatchers(pathTree, pathMatchers)...)
+	treeErrors, failedPaths := addTreeMatchers(pathTree, pathMatchers)
+	errors = append(errors, treeErrors...)
 
 	// sort root leaves during construction time, based on their priority
 	sort.Stable(rootLeaves)
 
-	return &matcher{pathTree, rootLeaves, o}, errors
+	m := &matcher{
+		paths:             pathTree,
+		rootLeaves:        rootLeaves,
+		matchingOptions:   o,
+		leaves:            leaves,
+		priorityConflicts: findPriorityConflicts(pathMatchers, rootLeaves, entries),
+	}
+
+	m.hosts, m.genericEntries, m.failedPaths = newHostIndex(prev, entries, rootLeaves, failedPaths)
+	return m, errors
+}
+
+// returns the leaf matcher of a route, and the paths where it needs to
+// be added in the path tree. When the route was contained by the
+// previous matcher, its leaf matcher is reused.
+func (m *matcher) leafEntry(r *Route, compiledRxs map[string]*regexp.Regexp, o MatchingOptions) (*leafEntry, error) {
+	if m != nil {
+		if e, ok := m.leaves[r]; ok {
+			return e, nil
+		}
+	}
+
+	l, err := newLeaf(r, compiledRxs)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := normalizePath(r)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &leafEntry{leaf: l, hosts: routeHosts(r)}
+	switch {
+	case r.pathSubtree != "":
+		e.paths = subtreePaths(path, o)
+	case r.path == "":
+	case o.ignoreTrailingSlash():
+		e.paths = []string{trimTrailingSlash(path)}
+	default:
+		e.paths = []string{path}
+	}
+
+	return e, nil
+}
+
+// returns the path tree and the root leaves to be used for a request
+// host. When the host is not found in the index, the generic tree is
+// used, containing all the routes.
+func (m *matcher) lookupTrees(host string) (*pathmux.Tree, leafMatchers) {
+	if hm, ok := m.hosts[normalizeHost(host)]; ok {
+		return hm.paths, hm.rootLeaves
+	}
+
+	return m.paths, m.rootLeaves
 }
 
 // matches a path in the path trie structure.
-func matchPathTree(tree *pathmux.Tree, path string, lrm *leafRequestMatcher) (map[string]string, *leafMatcher) {
+func matchPathTree(tree *pathmux.Tree, path string, lrm pathmux.Matcher) (map[string]string, *leafMatcher) {
 	v, params, value := tree.LookupMatcher(path, lrm)
 	if v == nil {
 		return nil, nil
@@ -386,15 +477,21 @@ func matchPathTree(tree *pathmux.Tree, path string, lrm *leafRequestMatcher) (ma
 	return paramsMap, lm
 }
 
-// matches the path regexp conditions in a leaf matcher.
-func matchRegexps(rxs []*regexp.Regexp, s string) bool {
-	for _, rx := range rxs {
+// returns the index of the first regexp not matching a string, or -1
+// when all of them match.
+func firstMismatch(rxs []*regexp.Regexp, s string) int {
+	for i, rx := range rxs {
 		if !rx.MatchString(s) {
-			return false
+			return i
 		}
 	}
 
-	return true
+	return -1
+}
+
+// matches the path regexp conditions in a leaf matcher.
+func matchRegexps(rxs []*regexp.Regexp, s string) bool {
+	return firstMismatch(rxs, s) < 0
 }
 
 // matches a set of request headers to a fix and regexp header condition
@@ -413,63 +510,76 @@ func matchHeader(h http.Header, key string, check func(string) bool) bool {
 	return false
 }
 
-// matches a set of request headers to the fix and regexp header conditions
-func matchHeaders(exact map[string]string, hrxs map[string][]*regexp.Regexp, h http.Header) bool {
-	for k, v := range exact {
-		if !matchHeader(h, k, func(val string) bool { return val == v }) {
-			return false
-		}
-	}
-
-	for k, rxs := range hrxs {
-		for _, rx := range rxs {
-			if !matchHeader(h, k, rx.MatchString) {
-				return false
-			}
-		}
-	}
-
-	return true
-}
-
-// check if all defined custom predicates are matched
-func matchPredicates(cps []Predicate, req *http.Request) bool {
-	for _, cp := range cps {
-		if !cp.Match(req) {
-			return false
-		}
-	}
+type leafCondition int
+
+const (
+	noCondition leafCondition = iota
+	pathCondition
+	methodCondition
+	hostCondition
+	pathRegexpCondition
+	headerCondition
+	headerRegexpCondition
+	customPredicateCondition
+)
 
-	return true
+// the condition of a leaf matcher that rejected a request. The index
+// points to the regexp or the custom predicate, and header contains the
+// name of the header condition.
+type rejection struct {
+	condition leafCondition
+	header    string
+	index     int
 }
 
-// matches a request to the conditions in a leaf matcher
-func matchLeaf(l *leafMatcher, req *http.Request, path, exactPath string) bool {
+// checks the conditions of a leaf matcher in a fixed order, and returns
+// the first one rejecting the request. The condition is noCondition
+// when the request matches. The header conditions are checked in the
+// order of the header names, to make the rejection deterministic.
+func checkLeaf(l *leafMatcher, req *http.Request, path, exactPath string) rejection {
 	if l.exactPath != "" && l.exactPath != path {
-		return false
+		return rejection{condition: pathCondition}
 	}
 
 	if l.method != "" && l.method != req.Method {
-		return false
+		return rejection{condition: methodCondition}
 	}
 
-	if !matchRegexps(l.hostRxs, req.Host) {
-		return false
+	if i := firstMismatch(l.hostRxs, req.Host); i >= 0 {
+		return rejection{condition: hostCondition, index: i}
 	}
 
-	if !matchRegexps(l.pathRxs, exactPath) {
-		return false
+	if i := firstMismatch(l.pathRxs, exactPath); i >= 0 {
+		return rejection{condition: pathRegexpCondition, index: i}
 	}
 
-	if !matchHeaders(l.headersExact, l.headersRegexp, req.Header) {
-		return false
+	for _, k := range l.headerKeys {
+		v := l.headersExact[k]
+		if !matchHeader(req.Header, k, func(val string) bool { return val == v }) {
+			return rejection{condition: headerCondition, header: k}
+		}
 	}
 
-	if !matchPredicates(l.predicates, req) {
-		return false
+	for _, k := range l.headerRegexpKeys {
+		for i, rx := range l.headersRegexp[k] {
+			if !matchHeader(req.Header, k, rx.MatchString) {
+				return rejection{condition: headerRegexpCondition, header: k, index: i}
+			}
+		}
+	}
+
+	for i, cp := range l.predicates {
+		if !cp.Match(req) {
+			return rejection{condition: customPredicateCondition, index: i}
+		}
 	}
 
-	return true
+	return rejection{}
+}
+
+// matches a request to the conditions in a leaf matcher
+func matchLeaf(l *leafMatcher, req *http.Request, path, exactPath string) bool {
+	return checkLeaf(l, req, path, exactPath).condition == noCondition
 }
 
 // matches a request to a set of leaf matchers
@@ -495,16 +605,17 @@ func (m *matcher) match(r *http.Request) (*Route, map[string]string) {
 		path = trimTrailingSlash(path)
 	}
 	lrm := &leafRequestMatcher{r: r, path: path, exactPath: exact}
+	paths, rootLeaves := m.lookupTrees(r.Host)
 
 	// first match fixed and wildcard paths
-	params, l := matchPathTree(m.paths, path, lrm)
+	params, l := matchPathTree(paths, path, lrm)
 
 	if l != nil {
 		return l.route, params
 	}
 
 	// if no path match, match root leaves for other conditions
-	l = matchLeaves(m.rootLeaves, r, path, exact)
+	l = matchLeaves(rootLeaves, r, path, exact)
 	if l != nil {
 		return l.route, nil
 	}
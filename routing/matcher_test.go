@@ -101,7 +101,7 @@ func docToRoutes(doc string) ([]*Route, error) {
 	if err != nil {
 		return nil, err
 	}
-	routes, _ := processRouteDefs(Options{Predicates: []PredicateSpec{&truePredicate{}}}, nil, defs)
+	routes, _, _ := processRouteDefs(Options{Predicates: []PredicateSpec{&truePredicate{}}}, nil, defs)
 	return routes, nil
 }
 
@@ -193,7 +193,7 @@ func generateRoutes(paths []string) []*Route {
 		defs[i] = &eskip.Route{Id: fmt.Sprintf("route%d", i), Path: p, Backend: p}
 	}
 
-	routes, _ := processRouteDefs(Options{}, nil, defs)
+	routes, _, _ := processRouteDefs(Options{}, nil, defs)
 	return routes
 }
 
@@ -448,11 +448,20 @@ func TestFindHeaderTrue(t *testing.T) {
 	}
 }
 
+func headerLeaf(exact map[string]string, hrxs map[string][]*regexp.Regexp) *leafMatcher {
+	return &leafMatcher{
+		headersExact:     exact,
+		headersRegexp:    hrxs,
+		headerKeys:       sortedKeys(exact),
+		headerRegexpKeys: sortedKeys(hrxs),
+	}
+}
+
 func TestMatchHeadersExactFalse(t *testing.T) {
 	h := make(http.Header)
 	h["Some-Header"] = []string{"some-value"}
 	h["Some-Other-Header"] = []string{"some-other-value-0", "some-other-value-1"}
-	if matchHeaders(map[string]string{"Some-Header": "some-wrong-value"}, nil, h) {
+	if matchLeaf(headerLeaf(map[string]string{"Some-Header": "some-wrong-value"}, nil), &http.Request{Header: h}, "", "") {
 		t.Error("failed not to match header")
 	}
 }
@@ -461,7 +470,7 @@ func TestMatchHeadersExactTrue(t *testing.T) {
 	h := make(http.Header)
 	h["Some-Header"] = []string{"some-value"}
 	h["Some-Other-Header"] = []string{"some-other-value-0", "some-other-value-1"}
-	if !matchHeaders(map[string]string{"Some-Header": "some-value"}, nil, h) {
+	if !matchLeaf(headerLeaf(map[string]string{"Some-Header": "some-value"}, nil), &http.Request{Header: h}, "", "") {
 		t.Error("failed to match header")
 	}
 }
@@ -471,7 +480,7 @@ func TestMatchHeadersRegexpFalse(t *testing.T) {
 	h := make(http.Header)
 	h["Some-Header"] = []string{"some-value"}
 	h["Some-Other-Header"] = []string{"some-other-value-0", "some-other-value-1"}
-	if matchHeaders(nil, map[string][]*regexp.Regexp{"Some-Header": {rx}}, h) {
+	if matchLeaf(headerLeaf(nil, map[string][]*regexp.Regexp{"Some-Header": {rx}}), &http.Request{Header: h}, "", "") {
 		t.Error("failed not to match header")
 	}
 }
@@ -481,7 +490,7 @@ func TestMatchHeadersRegexpTrue(t *testing.T) {
 	h := make(http.Header)
 	h["Some-Header"] = []string{"some-value"}
 	h["Some-Other-Header"] = []string{"some-other-value-0", "some-other-value-1"}
-	if !matchHeaders(nil, map[string][]*regexp.Regexp{"Some-Header": {rx}}, h) {
+	if !matchLeaf(headerLeaf(nil, map[string][]*regexp.Regexp{"Some-Header": {rx}}), &http.Request{Header: h}, "", "") {
 		t.Error("failed not to match header")
 	}
 }
@@ -497,11 +506,13 @@ func TestMatchLeafWrongMethod(t *testing.T) {
 			"Some-Header":       []string{"some-value"},
 			"Some-Other-Header": []string{"some-other-value"}}}
 	l := &leafMatcher{
-		method:        "PUT",
-		hostRxs:       []*regexp.Regexp{rxh},
-		pathRxs:       []*regexp.Regexp{rxp},
-		headersExact:  map[string]string{"Some-Header": "some-value"},
-		headersRegexp: map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}}}
+		method:           "PUT",
+		hostRxs:          []*regexp.Regexp{rxh},
+		pathRxs:          []*regexp.Regexp{rxp},
+		headersExact:     map[string]string{"Some-Header": "some-value"},
+		headersRegexp:    map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}},
+		headerKeys:       []string{"Some-Header"},
+		headerRegexpKeys: []string{"Some-Other-Header"}}
 	if matchLeaf(l, req, "/some/path", "/some/path") {
 		t.Error("failed not to match leaf method")
 	}
@@ -518,11 +529,13 @@ func TestMatchLeafWrongHost(t *testing.T) {
 			"Some-Header":       []string{"some-value"},
 			"Some-Other-Header": []string{"some-other-value"}}}
 	l := &leafMatcher{
-		method:        "PUT",
-		hostRxs:       []*regexp.Regexp{rxh},
-		pathRxs:       []*regexp.Regexp{rxp},
-		headersExact:  map[string]string{"Some-Header": "some-value"},
-		headersRegexp: map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}}}
+		method:           "PUT",
+		hostRxs:          []*regexp.Regexp{rxh},
+		pathRxs:          []*regexp.Regexp{rxp},
+		headersExact:     map[string]string{"Some-Header": "some-value"},
+		headersRegexp:    map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}},
+		headerKeys:       []string{"Some-Header"},
+		headerRegexpKeys: []string{"Some-Other-Header"}}
 	if matchLeaf(l, req, "/some/path", "/some/path") {
 		t.Error("failed not to match leaf host")
 	}
@@ -540,11 +553,13 @@ func TestMatchLeafWrongPath(t *testing.T) {
 			"Some-Header":       []string{"some-value"},
 			"Some-Other-Header": []string{"some-other-value"}}}
 	l := &leafMatcher{
-		method:        "PUT",
-		hostRxs:       []*regexp.Regexp{rxh},
-		pathRxs:       []*regexp.Regexp{rxp},
-		headersExact:  map[string]string{"Some-Header": "some-value"},
-		headersRegexp: map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}}}
+		method:           "PUT",
+		hostRxs:          []*regexp.Regexp{rxh},
+		pathRxs:          []*regexp.Regexp{rxp},
+		headersExact:     map[string]string{"Some-Header": "some-value"},
+		headersRegexp:    map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}},
+		headerKeys:       []string{"Some-Header"},
+		headerRegexpKeys: []string{"Some-Other-Header"}}
 	if matchLeaf(l, req, "/some/other/path", "/some/other/path") {
 		t.Error("failed not to match leaf path")
 	}
@@ -562,11 +577,13 @@ func TestMatchLeafWrongExactHeader(t *testing.T) {
 			"Some-Header":       []string{"some-wrong-value"},
 			"Some-Other-Header": []string{"some-other-value"}}}
 	l := &leafMatcher{
-		method:        "PUT",
-		hostRxs:       []*regexp.Regexp{rxh},
-		pathRxs:       []*regexp.Regexp{rxp},
-		headersExact:  map[string]string{"Some-Header": "some-value"},
-		headersRegexp: map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}}}
+		method:           "PUT",
+		hostRxs:          []*regexp.Regexp{rxh},
+		pathRxs:          []*regexp.Regexp{rxp},
+		headersExact:     map[string]string{"Some-Header": "some-value"},
+		headersRegexp:    map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}},
+		headerKeys:       []string{"Some-Header"},
+		headerRegexpKeys: []string{"Some-Other-Header"}}
 	if matchLeaf(l, req, "/some/path", "/some/path") {
 		t.Error("failed not to match leaf exact header")
 	}
@@ -584,11 +601,13 @@ func TestMatchLeafWrongRegexpHeader(t *testing.T) {
 			"Some-Header":       []string{"some-value"},
 			"Some-Other-Header": []string{"some-other-wrong-value"}}}
 	l := &leafMatcher{
-		method:        "PUT",
-		hostRxs:       []*regexp.Regexp{rxh},
-		pathRxs:       []*regexp.Regexp{rxp},
-		headersExact:  map[string]string{"Some-Header": "some-value"},
-		headersRegexp: map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}}}
+		method:           "PUT",
+		hostRxs:          []*regexp.Regexp{rxh},
+		pathRxs:          []*regexp.Regexp{rxp},
+		headersExact:     map[string]string{"Some-Header": "some-value"},
+		headersRegexp:    map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}},
+		headerKeys:       []string{"Some-Header"},
+		headerRegexpKeys: []string{"Some-Other-Header"}}
 	if matchLeaf(l, req, "/some/path", "/some/path") {
 		t.Error("failed not to match leaf regexp header")
 	}
@@ -606,11 +625,13 @@ func TestMatchLeaf(t *testing.T) {
 			"Some-Header":       []string{"some-value"},
 			"Some-Other-Header": []string{"some-other-value"}}}
 	l := &leafMatcher{
-		method:        "PUT",
-		hostRxs:       []*regexp.Regexp{rxh},
-		pathRxs:       []*regexp.Regexp{rxp},
-		headersExact:  map[string]string{"Some-Header": "some-value"},
-		headersRegexp: map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}}}
+		method:           "PUT",
+		hostRxs:          []*regexp.Regexp{rxh},
+		pathRxs:          []*regexp.Regexp{rxp},
+		headersExact:     map[string]string{"Some-Header": "some-value"},
+		headersRegexp:    map[string][]*regexp.Regexp{"Some-Other-Header": {rxhd}},
+		headerKeys:       []string{"Some-Header"},
+		headerRegexpKeys: []string{"Some-Other-Header"}}
 	if !matchLeaf(l, req, "/some/path", "/some/path") {
 		t.Error("failed to match leaf")
 	}
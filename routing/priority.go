@@ -0,0 +1,114 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// priorityConflict is found when multiple routes with the same explicit
+// priority and the same weight are in the same position of the path
+// tree, and they can match the same hosts. In this case, it is
+// unspecified which of the routes is matched.
+type priorityConflict struct {
+	path     string
+	priority int
+	routes   []string
+}
+
+func (c priorityConflict) String() string {
+	path := c.path
+	if path == "" {
+		path = "<root>"
+	}
+
+	return fmt.Sprintf(
+		"routes with identical priority %d overlap at path %s, their order is unspecified: %s",
+		c.priority, path, strings.Join(c.routes, ", "),
+	)
+}
+
+func hostsOverlap(left, right []string) bool {
+	if left == nil || right == nil {
+		return true
+	}
+
+	for _, l := range left {
+		for _, r := range right {
+			if l == r {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// finds the conflicts in a list of leaves sorted by priority and weight
+func leafPriorityConflicts(path string, leaves leafMatchers, hosts map[*leafMatcher][]string) []priorityConflict {
+	var conflicts []priorityConflict
+	for i := 0; i < len(leaves); {
+		j := i + 1
+		for j < len(leaves) && !leaves.Less(i, j) && !leaves.Less(j, i) {
+			j++
+		}
+
+		group := leaves[i:j]
+		i = j
+		if group[0].priority == 0 || len(group) < 2 {
+			continue
+		}
+
+		var ids []string
+		for k, l := range group {
+			for m, other := range group {
+				if k != m && hostsOverlap(hosts[l], hosts[other]) {
+					ids = append(ids, l.route.Id)
+					break
+				}
+			}
+		}
+
+		if len(ids) > 0 {
+			sort.Strings(ids)
+			conflicts = append(conflicts, priorityConflict{path: path, priority: group[0].priority, routes: ids})
+		}
+	}
+
+	return conflicts
+}
+
+// finds the routes with explicit priority, whose order is unspecified.
+// The leaves need to be sorted.
+func findPriorityConflicts(pathMatchers map[string]*pathMatcher, rootLeaves leafMatchers, entries []*leafEntry) []priorityConflict {
+	hosts := make(map[*leafMatcher][]string)
+	for _, e := range entries {
+		if e.leaf.priority != 0 {
+			hosts[e.leaf] = e.hosts
+		}
+	}
+
+	if len(hosts) < 2 {
+		return nil
+	}
+
+	conflicts := leafPriorityConflicts("", rootLeaves, hosts)
+	for p, pm := range pathMatchers {
+		conflicts = append(conflicts, leafPriorityConflicts(p, pm.leaves, hosts)...)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].path < conflicts[j].path })
+
+	// the path subtree routes are added to multiple paths
+	var unique []priorityConflict
+	found := make(map[string]bool)
+	for _, c := range conflicts {
+		key := fmt.Sprint(c.priority, c.routes)
+		if !found[key] {
+			found[key] = true
+			unique = append(unique, c)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,118 @@
+package routing
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPriorityTakesPrecedenceOverWeight(t *testing.T) {
+	m, err := docToMatcher(`
+		specific: Path("/foo") && Method("GET") && Header("X-Foo", "bar") -> "https://specific.example.org";
+		generic [priority=10]: Path("/foo") -> "https://generic.example.org";
+		low [priority=-1]: Path("/foo") && Method("GET") && Header("X-Foo", "bar") && Header("X-Bar", "baz") -> "https://low.example.org";
+		rootSpecific: Method("GET") -> "https://root-specific.example.org";
+		rootGeneric [priority=1]: * -> "https://root-generic.example.org";
+		subtree [priority=5]: PathSubtree("/bar") -> "https://subtree.example.org";
+		subtreeSpecific: PathSubtree("/bar") && Method("GET") -> "https://subtree-specific.example.org";
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, backend := range map[string]string{
+		"/foo":     "https://generic.example.org",
+		"/baz":     "https://root-generic.example.org",
+		"/bar/qux": "https://subtree.example.org",
+	} {
+		r := &http.Request{
+			Method: "GET",
+			URL:    &url.URL{Path: path},
+			Header: http.Header{"X-Foo": []string{"bar"}, "X-Bar": []string{"baz"}},
+		}
+
+		if route, _ := m.match(r); route == nil || route.Backend != backend {
+			t.Errorf("invalid route matched for %s, expected: %s, got: %v", path, backend, route)
+		}
+	}
+}
+
+func TestPriorityConflicts(t *testing.T) {
+	for _, test := range []struct {
+		title     string
+		doc       string
+		conflicts []priorityConflict
+	}{{
+		title: "no priority",
+		doc: `
+			a: Path("/foo") -> <shunt>;
+			b: Path("/foo") -> <shunt>;
+		`,
+	}, {
+		title: "different priority",
+		doc: `
+			a [priority=1]: Path("/foo") -> <shunt>;
+			b [priority=2]: Path("/foo") -> <shunt>;
+		`,
+	}, {
+		title: "same priority, different weight",
+		doc: `
+			a [priority=1]: Path("/foo") && Method("GET") -> <shunt>;
+			b [priority=1]: Path("/foo") -> <shunt>;
+		`,
+	}, {
+		title: "same priority, different path",
+		doc: `
+			a [priority=1]: Path("/foo") -> <shunt>;
+			b [priority=1]: Path("/bar") -> <shunt>;
+		`,
+	}, {
+		title: "same priority, different exact hosts",
+		doc: `
+			a [priority=1]: Host("^a[.]example[.]org$") && Path("/foo") -> <shunt>;
+			b [priority=1]: Host("^b[.]example[.]org$") && Path("/foo") -> <shunt>;
+		`,
+	}, {
+		title: "same priority, same path",
+		doc: `
+			a [priority=1]: Path("/foo") -> <shunt>;
+			b [priority=1]: Path("/foo") -> <shunt>;
+			c [priority=1]: Host("^c[.]example[.]org$") && Path("/foo") -> <shunt>;
+			d [priority=1]: Host("^d[.]example[.]org$") && Path("/foo") -> <shunt>;
+		`,
+		conflicts: []priorityConflict{{path: "/foo", priority: 1, routes: []string{"a", "b"}}},
+	}, {
+		title: "same priority, overlapping hosts",
+		doc: `
+			a [priority=1]: Host("^a[.]example[.]org$") && Path("/foo") -> <shunt>;
+			b [priority=1]: Host("[.]example[.]org$") && Path("/foo") -> <shunt>;
+		`,
+		conflicts: []priorityConflict{{path: "/foo", priority: 1, routes: []string{"a", "b"}}},
+	}, {
+		title: "same priority, root",
+		doc: `
+			a [priority=1]: * -> <shunt>;
+			b [priority=1]: * -> <shunt>;
+		`,
+		conflicts: []priorityConflict{{priority: 1, routes: []string{"a", "b"}}},
+	}, {
+		title: "same priority, path subtree reported once",
+		doc: `
+			a [priority=1]: PathSubtree("/foo") -> <shunt>;
+			b [priority=1]: PathSubtree("/foo") -> <shunt>;
+		`,
+		conflicts: []priorityConflict{{path: "/foo", priority: 1, routes: []string{"a", "b"}}},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			m, err := docToMatcher(test.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(m.priorityConflicts, test.conflicts) {
+				t.Errorf("invalid conflicts, expected: %v, got: %v", test.conflicts, m.priorityConflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package routing
+
+import (
+	"reflect"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/logging"
+)
+
+type cachedRoute struct {
+	def       *eskip.Route
+	name      string
+	namespace string
+	route     *Route
+}
+
+// routeCache stores the processed routes of the last update by route
+// id, together with the definitions that they were created from. When
+// a definition doesn't change, its route is reused in the next update,
+// preserving the filter and predicate instances. The cached routes are
+// the ones before the post-processing, and the post-processors receive
+// only their copies, see postProcess().
+type routeCache struct {
+	routes map[string]cachedRoute
+}
+
+func newRouteCache(size int) *routeCache {
+	return &routeCache{routes: make(map[string]cachedRoute, size)}
+}
+
+// checks whether the arguments can be compared by eskip.Eq, which
+// compares them with the == operator
+func comparableArgs(args []interface{}) bool {
+	for _, a := range args {
+		if a != nil && !reflect.TypeOf(a).Comparable() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func comparableDef(def *eskip.Route) bool {
+	for _, p := range def.Predicates {
+		if !comparableArgs(p.Args) {
+			return false
+		}
+	}
+
+	for _, f := range def.Filters {
+		if !comparableArgs(f.Args) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// eskip.Eq ignores the metadata, but the routes are served with it
+func eqMetadata(left, right map[string]string) bool {
+	if len(left) != len(right) {
+		return false
+	}
+
+	for k, v := range left {
+		if rv, ok := right[k]; !ok || rv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// get returns the route of the previous update, if its definition
+// didn't change.
+func (c *routeCache) get(def *eskip.Route) (*Route, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	cr, ok := c.routes[def.Id]
+	if !ok {
+		return nil, false
+	}
+
+	if cr.name != def.Name || cr.namespace != def.Namespace ||
+		!eqMetadata(cr.def.Metadata, def.Metadata) ||
+		!comparableDef(def) || !eskip.Eq(cr.def, def) {
+		return nil, false
+	}
+
+	return cr.route, true
+}
+
+// set stores a processed route. The definition is copied, to detect
+// the changes made in place, too.
+func (c *routeCache) set(def *eskip.Route, r *Route) {
+	if !comparableDef(def) {
+		return
+	}
+
+	c.routes[def.Id] = cachedRoute{
+		def:       eskip.Copy(def),
+		name:      def.Name,
+		namespace: def.Namespace,
+		route:     r,
+	}
+}
+
+// checks whether the fields of two values of the same type are the
+// same, comparing the slices, the maps, the pointers and the interface
+// values by their identity
+func sameValue(left, right reflect.Value) bool {
+	switch left.Kind() {
+	case reflect.Struct:
+		for i := 0; i < left.NumField(); i++ {
+			if !sameValue(left.Field(i), right.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Array:
+		for i := 0; i < left.Len(); i++ {
+			if !sameValue(left.Index(i), right.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Slice:
+		return left.Len() == right.Len() && left.Pointer() == right.Pointer()
+	case reflect.Map, reflect.Ptr, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return left.Pointer() == right.Pointer()
+	case reflect.Interface:
+		if left.IsNil() || right.IsNil() {
+			return left.IsNil() == right.IsNil()
+		}
+
+		return left.Elem().Type() == right.Elem().Type() && sameValue(left.Elem(), right.Elem())
+	case reflect.String:
+		return left.String() == right.String()
+	case reflect.Bool:
+		return left.Bool() == right.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return left.Int() == right.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return left.Uint() == right.Uint()
+	case reflect.Float32, reflect.Float64:
+		return left.Float() == right.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return left.Complex() == right.Complex()
+	default:
+		return false
+	}
+}
+
+// postProcess executes the post-processors on the copies of the
+// routes, so that the routes of the current routing table, that are
+// reused from the cache, are never modified. The copies that the
+// post-processors didn't modify are replaced by the original routes,
+// allowing the matcher to reuse their leaves and host trees. The routes
+// modified by the post-processors, e.g. the load balanced routes, are
+// created again on every update.
+func postProcess(pp []PostProcessor, routes []*Route) []*Route {
+	if len(pp) == 0 {
+		return routes
+	}
+
+	originals := make(map[*Route]*Route, len(routes))
+	copies := make([]*Route, len(routes))
+	for i, r := range routes {
+		c := *r
+		copies[i] = &c
+		originals[&c] = r
+	}
+
+	for _, p := range pp {
+		copies = p.Do(copies)
+	}
+
+	for i, c := range copies {
+		if o, ok := originals[c]; ok && sameValue(reflect.ValueOf(c).Elem(), reflect.ValueOf(o).Elem()) {
+			copies[i] = o
+		}
+	}
+
+	return copies
+}
+
+// logs the warnings that were not logged for the previous routing
+// table, and returns the current ones
+func logNewWarnings(l logging.Logger, previous map[string]bool, warnings []string) map[string]bool {
+	current := make(map[string]bool, len(warnings))
+	for _, w := range warnings {
+		if !previous[w] && !current[w] {
+			l.Warn(w)
+		}
+
+		current[w] = true
+	}
+
+	return current
+}
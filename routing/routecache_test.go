@@ -0,0 +1,255 @@
+package routing
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/logging/loggingtest"
+)
+
+type cacheTestFilter struct{ args []interface{} }
+
+func (*cacheTestFilter) Name() string                   { return "cacheTest" }
+func (*cacheTestFilter) Request(filters.FilterContext)  {}
+func (*cacheTestFilter) Response(filters.FilterContext) {}
+
+func (*cacheTestFilter) CreateFilter(args []interface{}) (filters.Filter, error) {
+	return &cacheTestFilter{args: args}, nil
+}
+
+func cacheTestRegistry() filters.Registry {
+	fr := make(filters.Registry)
+	fr.Register(&cacheTestFilter{})
+	return fr
+}
+
+func processTestDefs(t *testing.T, doc string, cache *routeCache) (map[string]*Route, *routeCache) {
+	defs, err := eskip.Parse(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := Options{Log: loggingtest.New()}
+	routes, _, _, next := processChangedRouteDefs(o, cacheTestRegistry(), defs, cache)
+	byID := make(map[string]*Route)
+	for _, r := range routes {
+		byID[r.Id] = r
+	}
+
+	return byID, next
+}
+
+func TestRouteCacheReusesUnchangedRoutes(t *testing.T) {
+	first, cache := processTestDefs(t, `
+		unchanged: Path("/foo") -> cacheTest("/bar") -> "https://foo.example.org";
+		changed: Path("/baz") -> cacheTest("/qux") -> "https://baz.example.org";
+		renamed: Path("/renamed") -> <shunt>;
+		annotated [owner="team-a"]: Path("/annotated") -> <shunt>;
+		deleted: * -> <shunt>;
+	`, nil)
+
+	second, cache := processTestDefs(t, `
+		unchanged: Path("/foo") -> cacheTest("/bar") -> "https://foo.example.org";
+		changed: Path("/baz") -> cacheTest("/quux") -> "https://baz.example.org";
+		renamed2: Path("/renamed") -> <shunt>;
+		annotated [owner="team-b"]: Path("/annotated") -> <shunt>;
+	`, cache)
+
+	if second["unchanged"] != first["unchanged"] {
+		t.Error("failed to reuse the unchanged route")
+	}
+
+	if second["unchanged"].Filters[0].Filter != first["unchanged"].Filters[0].Filter {
+		t.Error("failed to reuse the filter instance")
+	}
+
+	if second["changed"] == first["changed"] {
+		t.Error("unexpected reuse of the changed route")
+	}
+
+	if second["renamed2"] == nil || second["renamed2"] == first["renamed"] {
+		t.Error("unexpected reuse of the route with a different id")
+	}
+
+	if second["annotated"] == first["annotated"] || second["annotated"].Metadata["owner"] != "team-b" {
+		t.Error("unexpected reuse of the route with changed metadata")
+	}
+
+	if _, ok := cache.routes["deleted"]; ok {
+		t.Error("failed to remove the deleted route from the cache")
+	}
+
+	// a route deleted and added again is created again
+	third, _ := processTestDefs(t, `deleted: * -> <shunt>`, cache)
+	if third["deleted"] == first["deleted"] {
+		t.Error("unexpected reuse of the deleted route")
+	}
+}
+
+func TestRouteCacheDetectsChangesInPlace(t *testing.T) {
+	defs, err := eskip.Parse(`foo: Path("/foo") -> cacheTest("/bar") -> <shunt>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := Options{Log: loggingtest.New()}
+	first, _, _, cache := processChangedRouteDefs(o, cacheTestRegistry(), defs, nil)
+	defs[0].Filters[0].Args[0] = "/baz"
+	second, _, _, _ := processChangedRouteDefs(o, cacheTestRegistry(), defs, cache)
+	if second[0] == first[0] {
+		t.Error("unexpected reuse of the route changed in place")
+	}
+}
+
+func TestRouteCacheUncomparableArgs(t *testing.T) {
+	defs := []*eskip.Route{{
+		Id:          "foo",
+		Filters:     []*eskip.Filter{{Name: "cacheTest", Args: []interface{}{200.0}}},
+		Predicates:  []*eskip.Predicate{{Name: "True", Args: []interface{}{[]interface{}{"foo"}}}},
+		BackendType: eskip.ShuntBackend,
+	}}
+
+	o := Options{Log: loggingtest.New(), Predicates: []PredicateSpec{&truePredicate{}}}
+	first, _, _, cache := processChangedRouteDefs(o, cacheTestRegistry(), defs, nil)
+	second, _, _, _ := processChangedRouteDefs(o, cacheTestRegistry(), defs, cache)
+	if len(first) != 1 || len(second) != 1 || second[0] == first[0] {
+		t.Error("unexpected reuse of the route with uncomparable args")
+	}
+}
+
+func TestUpdateMatcherReusesUnchangedHosts(t *testing.T) {
+	const hostCount = 6
+	routes, cache := processTestDefs(t, hostIndexRoutes(hostCount), nil)
+	sorted := func(byID map[string]*Route) []*Route {
+		defs, err := eskip.Parse(hostIndexRoutes(hostCount))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var rs []*Route
+		for _, d := range defs {
+			if r, ok := byID[d.Id]; ok {
+				rs = append(rs, r)
+			}
+		}
+
+		return rs
+	}
+
+	m1, errs := newMatcher(sorted(routes), MatchingOptionsNone)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	// change a route of host1 only
+	doc := hostIndexRoutes(hostCount)
+	routes, _ = processTestDefs(t, doc+`
+		host1_item: Host("^host1[.]example[.]org$") && Path("/items/:id") -> "https://host1.item.changed";
+	`, cache)
+
+	m2, errs := updateMatcher(m1, sorted(routes), MatchingOptionsNone)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	if m2.hosts["host0.example.org"] != m1.hosts["host0.example.org"] {
+		t.Error("failed to reuse the unchanged host")
+	}
+
+	if m2.hosts["host1.example.org"] == m1.hosts["host1.example.org"] {
+		t.Error("unexpected reuse of the changed host")
+	}
+
+	if m2.leaves[routes["host0_item"]] != m1.leaves[routes["host0_item"]] {
+		t.Error("failed to reuse the leaf of the unchanged route")
+	}
+
+	r := &http.Request{Method: "GET", Host: "host1.example.org", URL: &url.URL{Path: "/items/42"}, Header: http.Header{}}
+	if route, _ := m2.match(r); route == nil || route.Backend != "https://host1.item.changed" {
+		t.Errorf("failed to match the changed route: %v", routeID(route))
+	}
+
+	// changing a generic route invalidates every host
+	routes, _ = processTestDefs(t, doc+`generic_health: Path("/healthz") -> "https://generic.health.changed";`, cache)
+	m3, errs := updateMatcher(m2, sorted(routes), MatchingOptionsNone)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	for h, hm := range m3.hosts {
+		if hm == m2.hosts[h] {
+			t.Errorf("unexpected reuse of host %s", h)
+		}
+	}
+
+	r = &http.Request{Method: "GET", Host: "host0.example.org", URL: &url.URL{Path: "/healthz"}, Header: http.Header{}}
+	if route, _ := m3.match(r); route == nil || route.Backend != "https://generic.health.changed" {
+		t.Errorf("failed to match the changed generic route: %v", routeID(route))
+	}
+}
+
+type setBackendPostProcessor struct{ id string }
+
+func (p setBackendPostProcessor) Do(routes []*Route) []*Route {
+	var rr []*Route
+	for _, r := range routes {
+		switch r.Id {
+		case p.id:
+			r.Host = "modified.example.org"
+		case "dropped":
+			continue
+		}
+
+		rr = append(rr, r)
+	}
+
+	return rr
+}
+
+func TestPostProcessCopies(t *testing.T) {
+	routes, _ := processTestDefs(t, `
+		unmodified: Path("/foo") -> cacheTest("/bar") -> "https://foo.example.org";
+		modified: Path("/baz") -> "https://baz.example.org";
+		dropped: * -> <shunt>;
+	`, nil)
+
+	processed := postProcess(
+		[]PostProcessor{setBackendPostProcessor{id: "modified"}},
+		[]*Route{routes["unmodified"], routes["modified"], routes["dropped"]},
+	)
+
+	if len(processed) != 2 {
+		t.Fatalf("unexpected post-processed routes: %d", len(processed))
+	}
+
+	if processed[0] != routes["unmodified"] {
+		t.Error("failed to keep the route that the post-processors didn't modify")
+	}
+
+	if processed[1] == routes["modified"] || processed[1].Host != "modified.example.org" {
+		t.Error("failed to use the copy modified by the post-processors")
+	}
+
+	if routes["modified"].Host != "baz.example.org" {
+		t.Error("unexpected modification of the original route")
+	}
+}
+
+func TestLogNewWarnings(t *testing.T) {
+	l := loggingtest.New()
+	defer l.Close()
+
+	logged := logNewWarnings(l, nil, []string{"foo", "bar"})
+	logged = logNewWarnings(l, logged, []string{"foo", "bar", "baz"})
+	logged = logNewWarnings(l, logged, []string{"baz"})
+	logNewWarnings(l, logged, []string{"foo"})
+
+	for w, n := range map[string]int{"foo": 2, "bar": 1, "baz": 1} {
+		if c := l.Count(w); c != n {
+			t.Errorf("unexpected number of warnings for %s, expected: %d, got: %d", w, n, c)
+		}
+	}
+}
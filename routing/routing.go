@@ -12,6 +12,7 @@ import (
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/logging"
+	"github.com/zalando/skipper/metrics"
 	"github.com/zalando/skipper/predicates"
 )
 
@@ -125,6 +126,21 @@ type Options struct {
 	// SignalFirstLoad enables signaling on the first load
 	// of the routing configuration during the startup.
 	SignalFirstLoad bool
+
+	// HistorySize sets the number of the route table generations,
+	// whose changes are kept. When zero, DefaultHistorySize is used,
+	// and when negative, no history is kept.
+	HistorySize int
+
+	// LintRoutes enables the static analysis of the routes on startup
+	// and on every update. The unreachable routes, the routes with
+	// duplicate conditions and the routes whose order is unspecified are
+	// logged as warnings. See Lint.
+	LintRoutes bool
+
+	// Metrics is used to report the number of the invalid routes per
+	// data client. Optional.
+	Metrics metrics.Metrics
 }
 
 // RouteFilter contains extensions to generic filter
@@ -243,6 +259,12 @@ type Route struct {
 // to the routes after they were created from their data representation and
 // before they were passed to the proxy.
 //
+// The post-processors receive every route of the routing table on every
+// update, but the routes whose definition didn't change are passed in as
+// copies of the previous ones, sharing the filter and predicate instances.
+// The copies that the post-processors don't modify are replaced by the
+// routes of the previous routing table.
+//
 // This feature is experimental.
 type PostProcessor interface {
 	Do([]*Route) []*Route
@@ -264,6 +286,9 @@ type Routing struct {
 	firstLoad         chan struct{}
 	firstLoadSignaled bool
 	quit              chan struct{}
+	history           *history
+	dataClients       []DataClient
+	metrics           metrics.Metrics
 }
 
 // New initializes a routing instance, and starts listening for route
@@ -273,7 +298,14 @@ func New(o Options) *Routing {
 		o.Log = &logging.DefaultLog{}
 	}
 
-	r := &Routing{log: o.Log, firstLoad: make(chan struct{}), quit: make(chan struct{})}
+	r := &Routing{
+		log:         o.Log,
+		firstLoad:   make(chan struct{}),
+		quit:        make(chan struct{}),
+		history:     newHistory(o.HistorySize),
+		dataClients: o.DataClients,
+		metrics:     o.Metrics,
+	}
 	if !o.SignalFirstLoad {
 		close(r.firstLoad)
 		r.firstLoadSignaled = true
@@ -359,6 +391,8 @@ func (r *Routing) startReceivingUpdates(o Options) {
 			select {
 			case rt := <-c:
 				r.routeTable.Store(rt)
+				r.history.add(rt.generation)
+				r.reportInvalid(rt.generation)
 				if !r.firstLoadSignaled {
 					dc--
 					if dc == 0 {
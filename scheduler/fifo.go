@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zalando/skipper/metrics"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	// Key used during routing to pass fifo values from the filters to the proxy.
+	FIFOKey = "fifo"
+)
+
+var (
+	// ErrQueueFull is returned by FifoQueue.Wait when the queue has
+	// reached its maximum size.
+	ErrQueueFull = errors.New("queue full")
+
+	// ErrQueueTimeout is returned by FifoQueue.Wait when the request
+	// could not be scheduled within the configured timeout.
+	ErrQueueTimeout = errors.New("queue timeout")
+
+	// ErrClientCanceled is returned by FifoQueue.Wait when the context
+	// of the request was canceled while waiting in the queue.
+	ErrClientCanceled = errors.New("client canceled")
+
+	// ErrQueueClosed is returned by FifoQueue.Wait when the queue was
+	// already closed.
+	ErrQueueClosed = errors.New("queue closed")
+)
+
+// FifoQueue objects implement a FIFO queue for handling requests, with a maximum allowed
+// concurrency and queue size. Currently, they can be used from the fifo and fifoGroup
+// filters in the filters/scheduler package only.
+type FifoQueue struct {
+	mu       sync.RWMutex
+	config   Config
+	sem      *semaphore.Weighted
+	closed   bool
+	active   int64
+	inflight int64
+
+	metrics                  metrics.Metrics
+	activeRequestsMetricsKey string
+	errorFullMetricsKey      string
+	errorOtherMetricsKey     string
+	errorTimeoutMetricsKey   string
+	queuedRequestsMetricsKey string
+}
+
+// FIFOFilter is the interface that needs to be implemented by the filters that
+// use a FIFO queue maintained by the registry.
+type FIFOFilter interface {
+
+	// SetQueue will be used by the registry to pass in the right queue to
+	// the filter.
+	SetQueue(*FifoQueue)
+
+	// GetQueue is currently used only by tests.
+	GetQueue() *FifoQueue
+
+	// Config will be called by the registry once during processing the
+	// routing to get the right queue settings from the filter.
+	Config() Config
+}
+
+// GroupedFIFOFilter is an extension of the FIFOFilter interface for filters
+// that use a shared queue.
+type GroupedFIFOFilter interface {
+	FIFOFilter
+
+	// Group returns the name of the group.
+	Group() string
+
+	// HasConfig indicates that the current filter provides the queue
+	// queue settings for the group.
+	HasConfig() bool
+}
+
+func newFifoQueue(c Config) *FifoQueue {
+	return &FifoQueue{
+		config: c,
+		sem:    newSemaphore(c),
+	}
+}
+
+func newSemaphore(c Config) *semaphore.Weighted {
+	if c.MaxConcurrency < 1 {
+		return semaphore.NewWeighted(1)
+	}
+
+	return semaphore.NewWeighted(int64(c.MaxConcurrency))
+}
+
+// Wait blocks until a request can be processed or needs to be rejected.
+// Requests are scheduled in the order of their arrival. When it can be
+// processed, calling done indicates that it has finished. It is mandatory
+// to call done() when the request was processed. When the request needs to
+// be rejected, an error will be returned. Canceling ctx removes the request
+// from the queue.
+func (q *FifoQueue) Wait(ctx context.Context) (done func(), err error) {
+	done, err = q.wait(ctx)
+	if q.metrics != nil && err != nil {
+		switch err {
+		case ErrQueueFull:
+			q.metrics.IncCounter(q.errorFullMetricsKey)
+		case ErrQueueTimeout:
+			q.metrics.IncCounter(q.errorTimeoutMetricsKey)
+		default:
+			q.metrics.IncCounter(q.errorOtherMetricsKey)
+		}
+	}
+
+	return done, err
+}
+
+func (q *FifoQueue) wait(ctx context.Context) (func(), error) {
+	q.mu.RLock()
+	closed := q.closed
+	c := q.config
+	sem := q.sem
+	q.mu.RUnlock()
+
+	if closed {
+		return nil, ErrQueueClosed
+	}
+
+	inflight := atomic.AddInt64(&q.inflight, 1)
+	if c.MaxQueueSize > 0 && inflight > int64(c.MaxConcurrency+c.MaxQueueSize) {
+		atomic.AddInt64(&q.inflight, -1)
+		return nil, ErrQueueFull
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	if err := sem.Acquire(ctx, 1); err != nil {
+		atomic.AddInt64(&q.inflight, -1)
+		if err == context.DeadlineExceeded {
+			return nil, ErrQueueTimeout
+		}
+
+		return nil, ErrClientCanceled
+	}
+
+	atomic.AddInt64(&q.active, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sem.Release(1)
+			atomic.AddInt64(&q.active, -1)
+			atomic.AddInt64(&q.inflight, -1)
+		})
+	}, nil
+}
+
+// Status returns the current status of a queue.
+func (q *FifoQueue) Status() QueueStatus {
+	q.mu.RLock()
+	closed := q.closed
+	q.mu.RUnlock()
+
+	active := atomic.LoadInt64(&q.active)
+	queued := atomic.LoadInt64(&q.inflight) - active
+	if queued < 0 {
+		queued = 0
+	}
+
+	return QueueStatus{
+		ActiveRequests: int(active),
+		QueuedRequests: int(queued),
+		Closed:         closed,
+	}
+}
+
+// Config returns the configuration that the queue was created with.
+func (q *FifoQueue) Config() Config {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.config
+}
+
+// reconfigure replaces the semaphore when the concurrency changes. Requests
+// that already hold or wait for the previous semaphore finish with it.
+func (q *FifoQueue) reconfigure(c Config) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if c.MaxConcurrency != q.config.MaxConcurrency {
+		q.sem = newSemaphore(c)
+	}
+
+	q.config = c
+}
+
+func (q *FifoQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+}
+
+func (q *FifoQueue) setMetrics(m metrics.Metrics, name string) {
+	if name == "" {
+		name = "unknown"
+	}
+
+	q.activeRequestsMetricsKey = fmt.Sprintf("fifo.%s.active", name)
+	q.queuedRequestsMetricsKey = fmt.Sprintf("fifo.%s.queued", name)
+	q.errorFullMetricsKey = fmt.Sprintf("fifo.%s.error.full", name)
+	q.errorOtherMetricsKey = fmt.Sprintf("fifo.%s.error.other", name)
+	q.errorTimeoutMetricsKey = fmt.Sprintf("fifo.%s.error.timeout", name)
+	q.metrics = m
+}
@@ -0,0 +1,272 @@
+package scheduler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/proxy/proxytest"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/scheduler"
+)
+
+func TestFifoQueueFull(t *testing.T) {
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+	}))
+	defer backend.Close()
+
+	reg := scheduler.NewRegistry()
+	defer reg.Close()
+
+	p := proxytest.WithRoutingOptions(builtin.MakeRegistry(), routing.Options{
+		PostProcessors: []routing.PostProcessor{reg},
+	}, &eskip.Route{
+		Id:      "fifo",
+		Filters: []*eskip.Filter{{Name: filters.FifoName, Args: []interface{}{1, 1, "10s"}}},
+		Backend: backend.URL,
+	})
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	get := func() {
+		defer wg.Done()
+		rsp, err := http.Get(p.URL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		rsp.Body.Close()
+		codes <- rsp.StatusCode
+	}
+
+	// one active and one queued request
+	wg.Add(2)
+	go get()
+	<-arrived
+	go get()
+	time.Sleep(50 * time.Millisecond)
+
+	rsp, err := http.Get(p.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Failed to get status code, got: %d, want: %d", rsp.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for c := range codes {
+		if c != http.StatusOK {
+			t.Errorf("Failed to get status code, got: %d, want: %d", c, http.StatusOK)
+		}
+	}
+}
+
+func TestFifoBackendTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		filters []*eskip.Filter
+	}{{
+		name: "backend timeout before fifo",
+		filters: []*eskip.Filter{
+			{Name: filters.BackendTimeoutName, Args: []interface{}{"50ms"}},
+			{Name: filters.FifoGroupName, Args: []interface{}{"group"}},
+		},
+	}, {
+		name: "backend timeout after fifo",
+		filters: []*eskip.Filter{
+			{Name: filters.FifoGroupName, Args: []interface{}{"group"}},
+			{Name: filters.BackendTimeoutName, Args: []interface{}{"50ms"}},
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var forwarded int32
+			arrived := make(chan struct{}, 1)
+			release := make(chan struct{})
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/slow" {
+					arrived <- struct{}{}
+					<-release
+					return
+				}
+
+				atomic.AddInt32(&forwarded, 1)
+			}))
+			defer backend.Close()
+
+			reg := scheduler.NewRegistry()
+			defer reg.Close()
+
+			p := proxytest.WithRoutingOptions(builtin.MakeRegistry(), routing.Options{
+				PostProcessors: []routing.PostProcessor{reg},
+			}, &eskip.Route{
+				Id:         "slow",
+				Predicates: []*eskip.Predicate{{Name: "Path", Args: []interface{}{"/slow"}}},
+				Filters:    []*eskip.Filter{{Name: filters.FifoGroupName, Args: []interface{}{"group", 1, 1, "10s"}}},
+				Backend:    backend.URL,
+			}, &eskip.Route{
+				Id:         "deadline",
+				Predicates: []*eskip.Predicate{{Name: "Path", Args: []interface{}{"/deadline"}}},
+				Filters:    tt.filters,
+				Backend:    backend.URL,
+			})
+			defer p.Close()
+
+			slow := make(chan int, 1)
+			go func() {
+				rsp, err := http.Get(p.URL + "/slow")
+				if err != nil {
+					t.Error(err)
+					slow <- 0
+					return
+				}
+
+				rsp.Body.Close()
+				slow <- rsp.StatusCode
+			}()
+
+			<-arrived
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				close(release)
+			}()
+
+			// waits in the queue longer than its backend timeout
+			rsp, err := http.Get(p.URL + "/deadline")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp.Body.Close()
+			if rsp.StatusCode != http.StatusGatewayTimeout {
+				t.Errorf("Failed to get status code, got: %d, want: %d", rsp.StatusCode, http.StatusGatewayTimeout)
+			}
+
+			if n := atomic.LoadInt32(&forwarded); n != 0 {
+				t.Errorf("Failed to reject request before forwarding, forwarded: %d", n)
+			}
+
+			if c := <-slow; c != http.StatusOK {
+				t.Errorf("Failed to get status code, got: %d, want: %d", c, http.StatusOK)
+			}
+		})
+	}
+}
+
+func newFifoQueue(t *testing.T, reg *scheduler.Registry, args ...interface{}) *scheduler.FifoQueue {
+	t.Helper()
+	f, err := builtin.MakeRegistry()[filters.FifoName].CreateFilter(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg.Do([]*routing.Route{{
+		Route:   eskip.Route{Id: "fifo"},
+		Filters: []*routing.RouteFilter{{Filter: f, Name: filters.FifoName}},
+	}})
+
+	return f.(scheduler.FIFOFilter).GetQueue()
+}
+
+func TestFifoQueueOrder(t *testing.T) {
+	reg := scheduler.NewRegistry()
+	defer reg.Close()
+
+	q := newFifoQueue(t, reg, 1, 10, "10s")
+	done, err := q.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d, err := q.Wait(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			d()
+		}(i)
+
+		// make sure that the requests enter the queue in order
+		for q.Status().QueuedRequests != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	done()
+	wg.Wait()
+	for i, o := range order {
+		if i != o {
+			t.Fatalf("Failed to process requests in order: %v", order)
+		}
+	}
+}
+
+func TestFifoQueueErrors(t *testing.T) {
+	reg := scheduler.NewRegistry()
+	defer reg.Close()
+
+	q := newFifoQueue(t, reg, 1, 1, "10ms")
+	done, err := q.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer done()
+
+	if _, err := q.Wait(context.Background()); err != scheduler.ErrQueueTimeout {
+		t.Errorf("Failed to get timeout, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.Wait(ctx); err != scheduler.ErrClientCanceled {
+		t.Errorf("Failed to get client canceled, got: %v", err)
+	}
+
+	queued := make(chan error)
+	go func() {
+		_, err := q.Wait(context.Background())
+		queued <- err
+	}()
+
+	for q.Status().QueuedRequests != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := q.Wait(context.Background()); err != scheduler.ErrQueueFull {
+		t.Errorf("Failed to get queue full, got: %v", err)
+	}
+
+	<-queued
+}
@@ -1,5 +1,5 @@
 // Package scheduler provides a registry to be used as a postprocessor for the routes
-// that use a LIFO filter.
+// that use a LIFO or a FIFO filter.
 package scheduler
 
 import (
@@ -77,11 +77,15 @@ type Options struct {
 	// EnableRouteLIFOMetrics enables collecting metrics about the LIFO queues.
 	EnableRouteLIFOMetrics bool
 
-	// Metrics must be provided to the registry in order to collect the LIFO metrics.
+	// EnableRouteFIFOMetrics enables collecting metrics about the FIFO queues.
+	EnableRouteFIFOMetrics bool
+
+	// Metrics must be provided to the registry in order to collect the LIFO
+	// and FIFO metrics.
 	Metrics metrics.Metrics
 }
 
-// Registry maintains a set of LIFO queues. It is used to preserve LIFO queue instances
+// Registry maintains a set of LIFO and FIFO queues. It is used to preserve queue instances
 // across multiple generations of the routing. It implements the routing.PostProcessor
 // interface, it is enough to just pass in to routing.Routing when initializing it.
 //
@@ -227,10 +231,11 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 	rr := make([]*routing.Route, len(routes))
 	existingKeys := make(map[string]bool)
 	groups := make(map[string][]GroupedLIFOFilter)
+	fifoGroups := make(map[string][]GroupedFIFOFilter)
 
 	for i, ri := range routes {
 		rr[i] = ri
-		var lifoCount int
+		var lifoCount, fifoCount int
 		for _, fi := range ri.Filters {
 			if glf, ok := fi.Filter.(GroupedLIFOFilter); ok {
 				groupName := glf.Group()
@@ -238,6 +243,20 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 				continue
 			}
 
+			if gff, ok := fi.Filter.(GroupedFIFOFilter); ok {
+				groupName := gff.Group()
+				fifoGroups[groupName] = append(fifoGroups[groupName], gff)
+				continue
+			}
+
+			if ff, ok := fi.Filter.(FIFOFilter); ok {
+				fifoCount++
+				key := fmt.Sprintf("fifo::%s", ri.Id)
+				existingKeys[key] = true
+				ff.SetQueue(r.fifoQueue(key, ri.Id, ff.Config()))
+				continue
+			}
+
 			lf, ok := fi.Filter.(LIFOFilter)
 			if !ok {
 				continue
@@ -266,6 +285,10 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 		if lifoCount > 1 {
 			log.Warnf("Found multiple lifo filters in route: %s", ri.Id)
 		}
+
+		if fifoCount > 1 {
+			log.Warnf("Found multiple fifo filters in route: %s", ri.Id)
+		}
 	}
 
 	for name, group := range groups {
@@ -308,9 +331,37 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 		}
 	}
 
+	for name, group := range fifoGroups {
+		var (
+			c           Config
+			foundConfig bool
+		)
+
+		for _, gff := range group {
+			if !gff.HasConfig() {
+				continue
+			}
+
+			if foundConfig && gff.Config() != c {
+				log.Warnf("Found mismatching configuration for the FIFO group: %s", name)
+				continue
+			}
+
+			c = gff.Config()
+			foundConfig = true
+		}
+
+		key := fmt.Sprintf("group-fifo::%s", name)
+		existingKeys[key] = true
+		q := r.fifoQueue(key, name, c)
+		for _, gff := range group {
+			gff.SetQueue(q)
+		}
+	}
+
 	r.queues.Range(func(key, qi interface{}) bool {
 		if !existingKeys[key.(string)] {
-			qi.(*Queue).close()
+			closeQueue(qi)
 			r.queues.Delete(key)
 		}
 
@@ -320,6 +371,37 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 	return rr
 }
 
+// fifoQueue returns the stored FIFO queue for key, or creates and stores a
+// new one. The configuration of an existing queue is updated when changed.
+func (r *Registry) fifoQueue(key, name string, c Config) *FifoQueue {
+	if qi, ok := r.queues.Load(key); ok {
+		q := qi.(*FifoQueue)
+		if q.Config() != c {
+			q.reconfigure(c)
+		}
+
+		return q
+	}
+
+	q := newFifoQueue(c)
+	if r.options.EnableRouteFIFOMetrics {
+		q.setMetrics(r.options.Metrics, name)
+		r.measure()
+	}
+
+	r.queues.Store(key, q)
+	return q
+}
+
+func closeQueue(qi interface{}) {
+	switch q := qi.(type) {
+	case *Queue:
+		q.close()
+	case *FifoQueue:
+		q.close()
+	}
+}
+
 func (r *Registry) measure() {
 	if r.options.Metrics == nil || r.measuring {
 		return
@@ -329,10 +411,25 @@ func (r *Registry) measure() {
 	go func() {
 		for {
 			r.queues.Range(func(_, value interface{}) bool {
-				q := value.(*Queue)
-				s := q.Status()
-				r.options.Metrics.UpdateGauge(q.activeRequestsMetricsKey, float64(s.ActiveRequests))
-				r.options.Metrics.UpdateGauge(q.queuedRequestsMetricsKey, float64(s.QueuedRequests))
+				switch q := value.(type) {
+				case *Queue:
+					if q.metrics == nil {
+						return true
+					}
+
+					s := q.Status()
+					r.options.Metrics.UpdateGauge(q.activeRequestsMetricsKey, float64(s.ActiveRequests))
+					r.options.Metrics.UpdateGauge(q.queuedRequestsMetricsKey, float64(s.QueuedRequests))
+				case *FifoQueue:
+					if q.metrics == nil {
+						return true
+					}
+
+					s := q.Status()
+					r.options.Metrics.UpdateGauge(q.activeRequestsMetricsKey, float64(s.ActiveRequests))
+					r.options.Metrics.UpdateGauge(q.queuedRequestsMetricsKey, float64(s.QueuedRequests))
+				}
+
 				return true
 			})
 
@@ -349,7 +446,7 @@ func (r *Registry) measure() {
 // queues.
 func (r *Registry) Close() {
 	r.queues.Range(func(_, value interface{}) bool {
-		value.(*Queue).close()
+		closeQueue(value)
 		return true
 	})
 
@@ -0,0 +1,285 @@
+/*
+Package certdir implements a registry of TLS certificates loaded from a
+directory, and selected by the server name received with SNI.
+
+The directory is expected to contain the certificates and the keys in
+PEM format, in pairs of files with the same name and the extensions .crt
+and .key, e.g. example.org.crt and example.org.key. The certificates are
+matched by their DNS names, or, when they don't have any, by their
+common names. Wildcard names, e.g. *.example.org, match a single label
+of the server name. When multiple certificates match the same name, the
+one that expires the latest is used.
+
+The directory is polled, and the changed, added or removed certificates
+are reloaded, without restarting the server, similar to
+secrets.SecretPaths.
+
+The registry is used as the GetCertificate function of a tls.Config:
+
+	r, err := certdir.New(certdir.Options{Dir: "/etc/skipper/certs"})
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+	config := &tls.Config{
+		Certificates:   []tls.Certificate{defaultCertificate},
+		GetCertificate: r.GetCertificate,
+	}
+
+When no certificate in the directory matches the server name, the
+registry returns nil, and the TLS server falls back to the Certificates
+of the tls.Config.
+*/
+package certdir
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/metrics"
+)
+
+const (
+	defaultRefreshInterval = time.Minute
+
+	certExtension = ".crt"
+	keyExtension  = ".key"
+
+	expiryMetricsPrefix = "tls.certificate."
+	expiryMetricsSuffix = ".expiry"
+)
+
+// Options are used to initialize the certificate registry.
+type Options struct {
+
+	// Dir is the directory containing the certificates and the keys.
+	// Required.
+	Dir string
+
+	// RefreshInterval sets how often the directory is checked for
+	// changes. Defaults to 1m.
+	RefreshInterval time.Duration
+
+	// Metrics, when set, is used to report the expiry of each
+	// certificate as a gauge with the key
+	// tls.certificate.<file name>.expiry, containing the Unix time in
+	// seconds, when the certificate expires. The gauges of the removed
+	// certificates are deleted, when the metrics implement
+	// DeleteGauge(key string), like the Prometheus and the CodaHale
+	// metrics.
+	Metrics metrics.Metrics
+}
+
+// Registry holds the certificates loaded from a directory.
+type Registry struct {
+	options  Options
+	quit     chan struct{}
+	once     sync.Once
+	files    string
+	names    atomic.Value // map[string]*tls.Certificate
+	reported []string
+}
+
+// New creates a certificate registry, loading the certificates from
+// the directory, and it starts polling the directory for changes. On
+// tear down, make sure to Close() it.
+func New(o Options) (*Registry, error) {
+	if o.RefreshInterval <= 0 {
+		o.RefreshInterval = defaultRefreshInterval
+	}
+
+	fi, err := os.Stat(o.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access the certificate directory: %w", err)
+	}
+
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("certificate path is not a directory: %s", o.Dir)
+	}
+
+	r := &Registry{
+		options: o,
+		quit:    make(chan struct{}),
+	}
+
+	r.names.Store(map[string]*tls.Certificate{})
+	if err := r.refresh(); err != nil {
+		return nil, err
+	}
+
+	go r.runRefresher()
+	return r, nil
+}
+
+func (r *Registry) certificateFiles() ([]string, string, error) {
+	m, err := filepath.Glob(filepath.Join(r.options.Dir, "*"+certExtension))
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Strings(m)
+
+	// the state of the files is used to detect changes without
+	// parsing the certificates
+	var state strings.Builder
+	for _, crt := range m {
+		for _, p := range []string{crt, keyPath(crt)} {
+			fi, err := os.Stat(p)
+			if err != nil {
+				fmt.Fprintf(&state, "%s:missing;", p)
+				continue
+			}
+
+			fmt.Fprintf(&state, "%s:%d:%d;", p, fi.ModTime().UnixNano(), fi.Size())
+		}
+	}
+
+	return m, state.String(), nil
+}
+
+func keyPath(crt string) string {
+	return strings.TrimSuffix(crt, certExtension) + keyExtension
+}
+
+func certificateName(crt string) string {
+	return strings.TrimSuffix(filepath.Base(crt), certExtension)
+}
+
+func certificateNames(leaf *x509.Certificate) []string {
+	names := leaf.DNSNames
+	if len(names) == 0 && leaf.Subject.CommonName != "" {
+		names = []string{leaf.Subject.CommonName}
+	}
+
+	lower := make([]string, len(names))
+	for i := range names {
+		lower[i] = strings.ToLower(names[i])
+	}
+
+	return lower
+}
+
+func (r *Registry) refresh() error {
+	files, state, err := r.certificateFiles()
+	if err != nil {
+		return fmt.Errorf("failed to list the certificate directory: %w", err)
+	}
+
+	if state == r.files {
+		return nil
+	}
+
+	names := make(map[string]*tls.Certificate)
+	var (
+		reported []string
+		loaded   int
+	)
+
+	for _, crt := range files {
+		cert, err := tls.LoadX509KeyPair(crt, keyPath(crt))
+		if err != nil {
+			log.Errorf("Failed to load certificate %s: %v", crt, err)
+			continue
+		}
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			log.Errorf("Failed to parse certificate %s: %v", crt, err)
+			continue
+		}
+
+		cert.Leaf = leaf
+		loaded++
+		for _, name := range certificateNames(leaf) {
+			if current, ok := names[name]; ok && current.Leaf.NotAfter.After(leaf.NotAfter) {
+				continue
+			}
+
+			names[name] = &cert
+		}
+
+		if r.options.Metrics != nil {
+			key := expiryMetricsPrefix + certificateName(crt) + expiryMetricsSuffix
+			r.options.Metrics.UpdateGauge(key, float64(leaf.NotAfter.Unix()))
+			reported = append(reported, key)
+		}
+	}
+
+	// the gauges of the removed certificates are deleted, when the
+	// metrics support it, otherwise they would trigger false expiry
+	// alerts
+	if d, ok := r.options.Metrics.(interface{ DeleteGauge(string) }); ok {
+		for _, key := range r.reported {
+			if !stringsContain(reported, key) {
+				d.DeleteGauge(key)
+			}
+		}
+	}
+
+	r.names.Store(names)
+	r.files = state
+	r.reported = reported
+	log.Infof("Loaded %d of %d certificates from %s", loaded, len(files), r.options.Dir)
+	return nil
+}
+
+func stringsContain(s []string, v string) bool {
+	for _, si := range s {
+		if si == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Registry) runRefresher() {
+	for {
+		select {
+		case <-time.After(r.options.RefreshInterval):
+			if err := r.refresh(); err != nil {
+				log.Errorf("Failed to refresh certificates: %v", err)
+			}
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// GetCertificate returns the certificate matching the server name of
+// the client hello. It returns nil, when no certificate matches, to let
+// the TLS server use the default certificates.
+func (r *Registry) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if name == "" {
+		return nil, nil
+	}
+
+	names := r.names.Load().(map[string]*tls.Certificate)
+	if cert, ok := names[name]; ok {
+		return cert, nil
+	}
+
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if cert, ok := names["*"+name[i:]]; ok {
+			return cert, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Close stops polling the directory.
+func (r *Registry) Close() {
+	r.once.Do(func() { close(r.quit) })
+}
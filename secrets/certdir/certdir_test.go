@@ -0,0 +1,240 @@
+package certdir
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/metrics/metricstest"
+)
+
+// writeCert generates a self-signed certificate for testing purposes,
+// and writes it, and its key, to the directory
+func writeCert(t *testing.T, dir, name, commonName string, notAfter time.Time, dnsNames ...string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     dnsNames,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+keyExtension), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name+certExtension), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func serverCommonName(t *testing.T, r *Registry, serverName string) string {
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cert == nil {
+		return ""
+	}
+
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestInvalidDir(t *testing.T) {
+	if _, err := New(Options{Dir: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("failed to fail for a missing directory")
+	}
+
+	f := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(f, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(Options{Dir: f}); err == nil {
+		t.Error("failed to fail for a file")
+	}
+}
+
+func TestGetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	expiry := time.Now().Add(24 * time.Hour)
+	writeCert(t, dir, "example", "example", expiry, "www.example.org", "example.org")
+	writeCert(t, dir, "wildcard", "wildcard", expiry, "*.example.org")
+	writeCert(t, dir, "cn-only", "cn.example.com", expiry)
+	writeCert(t, dir, "old", "old", expiry.Add(-time.Hour), "api.example.com")
+	writeCert(t, dir, "new", "new", expiry, "API.example.com")
+
+	// invalid pairs are skipped
+	if err := os.WriteFile(filepath.Join(dir, "invalid.crt"), []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	writeCert(t, dir, "nokey", "nokey", expiry, "nokey.example.org")
+	if err := os.Remove(filepath.Join(dir, "nokey.key")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer r.Close()
+	for _, test := range []struct {
+		serverName string
+		expected   string
+	}{
+		{"www.example.org", "example"},
+		{"WWW.Example.Org.", "example"},
+		{"example.org", "example"},
+		{"foo.example.org", "wildcard"},
+		{"foo.bar.example.org", ""},
+		{"cn.example.com", "cn.example.com"},
+		{"api.example.com", "new"},
+		{"nokey.example.org", "wildcard"},
+		{"www.example.net", ""},
+		{"", ""},
+	} {
+		if cn := serverCommonName(t, r, test.serverName); cn != test.expected {
+			t.Errorf("invalid certificate for %q, expected: %q, got: %q", test.serverName, test.expected, cn)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	expiry := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	writeCert(t, dir, "example", "first", expiry, "www.example.org")
+
+	m := &metricstest.MockMetrics{}
+	r, err := New(Options{Dir: dir, RefreshInterval: 10 * time.Millisecond, Metrics: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer r.Close()
+	if cn := serverCommonName(t, r, "www.example.org"); cn != "first" {
+		t.Fatalf("failed to load certificate, got: %q", cn)
+	}
+
+	if v, ok := m.Gauge("tls.certificate.example.expiry"); !ok || v != float64(expiry.Unix()) {
+		t.Errorf("invalid expiry metric: %v, %t", v, ok)
+	}
+
+	waitFor := func(serverName, expected string) {
+		timeout := time.After(3 * time.Second)
+		for serverCommonName(t, r, serverName) != expected {
+			select {
+			case <-timeout:
+				t.Fatalf("timeout while waiting for %q to be served by %q", serverName, expected)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+
+	// changed
+	writeCert(t, dir, "example", "second", expiry.Add(time.Hour), "www.example.org")
+	waitFor("www.example.org", "second")
+	if v, _ := m.Gauge("tls.certificate.example.expiry"); v != float64(expiry.Add(time.Hour).Unix()) {
+		t.Errorf("failed to update expiry metric: %v", v)
+	}
+
+	// added
+	writeCert(t, dir, "other", "other", expiry, "www.example.com")
+	waitFor("www.example.com", "other")
+
+	// removed
+	if err := os.Remove(filepath.Join(dir, "example.crt")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor("www.example.org", "")
+	if v, ok := m.Gauge("tls.certificate.example.expiry"); ok {
+		t.Errorf("failed to delete expiry metric: %v", v)
+	}
+}
+
+func TestFallbackCertificate(t *testing.T) {
+	dir := t.TempDir()
+	writeCert(t, dir, "example", "example", time.Now().Add(time.Hour), "www.example.org")
+	writeCert(t, dir, "default", "default", time.Now().Add(time.Hour), "default.example.org")
+	fallback, err := tls.LoadX509KeyPair(filepath.Join(dir, "default.crt"), filepath.Join(dir, "default.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "default.crt")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer r.Close()
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates:   []tls.Certificate{fallback},
+		GetCertificate: r.GetCertificate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			c.(*tls.Conn).Handshake()
+			c.Close()
+		}
+	}()
+
+	for serverName, expected := range map[string]string{
+		"www.example.org": "example",
+		"www.example.net": "default",
+		"":                "default",
+	} {
+		c, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cn := c.ConnectionState().PeerCertificates[0].Subject.CommonName
+		c.Close()
+		if cn != expected {
+			t.Errorf("invalid certificate for %q, expected: %q, got: %q", serverName, expected, cn)
+		}
+	}
+}
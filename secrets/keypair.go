@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"sync"
+)
+
+// KeyPair provides a TLS certificate from a certificate and a key
+// secret. The certificate is parsed again, when the secrets change,
+// e.g. after rotating the files watched by SecretPaths.
+type KeyPair struct {
+	sr       SecretsReader
+	certName string
+	keyName  string
+
+	mu   sync.Mutex
+	cert []byte
+	key  []byte
+	pair *tls.Certificate
+}
+
+// NewKeyPair creates a KeyPair, reading the PEM encoded certificate
+// and key from the secrets named certName and keyName. It fails, when
+// the secrets can't be found or can't be parsed.
+func NewKeyPair(sr SecretsReader, certName, keyName string) (*KeyPair, error) {
+	kp := &KeyPair{sr: sr, certName: certName, keyName: keyName}
+	if _, err := kp.Certificate(); err != nil {
+		return nil, err
+	}
+
+	return kp, nil
+}
+
+// Certificate returns the current certificate. When the secrets
+// changed, but they can't be parsed, it returns the previous
+// certificate.
+func (kp *KeyPair) Certificate() (*tls.Certificate, error) {
+	cert, ok := kp.sr.GetSecret(kp.certName)
+	if !ok {
+		return nil, fmt.Errorf("certificate not found: %s", kp.certName)
+	}
+
+	key, ok := kp.sr.GetSecret(kp.keyName)
+	if !ok {
+		return nil, fmt.Errorf("key not found: %s", kp.keyName)
+	}
+
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+
+	if kp.pair != nil && bytes.Equal(cert, kp.cert) && bytes.Equal(key, kp.key) {
+		return kp.pair, nil
+	}
+
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		if kp.pair != nil {
+			return kp.pair, nil
+		}
+
+		return nil, fmt.Errorf("failed to load key pair %s, %s: %w", kp.certName, kp.keyName, err)
+	}
+
+	kp.cert, kp.key, kp.pair = cert, key, &pair
+	return kp.pair, nil
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate.
+func (kp *KeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return kp.Certificate()
+}
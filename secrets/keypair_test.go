@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+type mapSecrets map[string][]byte
+
+func (m mapSecrets) GetSecret(s string) ([]byte, bool) {
+	b, ok := m[s]
+	return b, ok
+}
+
+func (mapSecrets) Close() {}
+
+func testKeyPair(t *testing.T, cn string) (cert, key []byte) {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &k.PublicKey, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kder, err := x509.MarshalECPrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder})
+	return
+}
+
+func commonName(t *testing.T, kp *KeyPair) string {
+	c, err := kp.GetClientCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := x509.ParseCertificate(c.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return x.Subject.CommonName
+}
+
+func TestKeyPair(t *testing.T) {
+	cert, key := testKeyPair(t, "first")
+
+	t.Run("missing cert", func(t *testing.T) {
+		if _, err := NewKeyPair(mapSecrets{"key": key}, "cert", "key"); err == nil {
+			t.Error("failed to fail")
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		if _, err := NewKeyPair(mapSecrets{"cert": cert}, "cert", "key"); err == nil {
+			t.Error("failed to fail")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := NewKeyPair(mapSecrets{"cert": cert, "key": []byte("foo")}, "cert", "key"); err == nil {
+			t.Error("failed to fail")
+		}
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		s := mapSecrets{"cert": cert, "key": key}
+		kp, err := NewKeyPair(s, "cert", "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cn := commonName(t, kp); cn != "first" {
+			t.Errorf("invalid certificate, expected: first, got: %s", cn)
+		}
+
+		s["cert"], s["key"] = testKeyPair(t, "second")
+		if cn := commonName(t, kp); cn != "second" {
+			t.Errorf("invalid certificate, expected: second, got: %s", cn)
+		}
+
+		s["key"] = key
+		if cn := commonName(t, kp); cn != "second" {
+			t.Errorf("failed to keep the previous certificate, got: %s", cn)
+		}
+	})
+}
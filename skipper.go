@@ -3,6 +3,8 @@ package skipper
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -13,16 +15,19 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	ot "github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/quic-go/quic-go/http3"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/zalando/skipper/circuit"
 	"github.com/zalando/skipper/dataclients/kubernetes"
 	"github.com/zalando/skipper/dataclients/routestring"
+	"github.com/zalando/skipper/drain"
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/eskipfile"
 	"github.com/zalando/skipper/etcd"
@@ -39,10 +44,12 @@ import (
 	"github.com/zalando/skipper/metrics"
 	skpnet "github.com/zalando/skipper/net"
 	pauth "github.com/zalando/skipper/predicates/auth"
+	"github.com/zalando/skipper/predicates/clientcert"
 	"github.com/zalando/skipper/predicates/cookie"
 	"github.com/zalando/skipper/predicates/cron"
 	"github.com/zalando/skipper/predicates/forwarded"
 	"github.com/zalando/skipper/predicates/interval"
+	"github.com/zalando/skipper/predicates/listener"
 	"github.com/zalando/skipper/predicates/methods"
 	"github.com/zalando/skipper/predicates/primitive"
 	"github.com/zalando/skipper/predicates/query"
@@ -50,11 +57,13 @@ import (
 	"github.com/zalando/skipper/predicates/tee"
 	"github.com/zalando/skipper/predicates/traffic"
 	"github.com/zalando/skipper/proxy"
+	"github.com/zalando/skipper/proxyprotocol"
 	"github.com/zalando/skipper/queuelistener"
 	"github.com/zalando/skipper/ratelimit"
 	"github.com/zalando/skipper/routing"
 	"github.com/zalando/skipper/scheduler"
 	"github.com/zalando/skipper/secrets"
+	"github.com/zalando/skipper/secrets/certdir"
 	"github.com/zalando/skipper/swarm"
 	"github.com/zalando/skipper/tracing"
 )
@@ -66,6 +75,76 @@ const (
 
 const DefaultPluginDir = "./plugins"
 
+// ListenerOptions defines an additional proxy listener. The requests
+// received on the additional listeners are handled by the same proxy
+// and routing table as the ones received on the main listener, and the
+// routes can be limited to certain listeners with the Listener
+// predicate.
+type ListenerOptions struct {
+
+	// Name of the listener, used by the Listener predicate. Required.
+	Name string
+
+	// Network address that the listener accepts connections on.
+	// Required.
+	Address string
+
+	// Path of certificate(s) when using TLS, mutiple may be given comma
+	// separated.
+	CertPathTLS string
+
+	// Path of key(s) when using TLS, multiple may be given comma
+	// separated. For multiple keys, the order must match the one given
+	// in CertPathTLS.
+	KeyPathTLS string
+
+	// EnableTCPQueue enables controlling the concurrently processed
+	// requests at the TCP listener. The queue of the listener is
+	// independent from the queue of the main listener.
+	EnableTCPQueue bool
+
+	// MaxTCPListenerConcurrency defines the max number of concurrently
+	// accepted connections, when the TCP queue is enabled.
+	MaxTCPListenerConcurrency int
+
+	// MaxTCPListenerQueue defines the maximum number of pending
+	// connections, when the TCP queue is enabled.
+	MaxTCPListenerQueue int
+
+	// EnableProxyProtocol enables accepting PROXY protocol headers on
+	// the listener, from the networks defined by
+	// Options.ProxyProtocolTrustedCIDRs.
+	EnableProxyProtocol bool
+}
+
+// BackendTLSProfile defines a named TLS configuration for the backend
+// connections. Routes select the profile with the backendTLS filter.
+type BackendTLSProfile struct {
+
+	// Name of the profile, used by the backendTLS filter. Required.
+	Name string
+
+	// CertPath is the path of the client certificate, presented to the
+	// backends. The file is watched, and rotated certificates are used
+	// for the new connections.
+	CertPath string
+
+	// KeyPath is the path of the key of the client certificate.
+	KeyPath string
+
+	// CAPath is the path of the CA bundle used to verify the backend
+	// certificates, multiple may be given comma separated. When not
+	// set, the system CA pool is used.
+	CAPath string
+
+	// ServerName overrides the server name used to verify the backend
+	// certificates, and sent in the TLS handshake.
+	ServerName string
+
+	// MinVersion of TLS. When not set, Options.TLSMinVersion is used.
+	MinVersion uint16
+}
+
 type testOptions struct {
 	redisConnMetricsInterval time.Duration
 }
@@ -112,6 +191,30 @@ type Options struct {
 	// If defines the maximum number of pending connection waiting in the queue.
 	MaxTCPListenerQueue int
 
+	// EnableProxyProtocol enables accepting PROXY protocol v1 and v2
+	// headers on the proxy listener. The client address received in
+	// the header is used as the remote address of the requests.
+	EnableProxyProtocol bool
+
+	// ProxyProtocolTrustedCIDRs contains the networks allowed to send
+	// PROXY protocol headers. Required when EnableProxyProtocol is set
+	// on the main or on an additional listener.
+	ProxyProtocolTrustedCIDRs []string
+
+	// Listeners defines additional proxy listeners, e.g. to serve
+	// internal traffic on a different port than the public traffic.
+	Listeners []ListenerOptions
+
+	// EnableHTTP3 enables an HTTP/3 (QUIC) listener next to the TLS
+	// proxy listener, sharing its TLS configuration. The responses of
+	// the TLS listener advertise the HTTP/3 listener in the Alt-Svc
+	// header. It requires TLS.
+	EnableHTTP3 bool
+
+	// AddressHTTP3 is the UDP address of the HTTP/3 listener. Defaults
+	// to Address.
+	AddressHTTP3 string
+
 	// List of custom filter specifications.
 	CustomFilters []filters.Spec
 
@@ -215,6 +318,10 @@ type Options struct {
 	// used with external name services (type=ExternalName).
 	KubernetesAllowedExternalNames []*regexp.Regexp
 
+	// KubernetesReportInvalidRoutes enables creating Warning events on the
+	// Ingress and RouteGroup objects whose routes are invalid.
+	KubernetesReportInvalidRoutes bool
+
 	// *DEPRECATED* API endpoint of the Innkeeper service, storing route definitions.
 	InnkeeperUrl string
 
@@ -255,6 +362,10 @@ type Options struct {
 	// InlineRoutes can define routes as eskip text.
 	InlineRoutes string
 
+	// InlineRoutesYAML can define routes as a YAML document, see
+	// eskip.ParseYAML.
+	InlineRoutesYAML string
+
 	// Polling timeout of the routing data sources.
 	SourcePollTimeout time.Duration
 
@@ -367,6 +478,16 @@ type Options struct {
 	// of routes were applied.
 	WaitFirstRouteLoad bool
 
+	// RouteHistorySize sets the number of route table generations whose
+	// changes are kept and served on the /routes/history endpoint of the
+	// support listener. When zero, routing.DefaultHistorySize is used,
+	// and when negative, no history is kept.
+	RouteHistorySize int
+
+	// LintRoutes enables logging the unreachable, duplicate and
+	// ambiguous routes on startup and on every route update.
+	LintRoutes bool
+
 	// SuppressRouteUpdateLogs indicates to log only summaries of the routing updates
 	// instead of full details of the updated/deleted routes.
 	SuppressRouteUpdateLogs bool
@@ -379,6 +500,15 @@ type Options struct {
 	// Network address for the support endpoints
 	SupportListener string
 
+	// DrainTokenFile is the path of the file that contains the bearer
+	// token of the /drain endpoint of the support listener. The
+	// endpoint is enabled only when it is set. See package drain.
+	DrainTokenFile string
+
+	// UpgradeDrainTimeout sets the time the upgraded connections are
+	// kept open after draining started.
+	UpgradeDrainTimeout time.Duration
+
 	// Deprecated: Network address for the /metrics endpoint
 	MetricsListener string
 
@@ -537,12 +667,40 @@ type Options struct {
 	// multiple keys, the order must match the one given in CertPathTLS
 	KeyPathTLS string
 
+	// CertDirTLS is a directory containing certificates and keys in
+	// pairs of .crt and .key files. The certificates are selected by
+	// the server name received with SNI, and they are reloaded when the
+	// files change. When no certificate of the directory matches, the
+	// certificates set by CertPathTLS and KeyPathTLS are used, therefore
+	// CertDirTLS requires them to be set, too.
+	CertDirTLS string
+
+	// CertDirRefreshIntervalTLS sets how often CertDirTLS is checked
+	// for changes. Defaults to 1m.
+	CertDirRefreshIntervalTLS time.Duration
+
+	// ClientCAPathTLS sets the path of the CA bundle(s), multiple may be
+	// given comma separated, used to verify the client certificates of
+	// the TLS listeners. When set, and ClientAuthTLS is not, the client
+	// certificates are verified, when the clients present them.
+	ClientCAPathTLS string
+
+	// ClientAuthTLS sets the policy of the TLS listeners for the client
+	// certificates. When nil, it defaults to tls.VerifyClientCertIfGiven
+	// with ClientCAPathTLS set, otherwise to tls.NoClientCert.
+	ClientAuthTLS *tls.ClientAuthType
+
 	// TLS Settings for Proxy Server
 	ProxyTLS *tls.Config
 
 	// Client TLS to connect to Backends
 	ClientTLS *tls.Config
 
+	// BackendTLSProfiles defines named TLS configurations for the
+	// backend connections, that routes can select with the backendTLS
+	// filter.
+	BackendTLSProfiles []BackendTLSProfile
+
 	// TLSMinVersion to set the minimal TLS version for all TLS configurations
 	TLSMinVersion uint16
 
@@ -583,6 +741,9 @@ type Options struct {
 	// EnableRouteLIFOMetrics enables metrics for the individual route LIFO queues, if any.
 	EnableRouteLIFOMetrics bool
 
+	// EnableRouteFIFOMetrics enables metrics for the individual route FIFO queues, if any.
+	EnableRouteFIFOMetrics bool
+
 	// OpenTracing enables opentracing
 	OpenTracing []string
 
@@ -784,6 +945,18 @@ type Options struct {
 	ClusterRatelimitMaxGroupShards int
 
 	testOptions
+
+	drainer *drain.Drainer
+}
+
+// draining returns the channel that is closed when draining starts,
+// or nil, when the draining mode is not initialized.
+func (o *Options) draining() <-chan struct{} {
+	if o.drainer == nil {
+		return nil
+	}
+
+	return o.drainer.Done()
 }
 
 func createDataClients(o Options, auth innkeeper.Authentication) ([]routing.DataClient, error) {
@@ -832,6 +1005,16 @@ func createDataClients(o Options, auth innkeeper.Authentication) ([]routing.Data
 		clients = append(clients, ir)
 	}
 
+	if o.InlineRoutesYAML != "" {
+		ir, err := routestring.NewYAML(o.InlineRoutesYAML)
+		if err != nil {
+			log.Error("error while parsing inline YAML routes", err)
+			return nil, err
+		}
+
+		clients = append(clients, ir)
+	}
+
 	if o.InnkeeperUrl != "" {
 		ic, err := innkeeper.New(innkeeper.Options{
 			Address:          o.InnkeeperUrl,
@@ -885,7 +1068,9 @@ func createDataClients(o Options, auth innkeeper.Authentication) ([]routing.Data
 			OriginMarker:                      o.EnableRouteCreationMetrics,
 			PathMode:                          o.KubernetesPathMode,
 			ProvideHealthcheck:                o.KubernetesHealthcheck,
+			Draining:                          o.draining(),
 			ProvideHTTPSRedirect:              o.KubernetesHTTPSRedirect,
+			ReportInvalidRoutes:               o.KubernetesReportInvalidRoutes,
 			ReverseSourcePredicate:            o.ReverseSourcePredicate,
 			RouteGroupClass:                   o.KubernetesRouteGroupClass,
 			WhitelistedHealthCheckCIDR:        o.WhitelistedHealthCheckCIDR,
@@ -953,12 +1138,16 @@ func (o *Options) tlsConfig() (*tls.Config, error) {
 		return o.ProxyTLS, nil
 	}
 
-	if o.CertPathTLS == "" && o.KeyPathTLS == "" {
+	return o.loadTLSConfig(o.CertPathTLS, o.KeyPathTLS)
+}
+
+func (o *Options) loadTLSConfig(certPaths, keyPaths string) (*tls.Config, error) {
+	if certPaths == "" && keyPaths == "" {
 		return nil, nil
 	}
 
-	crts := strings.Split(o.CertPathTLS, ",")
-	keys := strings.Split(o.KeyPathTLS, ",")
+	crts := strings.Split(certPaths, ",")
+	keys := strings.Split(keyPaths, ",")
 
 	if len(crts) != len(keys) {
 		return nil, fmt.Errorf("number of certificates does not match number of keys")
@@ -976,20 +1165,152 @@ func (o *Options) tlsConfig() (*tls.Config, error) {
 		}
 		config.Certificates = append(config.Certificates, keypair)
 	}
+
+	if err := o.setClientAuth(config); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
+func (o *Options) backendTLSProfiles(sp *secrets.SecretPaths) (map[string]*tls.Config, error) {
+	if len(o.BackendTLSProfiles) == 0 {
+		return nil, nil
+	}
+
+	profiles := make(map[string]*tls.Config)
+	for _, bp := range o.BackendTLSProfiles {
+		if bp.Name == "" {
+			return nil, errors.New("backend TLS profile without name")
+		}
+
+		if _, ok := profiles[bp.Name]; ok {
+			return nil, fmt.Errorf("duplicate backend TLS profile: %s", bp.Name)
+		}
+
+		config := &tls.Config{
+			ServerName: bp.ServerName,
+			MinVersion: bp.MinVersion,
+		}
+
+		if config.MinVersion == 0 {
+			config.MinVersion = o.TLSMinVersion
+		}
+
+		if bp.CertPath != "" || bp.KeyPath != "" {
+			for _, p := range []string{bp.CertPath, bp.KeyPath} {
+				if err := sp.Add(p); err != nil && err != secrets.ErrAlreadyExists {
+					return nil, fmt.Errorf("failed to add backend TLS profile %s: %w", bp.Name, err)
+				}
+			}
+
+			kp, err := secrets.NewKeyPair(sp, bp.CertPath, bp.KeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load backend TLS profile %s: %w", bp.Name, err)
+			}
+
+			config.GetClientCertificate = kp.GetClientCertificate
+		}
+
+		if bp.CAPath != "" {
+			pool := x509.NewCertPool()
+			for _, p := range strings.Split(bp.CAPath, ",") {
+				pem, err := os.ReadFile(p)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read CA bundle %s of backend TLS profile %s: %w", p, bp.Name, err)
+				}
+
+				if !pool.AppendCertsFromPEM(pem) {
+					return nil, fmt.Errorf("no certificates found in CA bundle %s of backend TLS profile %s", p, bp.Name)
+				}
+			}
+
+			config.RootCAs = pool
+		}
+
+		profiles[bp.Name] = config
+	}
+
+	return profiles, nil
+}
+
+func (o *Options) setClientAuth(config *tls.Config) error {
+	if o.ClientAuthTLS != nil {
+		config.ClientAuth = *o.ClientAuthTLS
+	}
+
+	if o.ClientCAPathTLS == "" {
+		return nil
+	}
+
+	pool := x509.NewCertPool()
+	for _, p := range strings.Split(o.ClientCAPathTLS, ",") {
+		pem, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle %s: %w", p, err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA bundle %s", p)
+		}
+	}
+
+	config.ClientCAs = pool
+	if o.ClientAuthTLS == nil {
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return nil
+}
+
+func (o *Options) proxyProtocolListener(l net.Listener, enabled bool) (net.Listener, error) {
+	if !enabled {
+		return l, nil
+	}
+
+	if len(o.ProxyProtocolTrustedCIDRs) == 0 {
+		l.Close()
+		return nil, errors.New("PROXY protocol requires trusted CIDRs")
+	}
+
+	cidrs, err := skpnet.ParseCIDRs(o.ProxyProtocolTrustedCIDRs)
+	if err != nil {
+		l.Close()
+		return nil, fmt.Errorf("invalid PROXY protocol trusted CIDRs: %w", err)
+	}
+
+	return proxyprotocol.NewListener(l, proxyprotocol.Options{
+		TrustedCIDRs:  cidrs,
+		HeaderTimeout: o.ReadHeaderTimeoutServer,
+	}), nil
+}
+
 func listen(o *Options, mtr metrics.Metrics) (net.Listener, error) {
 	if o.Address == "" {
 		o.Address = ":http"
 	}
 
-	if !o.EnableTCPQueue {
-		return net.Listen("tcp", o.Address)
+	return listenWith(o, ListenerOptions{
+		Address:                   o.Address,
+		EnableTCPQueue:            o.EnableTCPQueue,
+		MaxTCPListenerConcurrency: o.MaxTCPListenerConcurrency,
+		MaxTCPListenerQueue:       o.MaxTCPListenerQueue,
+		EnableProxyProtocol:       o.EnableProxyProtocol,
+	}, mtr)
+}
+
+func listenWith(o *Options, lo ListenerOptions, mtr metrics.Metrics) (net.Listener, error) {
+	if !lo.EnableTCPQueue {
+		l, err := net.Listen("tcp", lo.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		return o.proxyProtocolListener(l, lo.EnableProxyProtocol)
 	}
 
 	var memoryLimit int
-	if o.MaxTCPListenerConcurrency <= 0 {
+	if lo.MaxTCPListenerConcurrency <= 0 {
 		// cgroup v1: https://www.kernel.org/doc/Documentation/cgroup-v1/memory.txt
 		// cgroup v2: TODO(sszuecs) has to wait for docker/k8s check path /sys/fs/cgroup/<name>/memory.max
 		// Note that in containers this will be the container limit.
@@ -1017,16 +1338,47 @@ func listen(o *Options, mtr metrics.Metrics) (net.Listener, error) {
 		qto = o.ReadTimeoutServer
 	}
 
-	return queuelistener.Listen(queuelistener.Options{
+	l, err := queuelistener.Listen(queuelistener.Options{
 		Network:          "tcp",
-		Address:          o.Address,
-		MaxConcurrency:   o.MaxTCPListenerConcurrency,
-		MaxQueueSize:     o.MaxTCPListenerQueue,
+		Address:          lo.Address,
+		MaxConcurrency:   lo.MaxTCPListenerConcurrency,
+		MaxQueueSize:     lo.MaxTCPListenerQueue,
 		MemoryLimitBytes: memoryLimit,
 		ConnectionBytes:  o.ExpectedBytesPerRequest,
 		QueueTimeout:     qto,
 		Metrics:          mtr,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return o.proxyProtocolListener(l, lo.EnableProxyProtocol)
+}
+
+// listenMain creates the listener of the main proxy server.
+func (o *Options) listenMain(srv *http.Server, mtr metrics.Metrics) (net.Listener, error) {
+	if srv.TLSConfig == nil {
+		log.Infof("TLS settings not found, defaulting to HTTP")
+		return listen(o, mtr)
+	}
+
+	address := o.Address
+	if address == "" {
+		address = ":https"
+	}
+
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.proxyProtocolListener(l, o.EnableProxyProtocol)
+}
+
+func closeServers(servers []*http.Server) {
+	for _, s := range servers {
+		s.Close()
+	}
 }
 
 func listenAndServeQuit(
@@ -1041,22 +1393,85 @@ func listenAndServeQuit(
 		return err
 	}
 
-	srv := &http.Server{
-		Addr:              o.Address,
-		TLSConfig:         tlsConfig,
-		Handler:           proxy,
-		ReadTimeout:       o.ReadTimeoutServer,
-		ReadHeaderTimeout: o.ReadHeaderTimeoutServer,
-		WriteTimeout:      o.WriteTimeoutServer,
-		IdleTimeout:       o.IdleTimeoutServer,
-		MaxHeaderBytes:    o.MaxHeaderBytes,
+	if o.CertDirTLS != "" {
+		// without a default certificate, the handshakes of the clients
+		// without SNI or with an unknown server name would fail
+		if tlsConfig == nil || len(tlsConfig.Certificates) == 0 {
+			return errors.New("the certificate directory requires a default certificate set by CertPathTLS and KeyPathTLS")
+		}
+
+		certs, err := certdir.New(certdir.Options{
+			Dir:             o.CertDirTLS,
+			RefreshInterval: o.CertDirRefreshIntervalTLS,
+			Metrics:         mtr,
+		})
+		if err != nil {
+			return err
+		}
+
+		defer certs.Close()
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.GetCertificate = certs.GetCertificate
 	}
 
-	if o.EnableConnMetricsServer {
-		m := metrics.Default
-		srv.ConnState = func(conn net.Conn, state http.ConnState) {
-			m.IncCounter(fmt.Sprintf("lb-conn-%s", state))
+	if o.drainer == nil {
+		o.drainer = drain.New(mtr)
+	}
+
+	proxy = o.drainer.Wrap(proxy)
+	handler := proxy
+	var h3 *http3.Server
+	if o.EnableHTTP3 {
+		if tlsConfig == nil {
+			return errors.New("HTTP/3 requires TLS")
+		}
+
+		h3 = o.newHTTP3Server(proxy, tlsConfig)
+		handler = altSvcHandler(h3, proxy)
+	}
+
+	srv := o.newServer(handler, listener.DefaultName, o.Address, tlsConfig, o.EnableProxyProtocol)
+
+	// the main listener is opened first, so that failing to listen on
+	// the main address doesn't leave any other listener running
+	log.Infof("proxy listener on %v", o.Address)
+	l, err := o.listenMain(srv, mtr)
+	if err != nil {
+		return err
+	}
+
+	// the additional listeners are shut down together with the main
+	// listener
+	servers := []*http.Server{srv}
+	for _, lo := range o.Listeners {
+		lsrv, err := o.serveListener(proxy, lo)
+		if err != nil {
+			l.Close()
+			closeServers(servers[1:])
+			return err
+		}
+
+		servers = append(servers, lsrv)
+	}
+
+	if h3 != nil {
+		ql, err := o.listenHTTP3(h3, mtr)
+		if err != nil {
+			l.Close()
+			closeServers(servers[1:])
+			return err
 		}
+
+		go func() {
+			if err := h3.ServeListener(ql); err != http.ErrServerClosed {
+				log.Errorf("HTTP/3 listener failed: %v", err)
+			}
+		}()
+
+		// the HTTP/3 connections are closed after the TCP listeners
+		// were shut down
+		defer ql.Close()
+		defer h3.Close()
 	}
 
 	// making idleConnsCH and sigs optional parameters is required to be able to tear down a server
@@ -1069,6 +1484,18 @@ func listenAndServeQuit(
 		sigs = make(chan os.Signal, 1)
 	}
 
+	go func() {
+		select {
+		case <-o.drainer.Done():
+			// closes the idle connections, and disables the keep-alive
+			// for the new ones
+			for _, s := range servers {
+				s.SetKeepAlivesEnabled(false)
+			}
+		case <-idleConnsCH:
+		}
+	}()
+
 	go func() {
 		signal.Notify(sigs, syscall.SIGTERM)
 
@@ -1078,29 +1505,31 @@ func listenAndServeQuit(
 		time.Sleep(o.WaitForHealthcheckInterval)
 
 		log.Info("Start shutdown")
-		if err := srv.Shutdown(context.Background()); err != nil {
-			log.Errorf("Failed to graceful shutdown: %v", err)
+		var wg sync.WaitGroup
+		for _, s := range servers {
+			wg.Add(1)
+			go func(s *http.Server) {
+				defer wg.Done()
+				if err := s.Shutdown(context.Background()); err != nil {
+					log.Errorf("Failed to graceful shutdown: %v", err)
+				}
+			}(s)
 		}
+
+		wg.Wait()
 		close(idleConnsCH)
 	}()
 
-	log.Infof("proxy listener on %v", o.Address)
-
 	if srv.TLSConfig != nil {
-		if err := srv.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
-			log.Errorf("ListenAndServeTLS failed: %v", err)
+		if err := srv.ServeTLS(l, "", ""); err != http.ErrServerClosed {
+			log.Errorf("ServeTLS failed: %v", err)
+			closeServers(servers[1:])
 			return err
 		}
 	} else {
-		log.Infof("TLS settings not found, defaulting to HTTP")
-
-		l, err := listen(o, mtr)
-		if err != nil {
-			return err
-		}
-
 		if err := srv.Serve(l); err != http.ErrServerClosed {
 			log.Errorf("Serve failed: %v", err)
+			closeServers(servers[1:])
 			return err
 		}
 	}
@@ -1110,6 +1539,76 @@ func listenAndServeQuit(
 	return nil
 }
 
+func (o *Options) newServer(handler http.Handler, name, address string, tlsConfig *tls.Config, proxyProtocol bool) *http.Server {
+	srv := &http.Server{
+		Addr:              address,
+		TLSConfig:         tlsConfig,
+		Handler:           handler,
+		ReadTimeout:       o.ReadTimeoutServer,
+		ReadHeaderTimeout: o.ReadHeaderTimeoutServer,
+		WriteTimeout:      o.WriteTimeoutServer,
+		IdleTimeout:       o.IdleTimeoutServer,
+		MaxHeaderBytes:    o.MaxHeaderBytes,
+		BaseContext: func(net.Listener) context.Context {
+			return listener.NewContext(context.Background(), name)
+		},
+	}
+
+	if proxyProtocol {
+		srv.ConnContext = proxyprotocol.ConnContext
+	}
+
+	if o.EnableConnMetricsServer {
+		m := metrics.Default
+		srv.ConnState = func(conn net.Conn, state http.ConnState) {
+			m.IncCounter(fmt.Sprintf("lb-conn-%s", state))
+		}
+	}
+
+	return srv
+}
+
+// serveListener starts serving an additional listener in the
+// background. It returns an error, when the listener cannot be created.
+func (o *Options) serveListener(handler http.Handler, lo ListenerOptions) (*http.Server, error) {
+	if lo.Name == "" || lo.Address == "" {
+		return nil, fmt.Errorf("listener name and address are required: %s/%s", lo.Name, lo.Address)
+	}
+
+	if lo.Name == listener.DefaultName {
+		return nil, fmt.Errorf("listener name %s is reserved for the main listener", lo.Name)
+	}
+
+	tlsConfig, err := o.loadTLSConfig(lo.CertPathTLS, lo.KeyPathTLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS config of listener %s: %w", lo.Name, err)
+	}
+
+	// the queue metrics are reported only for the main listener, to
+	// keep their keys unambiguous
+	l, err := listenWith(o, lo, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s for listener %s: %w", lo.Address, lo.Name, err)
+	}
+
+	srv := o.newServer(handler, lo.Name, lo.Address, tlsConfig, lo.EnableProxyProtocol)
+	log.Infof("proxy listener %s on %v", lo.Name, lo.Address)
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			err = srv.ServeTLS(l, "", "")
+		} else {
+			err = srv.Serve(l)
+		}
+
+		if err != http.ErrServerClosed {
+			log.Errorf("Serve failed on listener %s: %v", lo.Name, err)
+		}
+	}()
+
+	return srv, nil
+}
+
 func listenAndServe(proxy http.Handler, o *Options) error {
 	return listenAndServeQuit(proxy, o, nil, nil, nil)
 }
@@ -1172,6 +1671,8 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 	}
 	metrics.Default = mtr
 
+	o.drainer = drain.New(mtr)
+
 	// *DEPRECATED* client tracking parameter
 	if o.ApiUsageMonitoringDefaultClientTrackingPattern != "" {
 		log.Warn(`"ApiUsageMonitoringDefaultClientTrackingPattern" option is deprecated`)
@@ -1249,6 +1750,11 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		}
 	}
 
+	backendTLSProfiles, err := o.backendTLSProfiles(sp)
+	if err != nil {
+		return err
+	}
+
 	tio := auth.TokenintrospectionOptions{
 		Timeout:      o.OAuthTokenintrospectionTimeout,
 		MaxIdleConns: o.IdleConnectionsPerHost,
@@ -1456,9 +1962,12 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		cookie.New(),
 		query.New(),
 		traffic.New(),
+		listener.New(),
+		clientcert.NewSubject(),
+		clientcert.NewSAN(),
 		primitive.NewTrue(),
 		primitive.NewFalse(),
-		primitive.NewShutdown(),
+		primitive.NewShutdownDraining(o.draining()),
 		pauth.NewJWTPayloadAllKV(),
 		pauth.NewJWTPayloadAnyKV(),
 		pauth.NewJWTPayloadAllKVRegexp(),
@@ -1479,6 +1988,7 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 	schedulerRegistry := scheduler.RegistryWith(scheduler.Options{
 		Metrics:                mtr,
 		EnableRouteLIFOMetrics: o.EnableRouteLIFOMetrics,
+		EnableRouteFIFOMetrics: o.EnableRouteFIFOMetrics,
 	})
 	defer schedulerRegistry.Close()
 
@@ -1499,6 +2009,9 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 			fadein.NewPostProcessor(),
 		},
 		SignalFirstLoad: o.WaitFirstRouteLoad,
+		HistorySize:     o.RouteHistorySize,
+		LintRoutes:      o.LintRoutes,
+		Metrics:         mtr,
 	}
 
 	if o.DefaultFilters != nil {
@@ -1543,8 +2056,11 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		DisableHTTPKeepalives:      o.DisableHTTPKeepalives,
 		AccessLogDisabled:          o.AccessLogDisabled,
 		ClientTLS:                  o.ClientTLS,
+		BackendTLSProfiles:         backendTLSProfiles,
 		CustomHttpRoundTripperWrap: o.CustomHttpRoundTripperWrap,
 		RateLimiters:               ratelimitRegistry,
+		Draining:                   o.draining(),
+		UpgradeDrainTimeout:        o.UpgradeDrainTimeout,
 	}
 
 	if o.EnableBreakers || len(o.BreakerSettings) > 0 {
@@ -1571,6 +2087,9 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		mux := http.NewServeMux()
 		mux.Handle("/routes", routing)
 		mux.Handle("/routes/", routing)
+		mux.Handle("/routes/explain", routing.ExplainHandler())
+		mux.Handle("/routes/history", routing.HistoryHandler())
+		mux.Handle("/routes/invalid", routing.InvalidRoutesHandler())
 
 		metricsHandler := metrics.NewHandler(mtrOpts, mtr)
 		mux.Handle("/metrics", metricsHandler)
@@ -1578,6 +2097,14 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		mux.Handle("/debug/pprof", metricsHandler)
 		mux.Handle("/debug/pprof/", metricsHandler)
 
+		if o.DrainTokenFile != "" {
+			if err := sp.Add(o.DrainTokenFile); err != nil && err != secrets.ErrAlreadyExists {
+				return fmt.Errorf("failed to add drain token file: %w", err)
+			}
+
+			mux.Handle("/drain", o.drainer.NewHandler(sp, o.DrainTokenFile))
+		}
+
 		log.Infof("support listener on %s", supportListener)
 		go func() {
 			if err := http.ListenAndServe(supportListener, mux); err != nil {
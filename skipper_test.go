@@ -1,20 +1,33 @@
 package skipper
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/zalando/skipper/dataclients/routestring"
+	"github.com/zalando/skipper/drain"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/filters/builtin"
+	"github.com/zalando/skipper/metrics/metricstest"
+	"github.com/zalando/skipper/predicates/clientcert"
+	"github.com/zalando/skipper/predicates/listener"
 	"github.com/zalando/skipper/proxy"
 	"github.com/zalando/skipper/ratelimit"
 	"github.com/zalando/skipper/routing"
@@ -279,6 +292,361 @@ func testServerShutdown(t *testing.T, o *Options, scheme string) {
 	require.Error(t, err)
 }
 
+func TestMultipleListeners(t *testing.T) {
+	mainAddress, err := findAddress()
+	require.NoError(t, err)
+
+	internalAddress, err := findAddress()
+	require.NoError(t, err)
+
+	o := &Options{
+		Address: mainAddress,
+		Listeners: []ListenerOptions{{
+			Name:    "internal",
+			Address: internalAddress,
+		}},
+	}
+
+	dc, err := routestring.New(`
+		internal: Path("/internal") && Listener("internal") -> inlineContent("internal") -> <shunt>;
+		public: Path("/public") && Listener("default") -> inlineContent("public") -> <shunt>;
+		any: Path("/any") -> inlineContent("any") -> <shunt>;
+	`)
+	require.NoError(t, err)
+
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		Predicates:     []routing.PredicateSpec{listener.New()},
+		DataClients:    []routing.DataClient{dc},
+	})
+	defer rt.Close()
+
+	proxy := proxy.New(rt, proxy.OptionsNone)
+	defer proxy.Close()
+
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		err := listenAndServeQuit(proxy, o, sigs, nil, nil)
+		require.NoError(t, err)
+		close(done)
+	}()
+
+	<-rt.FirstLoad()
+	for _, test := range []struct {
+		address, path string
+		expectedCode  int
+	}{
+		{mainAddress, "/public", http.StatusOK},
+		{mainAddress, "/internal", http.StatusNotFound},
+		{mainAddress, "/any", http.StatusOK},
+		{internalAddress, "/public", http.StatusNotFound},
+		{internalAddress, "/internal", http.StatusOK},
+		{internalAddress, "/any", http.StatusOK},
+	} {
+		r, err := waitConnGet("http://" + test.address + test.path)
+		require.NoError(t, err)
+		r.Body.Close()
+		require.Equal(t, test.expectedCode, r.StatusCode, "%s%s", test.address, test.path)
+	}
+
+	sigs <- syscall.SIGTERM
+	<-done
+
+	_, err = http.Get("http://" + internalAddress + "/any")
+	require.Error(t, err, "the additional listener was not shut down")
+}
+
+func TestListenFailureClosesListeners(t *testing.T) {
+	busy, err := net.Listen("tcp6", "[::]:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	free, err := findAddress()
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		title            string
+		main, additional string
+	}{{
+		title:      "main listener fails",
+		main:       busy.Addr().String(),
+		additional: free,
+	}, {
+		title:      "additional listener fails",
+		main:       free,
+		additional: busy.Addr().String(),
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			o := &Options{
+				Address: test.main,
+				Listeners: []ListenerOptions{{
+					Name:    "internal",
+					Address: test.additional,
+				}},
+			}
+
+			err := listenAndServeQuit(http.NotFoundHandler(), o, nil, nil, nil)
+			require.Error(t, err)
+
+			l, err := net.Listen("tcp", free)
+			require.NoError(t, err, "the listener was not closed")
+			l.Close()
+		})
+	}
+}
+
+func TestListenerOptionsInvalid(t *testing.T) {
+	for _, lo := range []ListenerOptions{
+		{Address: ":0"},
+		{Name: "internal"},
+		{Name: "default", Address: ":0"},
+		{Name: "internal", Address: ":0", CertPathTLS: "fixtures/missing.crt", KeyPathTLS: "fixtures/missing.key"},
+	} {
+		o := &Options{}
+		_, err := o.serveListener(http.NotFoundHandler(), lo)
+		require.Error(t, err, "%+v", lo)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600))
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	clientDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "orders"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"orders.example.org"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caTemplate, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	address, err := findAddress()
+	require.NoError(t, err)
+
+	o := &Options{
+		Address:         address,
+		CertPathTLS:     "fixtures/test.crt",
+		KeyPathTLS:      "fixtures/test.key",
+		ClientCAPathTLS: caFile,
+	}
+
+	dc, err := routestring.New(`
+		subject: ClientCertSubject(/^CN=orders$/) && Path("/subject") -> inlineContent("subject") -> <shunt>;
+		san: ClientCertSAN("orders.example.org") && Path("/san") -> inlineContent("san") -> <shunt>;
+		fallback: * -> status(403) -> <shunt>;
+	`)
+	require.NoError(t, err)
+
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		Predicates:     []routing.PredicateSpec{clientcert.NewSubject(), clientcert.NewSAN()},
+		DataClients:    []routing.DataClient{dc},
+	})
+	defer rt.Close()
+
+	proxy := proxy.New(rt, proxy.OptionsNone)
+	defer proxy.Close()
+
+	sigs := make(chan os.Signal, 1)
+	go func() {
+		err := listenAndServeQuit(proxy, o, sigs, nil, nil)
+		require.NoError(t, err)
+	}()
+
+	defer func() { sigs <- syscall.SIGTERM }()
+	<-rt.FirstLoad()
+
+	get := func(path string, certs ...tls.Certificate) int {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			Certificates:       certs,
+		}}}
+
+		rsp, err := waitConn(func() (*http.Response, error) { return client.Get("https://" + address + path) })
+		require.NoError(t, err)
+		rsp.Body.Close()
+		return rsp.StatusCode
+	}
+
+	clientCert := tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+	require.Equal(t, http.StatusForbidden, get("/subject"))
+	require.Equal(t, http.StatusOK, get("/subject", clientCert))
+	require.Equal(t, http.StatusOK, get("/san", clientCert))
+	require.Equal(t, http.StatusForbidden, get("/other", clientCert))
+}
+
+func TestHTTP3(t *testing.T) {
+	address, err := findAddress()
+	require.NoError(t, err)
+
+	o := &Options{
+		Address:                 address,
+		CertPathTLS:             "fixtures/test.crt",
+		KeyPathTLS:              "fixtures/test.key",
+		EnableHTTP3:             true,
+		EnableConnMetricsServer: true,
+	}
+
+	dc, err := routestring.New(`* -> inlineContent("Hello") -> <shunt>`)
+	require.NoError(t, err)
+
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		DataClients:    []routing.DataClient{dc},
+	})
+	defer rt.Close()
+
+	proxy := proxy.New(rt, proxy.OptionsNone)
+	defer proxy.Close()
+
+	m := &metricstest.MockMetrics{}
+	sigs := make(chan os.Signal, 1)
+	go func() {
+		err := listenAndServeQuit(proxy, o, sigs, nil, m)
+		require.NoError(t, err)
+	}()
+
+	defer func() { sigs <- syscall.SIGTERM }()
+	<-rt.FirstLoad()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	rsp, err := waitConn(func() (*http.Response, error) { return client.Get("https://" + address) })
+	require.NoError(t, err)
+	rsp.Body.Close()
+
+	_, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	require.Contains(t, rsp.Header.Get("Alt-Svc"), `h3=":`+port+`"`)
+
+	h3 := &http3.RoundTripper{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer h3.Close()
+
+	rsp, err = (&http.Client{Transport: h3}).Get("https://" + address)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+
+	b, err := io.ReadAll(rsp.Body)
+	require.NoError(t, err)
+	require.Equal(t, 3, rsp.ProtoMajor)
+	require.Equal(t, "Hello", string(b))
+
+	m.WithCounters(func(counters map[string]int64) {
+		require.Equal(t, int64(1), counters["lb-quic-conn-new"])
+	})
+}
+
+func TestHTTP3WithoutTLS(t *testing.T) {
+	err := listenAndServeQuit(nil, &Options{Address: ":0", EnableHTTP3: true}, nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestCertDirWithoutDefaultCertificate(t *testing.T) {
+	err := listenAndServeQuit(nil, &Options{Address: ":0", CertDirTLS: t.TempDir()}, nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestProxyProtocolWithoutTrustedCIDRs(t *testing.T) {
+	err := listenAndServeQuit(nil, &Options{Address: "127.0.0.1:0", EnableProxyProtocol: true}, nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestDraining(t *testing.T) {
+	address, err := findAddress()
+	require.NoError(t, err)
+
+	m := &metricstest.MockMetrics{}
+	o := &Options{Address: address, drainer: drain.New(m)}
+
+	dc, err := routestring.New(`* -> inlineContent("Hello") -> <shunt>`)
+	require.NoError(t, err)
+
+	rt := routing.New(routing.Options{
+		FilterRegistry: builtin.MakeRegistry(),
+		DataClients:    []routing.DataClient{dc},
+	})
+	defer rt.Close()
+
+	proxy := proxy.New(rt, proxy.OptionsNone)
+	defer proxy.Close()
+
+	sigs := make(chan os.Signal, 1)
+	go func() {
+		err := listenAndServeQuit(proxy, o, sigs, nil, m)
+		require.NoError(t, err)
+	}()
+
+	defer func() { sigs <- syscall.SIGTERM }()
+	<-rt.FirstLoad()
+
+	rsp, err := waitConnGet("http://" + address)
+	require.NoError(t, err)
+	rsp.Body.Close()
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	require.False(t, rsp.Close)
+
+	o.drainer.Drain()
+
+	rsp, err = http.Get("http://" + address)
+	require.NoError(t, err)
+	rsp.Body.Close()
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	require.True(t, rsp.Close)
+
+	v, ok := m.Gauge(drain.MetricsKey)
+	require.True(t, ok)
+	require.Equal(t, float64(1), v)
+}
+
+func TestClientCAInvalid(t *testing.T) {
+	o := &Options{
+		CertPathTLS:     "fixtures/test.crt",
+		KeyPathTLS:      "fixtures/test.key",
+		ClientCAPathTLS: "fixtures/missing.pem",
+	}
+
+	_, err := o.tlsConfig()
+	require.Error(t, err)
+
+	// not a PEM bundle
+	o.ClientCAPathTLS = "skipper.go"
+	_, err = o.tlsConfig()
+	require.Error(t, err)
+
+	o.ClientCAPathTLS = "fixtures/test.crt"
+	c, err := o.tlsConfig()
+	require.NoError(t, err)
+	require.Equal(t, tls.VerifyClientCertIfGiven, c.ClientAuth)
+
+	for _, auth := range []tls.ClientAuthType{tls.NoClientCert, tls.RequireAndVerifyClientCert} {
+		o.ClientAuthTLS = &auth
+		c, err = o.tlsConfig()
+		require.NoError(t, err)
+		require.Equal(t, auth, c.ClientAuth)
+	}
+}
+
 type (
 	customRatelimitSpec   struct{ registry *ratelimit.Registry }
 	customRatelimitFilter struct{}